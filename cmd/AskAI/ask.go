@@ -0,0 +1,321 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
+)
+
+func init() {
+	register("ask", "Ask a single question and print the answer", runAsk)
+}
+
+// runAsk answers one question without the TUI. The question comes from the
+// arguments, or from stdin if there are none or the only one is "-"; with
+// -template it is written with a prompt template. With -schema the answer is
+// JSON validated against the schema.
+func runAsk(args []string) error {
+	fset := newFlagSet("ask")
+	model := fset.String("model", "", "model to ask (default: the persona's, profile's or configured one)")
+	schemaPath := fset.String("schema", "", "JSON Schema file the answer must match; prints the answer as JSON")
+	repairs := fset.Int("repairs", llm.DefaultRepairs, "how often to ask the model to fix an answer that doesn't match the schema")
+	templateName := fset.String("template", "", "prompt template to write the question with (see askai templates)")
+	vars := templateVars{}
+	fset.Var(vars, "var", "value of a template placeholder as name=value (repeatable)")
+	save := fset.Bool("save", false, "store the question and answer in the vault")
+	thinking := fset.Bool("thinking", false, "print the model's reasoning to stderr")
+	var images imageFiles
+	fset.Var(&images, "image", "image file to attach, for vision models (repeatable)")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	var question string
+	var err error
+	if *templateName != "" {
+		question, err = renderTemplate(*templateName, vars, fset.Args())
+	} else {
+		question, err = askInput(fset.Args())
+	}
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(question) == "" {
+		return errors.New("no question given")
+	}
+
+	var schema *llm.Schema
+	if *schemaPath != "" {
+		data, err := os.ReadFile(*schemaPath)
+		if err != nil {
+			return fmt.Errorf("failed to read schema: %w", err)
+		}
+		if schema, err = llm.ParseSchema(data); err != nil {
+			return err
+		}
+	}
+
+	route, question := routeQuestion(question)
+	if (*model != "" || *personaFlag != "") && route.Reason != llm.RouteOverride {
+		// Explicit flags win over automatic routes, but not over an @override
+		route = llm.RouteDecision{}
+	} else if label := route.Label(); label != "" {
+		fmt.Fprintf(os.Stderr, "Route: %s\n", label)
+	}
+
+	provider, req, err := askRequest(question, route)
+	if err != nil {
+		return err
+	}
+	if *model != "" && route.Reason != llm.RouteOverride {
+		req.Model = *model
+	}
+	if err := attachImages(&req, images); err != nil {
+		return err
+	}
+	recallMemories(&req, question)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var answer string
+	var resp *llm.ChatResponse
+	if schema != nil {
+		var out []byte
+		out, resp, err = llm.AskStructured(ctx, provider, req, schema, *repairs)
+		var serr *llm.SchemaError
+		if errors.As(err, &serr) {
+			fmt.Fprintf(os.Stderr, "last answer:\n%s\n", serr.Output)
+		}
+		if err != nil {
+			return err
+		}
+		answer = string(out)
+		fmt.Println(answer)
+	} else {
+		var onThinking func(string) error
+		if *thinking {
+			onThinking = func(s string) error {
+				_, err := fmt.Fprint(os.Stderr, s)
+				return err
+			}
+		}
+		resp, err = llm.StreamThinking(ctx, provider, req, func(s string) error {
+			_, err := fmt.Print(s)
+			return err
+		}, onThinking)
+		fmt.Println()
+		if err != nil {
+			return err
+		}
+		answer = resp.Content
+	}
+
+	if !*save {
+		return nil
+	}
+	return saveAnswer(question, answer, *templateName, route, req, resp)
+}
+
+// askInput returns the question given as arguments, or read from stdin if
+// there are none or the only one is "-"
+func askInput(args []string) (string, error) {
+	input := strings.Join(args, " ")
+	if input != "" && input != "-" {
+		return input, nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read question: %w", err)
+	}
+	return string(data), nil
+}
+
+// templateVars collects repeated -var name=value flags
+type templateVars map[string]string
+
+func (v templateVars) String() string {
+	return ""
+}
+
+func (v templateVars) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("expected name=value, got %q", s)
+	}
+	v[name] = value
+	return nil
+}
+
+// imageFiles collects repeated -image flags
+type imageFiles []string
+
+func (f *imageFiles) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *imageFiles) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+// attachImages copies images into the active knowledge base's vault, where
+// the Q&A is stored, and attaches them to the question, the last message of req
+func attachImages(req *llm.ChatRequest, files []string) error {
+	req.VaultPath = config.KnowledgeBasePath(activeKnowledgeBase())
+	question := &req.Messages[len(req.Messages)-1]
+	for _, file := range files {
+		ref, err := fs.SaveAttachment(req.VaultPath, file)
+		if err != nil {
+			return err
+		}
+		question.Images = append(question.Images, ref)
+	}
+	return nil
+}
+
+// renderTemplate writes a question with a prompt template. Placeholders are
+// filled from vars; the text given as arguments or on stdin fills the one
+// placeholder left over, if any.
+func renderTemplate(name string, vars templateVars, args []string) (string, error) {
+	templates, err := config.LoadTemplates()
+	if err != nil {
+		return "", err
+	}
+	t, ok := config.FindTemplate(templates, name)
+	if !ok {
+		return "", fmt.Errorf("unknown template %q", name)
+	}
+
+	var missing []string
+	for _, v := range t.Variables() {
+		if _, ok := vars[v]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	switch {
+	case len(missing) == 1:
+		input, err := askInput(args)
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(input) == "" {
+			return "", fmt.Errorf("template %q needs a value for %s", t.Name, missing[0])
+		}
+		vars[missing[0]] = strings.TrimSpace(input)
+	case len(missing) > 1:
+		return "", fmt.Errorf("template %q needs values for %s (use -var name=value)", t.Name, strings.Join(missing, ", "))
+	}
+	return t.Render(vars)
+}
+
+// saveAnswer stores a question and its answer in the vault and Qdrant
+func saveAnswer(question, answer, template string, route llm.RouteDecision, req llm.ChatRequest, resp *llm.ChatResponse) error {
+	svc, err := openServices()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	entry := fs.QA{Question: question, Answer: answer, Model: req.Model, Template: template}
+	entry.Images = req.Messages[len(req.Messages)-1].Images
+	keepThinking(&entry, resp)
+	if persona, err := askPersona(route); err == nil && persona != nil {
+		entry.Persona = persona.Name
+	}
+	if resp != nil {
+		metrics := resp.Metrics
+		entry.Metrics = &metrics
+		entry.Endpoint = resp.Endpoint
+		if resp.Model != "" {
+			entry.Model = resp.Model
+		}
+	}
+	if data, err := json.Marshal(req.Options); err == nil {
+		entry.Options = data
+	}
+	qa, err := svc.app().StoreQA(entry)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Saved as %s\n", fs.ShortID(qa.Key()))
+	return nil
+}
+
+// keepThinking adds the model's reasoning to entry if the config asks for it
+func keepThinking(entry *fs.QA, resp *llm.ChatResponse) {
+	if resp == nil || resp.Thinking == "" {
+		return
+	}
+	if cfg, err := config.Load(); err == nil && cfg.KeepThinking {
+		entry.Thinking = resp.Thinking
+	}
+}
+
+// askRequest builds the request for a question from the config, the active
+// profile and the persona, and points it at the route's model if it has one
+func askRequest(question string, route llm.RouteDecision) (llm.ChatProvider, llm.ChatRequest, error) {
+	provider, req, err := backendRequest()
+	if err != nil {
+		return nil, llm.ChatRequest{}, err
+	}
+	persona, err := askPersona(route)
+	if err != nil {
+		return nil, llm.ChatRequest{}, err
+	}
+
+	if persona != nil {
+		if persona.Model != "" {
+			req.Model = persona.Model
+		}
+		if persona.Temperature != nil {
+			req.Options.Temperature = *persona.Temperature
+		}
+		if persona.Options != nil {
+			req.Options.GenerationOptions = req.Options.GenerationOptions.Merge(*persona.Options)
+		}
+		if persona.SystemPrompt != "" {
+			req.Messages = append(req.Messages, fs.Message{Role: fs.RoleSystem, Content: persona.SystemPrompt})
+		}
+	}
+	if route.Model != "" {
+		req.Model = route.Model
+	}
+	req.Messages = append(req.Messages, fs.Message{Role: fs.RoleUser, Content: question})
+	return provider, req, nil
+}
+
+// backendRequest returns the backend of the active profile and an empty
+// request with the configured model and generation options
+func backendRequest() (llm.ChatProvider, llm.ChatRequest, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, llm.ChatRequest{}, err
+	}
+	name, profile, err := config.ActiveProfile(*profileFlag)
+	if err != nil {
+		return nil, llm.ChatRequest{}, err
+	}
+	provider, err := llm.NewProfileProvider(name, profile)
+	if err != nil {
+		return nil, llm.ChatRequest{}, err
+	}
+
+	req := llm.ChatRequest{
+		Model:   cfg.ModelName,
+		Options: llm.Options{Temperature: cfg.Temperature, GenerationOptions: cfg.Options},
+	}
+	if profile.Model != "" {
+		req.Model = profile.Model
+	}
+	return provider, req, nil
+}
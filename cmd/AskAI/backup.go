@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+func init() {
+	register("backup", "Write a snapshot of the knowledge base to a tar.gz archive", runBackup)
+	register("restore", "Restore a knowledge base from a backup archive", runRestore)
+}
+
+// runBackup writes the active knowledge base's vault files and points to an archive
+func runBackup(args []string) error {
+	fset := newFlagSet("backup")
+	out := fset.String("o", "", "archive to write (default: askai-<kb>-<timestamp>.tar.gz)")
+	withConfig := fset.Bool("config", false, "also archive config.json")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	svc, err := openServices()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	path := *out
+	if path == "" {
+		path = fmt.Sprintf("askai-%s-%s.tar.gz", svc.kb, time.Now().Format("20060102-150405"))
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+
+	manifest, err := svc.app().Backup(f, svc.kb, *withConfig)
+	if cerr := f.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("failed to close %s: %w", path, cerr)
+	}
+	if err != nil {
+		os.Remove(path)
+		return err
+	}
+
+	fmt.Printf("Backed up %d points and %d files from %s to %s\n",
+		manifest.PointCount, len(manifest.Files), manifest.KnowledgeBase, path)
+	return nil
+}
+
+// runRestore verifies an archive and restores it into the active knowledge base
+func runRestore(args []string) error {
+	fset := newFlagSet("restore")
+	force := fset.Bool("force", false, "overwrite existing vault files (config.json is never overwritten)")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() != 1 {
+		return fmt.Errorf("usage: askai restore [-force] <archive>")
+	}
+
+	f, err := os.Open(fset.Arg(0))
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", fset.Arg(0), err)
+	}
+	defer f.Close()
+
+	svc, err := openServices()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	manifest, skipped, err := svc.app().Restore(f, *force)
+	if err != nil {
+		return err
+	}
+	for _, path := range skipped {
+		fmt.Printf("Kept the existing %s\n", path)
+	}
+
+	fmt.Printf("Restored %d points and %d files into %s (backup of %s taken %s)\n",
+		manifest.PointCount, len(manifest.Files), svc.kb,
+		manifest.KnowledgeBase, manifest.CreatedAt.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command is a CLI subcommand that runs instead of the TUI
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+// commands maps subcommand names to their implementation
+var commands = map[string]command{}
+
+// register adds a subcommand to the command table
+func register(name, summary string, run func(args []string) error) {
+	commands[name] = command{summary: summary, run: run}
+}
+
+func init() {
+	register("help", "List available commands", runHelp)
+}
+
+// runHelp prints the list of subcommands
+func runHelp(args []string) error {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("Usage: askai [-kb name] [command] [flags]")
+	fmt.Println()
+	fmt.Println("Run without a command to start the TUI.")
+	fmt.Println()
+	fmt.Println("Global flags:")
+	flag.CommandLine.SetOutput(os.Stdout)
+	flag.PrintDefaults()
+	fmt.Println()
+	fmt.Println("Commands:")
+	for _, name := range names {
+		fmt.Printf("  %-12s %s\n", name, commands[name].summary)
+	}
+	return nil
+}
+
+// newFlagSet creates a flag set for a subcommand that reports errors instead of exiting
+func newFlagSet(name string) *flag.FlagSet {
+	fset := flag.NewFlagSet("askai "+name, flag.ContinueOnError)
+	fset.SetOutput(os.Stderr)
+	return fset
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
+)
+
+func init() {
+	register("compare", "Ask several models the same question at once", runCompare)
+}
+
+// compareResult is one model's answer
+type compareResult struct {
+	model string
+	resp  *llm.ChatResponse
+	err   error
+}
+
+// runCompare sends a question to several models concurrently and prints
+// their answers one after another, each with its stats
+func runCompare(args []string) error {
+	fset := newFlagSet("compare")
+	models := fset.String("models", "", "comma-separated models to compare (default: the compare models in the config)")
+	save := fset.String("save", "", `store answers in the vault: a model name, or "all"`)
+	var images imageFiles
+	fset.Var(&images, "image", "image file to attach, for vision models (repeatable)")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	question, err := askInput(fset.Args())
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(question) == "" {
+		return errors.New("no question given")
+	}
+
+	names := config.ParseModelList(*models)
+	if len(names) == 0 {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		names = cfg.CompareModels
+	}
+	if len(names) < 2 {
+		return errors.New("at least two models are needed: use -models a,b or set Compare models in the TUI options")
+	}
+
+	provider, req, err := askRequest(question, llm.RouteDecision{})
+	if err != nil {
+		return err
+	}
+	if err := attachImages(&req, images); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	results := make([]compareResult, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			r := req
+			r.Model = name
+			resp, err := llm.Stream(ctx, provider, r, func(string) error { return nil })
+			results[i] = compareResult{model: name, resp: resp, err: err}
+		}(i, name)
+	}
+	wg.Wait()
+
+	for _, r := range results {
+		fmt.Printf("=== %s\n", r.model)
+		if r.err != nil {
+			fmt.Printf("Error: %v\n\n", r.err)
+			continue
+		}
+		fmt.Printf("%s\n\n[%s]\n\n", strings.TrimSpace(r.resp.Content), llm.FormatMetrics(r.resp.Metrics))
+	}
+
+	if *save == "" {
+		return nil
+	}
+	return saveCompared(question, req, results, *save)
+}
+
+// saveCompared stores the answers of the chosen model, or of all models,
+// each labelled with the model that wrote it
+func saveCompared(question string, req llm.ChatRequest, results []compareResult, which string) error {
+	var entries []fs.QA
+	for _, r := range results {
+		if r.err != nil || (which != "all" && r.model != which) {
+			continue
+		}
+		metrics := r.resp.Metrics
+		entry := fs.QA{Question: question, Answer: r.resp.Content, Model: r.model, Endpoint: r.resp.Endpoint, Metrics: &metrics}
+		entry.Images = req.Messages[len(req.Messages)-1].Images
+		keepThinking(&entry, r.resp)
+		if persona, err := config.ActivePersona(*personaFlag); err == nil && persona != nil {
+			entry.Persona = persona.Name
+		}
+		if data, err := json.Marshal(req.Options); err == nil {
+			entry.Options = data
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no answer from %q to store", which)
+	}
+
+	svc, err := openServices()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	for _, entry := range entries {
+		qa, err := svc.app().StoreQA(entry)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Saved %s's answer as %s\n", entry.Model, fs.ShortID(qa.Key()))
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+func init() {
+	register("conversations", "List saved conversations, print or export one, or title them", runConversations)
+}
+
+// runConversations lists saved conversations, prints the turns of one,
+// exports one as Markdown or titles the ones that need it
+func runConversations(args []string) error {
+	fset := newFlagSet("conversations")
+	show := fset.String("show", "", "print the conversation with this ID (or unique prefix)")
+	export := fset.String("export", "", "write the conversation with this ID (or unique prefix) as Markdown")
+	out := fset.String("o", "", "with -export, the file to write (default: stdout)")
+	title := fset.Bool("title", false, "generate titles and summaries for conversations that need them")
+	model := fset.String("model", "", "with -title, the model to use (default: title_model from the config, or the chat model)")
+	limit := fset.Int("n", 20, "number of conversations to list (0 for all)")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	vaultPath := config.KnowledgeBasePath(activeKnowledgeBase())
+
+	switch {
+	case *title:
+		return titleConversations(*model)
+
+	case *export != "":
+		c, err := fs.FindConversation(vaultPath, *export)
+		if err != nil {
+			return err
+		}
+		if *out == "" {
+			return exportConversation(os.Stdout, c)
+		}
+		f, err := os.Create(*out)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", *out, err)
+		}
+		err = exportConversation(f, c)
+		if cerr := f.Close(); err == nil && cerr != nil {
+			err = fmt.Errorf("failed to close %s: %w", *out, cerr)
+		}
+		return err
+
+	case *show != "":
+		c, err := fs.FindConversation(vaultPath, *show)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n%s\n", c.Title, c.Created.Format("2006-01-02 15:04"))
+		if c.Persona != "" {
+			fmt.Printf("Persona: %s\n", c.Persona)
+		}
+		if c.Synopsis != "" {
+			fmt.Printf("\n%s\n", c.Synopsis)
+		}
+		if c.Summary != "" {
+			fmt.Printf("\n[summary of the first %d messages]\n%s\n", c.Summarized, c.Summary)
+		}
+		for _, msg := range c.Messages {
+			fmt.Printf("\n[%s]\n%s\n", msg.Role, msg.Content)
+			for _, image := range msg.Images {
+				fmt.Printf("(image: %s)\n", filepath.Join(vaultPath, image))
+			}
+		}
+		return nil
+	}
+
+	convs, err := fs.ListConversations(vaultPath)
+	if err != nil {
+		return err
+	}
+	if *limit > 0 && len(convs) > *limit {
+		convs = convs[:*limit]
+	}
+	for _, c := range convs {
+		fmt.Printf("%s  %s  %3d turns  %s\n", fs.ShortID(c.ID), c.Updated.Format("2006-01-02 15:04"), c.Turns(), truncate(c.Title, 60))
+		if c.Synopsis != "" {
+			fmt.Printf("          %s\n", truncate(c.Synopsis, 100))
+		}
+	}
+	return nil
+}
+
+// titleConversations titles every conversation whose turns its title doesn't
+// cover yet, however old, and prints the new titles
+func titleConversations(model string) error {
+	provider, req, err := backendRequest()
+	if err != nil {
+		return err
+	}
+	if cfg, err := config.Load(); err == nil && cfg.TitleModel != "" {
+		req.Model = cfg.TitleModel
+	}
+	if model != "" {
+		req.Model = model
+	}
+
+	svc, err := openServices()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	titled, err := svc.app().TitleConversations(ctx, provider, req, time.Time{}, "")
+	for _, c := range titled {
+		fmt.Printf("%s  %s\n", fs.ShortID(c.ID), c.Title)
+	}
+	fmt.Printf("Titled %d conversations\n", len(titled))
+	return err
+}
+
+// exportConversation writes a conversation as Markdown: its title as the
+// heading and its synopsis, then the questions and answers. Tool exchanges
+// and system prompts are left out.
+func exportConversation(w io.Writer, c *fs.Conversation) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", c.Title)
+	fmt.Fprintf(&sb, "*%s", c.Created.Format("2006-01-02 15:04"))
+	if c.Persona != "" {
+		fmt.Fprintf(&sb, " · persona %s", c.Persona)
+	}
+	fmt.Fprintf(&sb, " · %d turns*\n\n", c.Turns())
+	if c.Synopsis != "" {
+		fmt.Fprintf(&sb, "> %s\n\n", strings.ReplaceAll(c.Synopsis, "\n", "\n> "))
+	}
+
+	for _, msg := range c.Messages {
+		switch {
+		case msg.Role == fs.RoleUser:
+			fmt.Fprintf(&sb, "**You:** %s\n\n", strings.TrimSpace(msg.Content))
+			for _, image := range msg.Images {
+				fmt.Fprintf(&sb, "![%s](%s)\n\n", filepath.Base(image), image)
+			}
+		case msg.Role == fs.RoleAssistant && len(msg.ToolCalls) == 0:
+			fmt.Fprintf(&sb, "**Assistant:** %s\n\n", strings.TrimSpace(msg.Content))
+		}
+	}
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	return nil
+}
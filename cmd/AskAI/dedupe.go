@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/VarunSharma3520/AskAI/internal/app"
+)
+
+func init() {
+	register("dedupe", "Find near-duplicate Q&As and optionally keep or merge them", runDedupe)
+}
+
+// runDedupe lists clusters of near-duplicate Q&As. With -keep or -merge it
+// resolves every cluster in favour of its newest entry.
+func runDedupe(args []string) error {
+	fset := newFlagSet("dedupe")
+	threshold := fset.Float64("threshold", app.DefaultDuplicateThreshold, "minimum question similarity (0-1)")
+	keep := fset.Bool("keep", false, "keep the newest entry of each cluster and delete the rest")
+	merge := fset.Bool("merge", false, "merge answers into the newest entry of each cluster")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if *keep && *merge {
+		return fmt.Errorf("-keep and -merge are mutually exclusive")
+	}
+
+	svc, err := openServices()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	clusters, err := svc.app().FindDuplicates(*threshold)
+	if err != nil {
+		return err
+	}
+	if len(clusters) == 0 {
+		fmt.Println("No near-duplicates found.")
+		return nil
+	}
+
+	for i, c := range clusters {
+		newest := c.Newest()
+		fmt.Printf("Cluster %d (%d entries)\n", i+1, len(c.Entries))
+		for j, e := range c.Entries {
+			marker := " "
+			if j == newest {
+				marker = "*"
+			}
+			fmt.Printf("  %s %s  %s\n", marker, e.ID, truncate(e.Question, 70))
+		}
+
+		switch {
+		case *keep:
+			if err := svc.app().KeepOne(c, newest); err != nil {
+				return err
+			}
+			fmt.Println("  -> kept newest entry")
+		case *merge:
+			if err := svc.app().MergeCluster(c, newest); err != nil {
+				return err
+			}
+			fmt.Println("  -> merged answers into newest entry")
+		}
+	}
+	return nil
+}
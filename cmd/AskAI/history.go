@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/VarunSharma3520/AskAI/internal/app"
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+func init() {
+	register("history", "List stored Q&As with their IDs", runHistory)
+	register("delete", "Delete stored Q&As by ID from the vault and Qdrant", runDelete)
+	register("edit", "Edit the question or answer of a stored Q&A", runEdit)
+}
+
+// runHistory prints the most recent Q&As with their IDs
+func runHistory(args []string) error {
+	fset := newFlagSet("history")
+	limit := fset.Int("n", 20, "number of entries to show (0 for all)")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	qas, err := app.New(nil, config.KnowledgeBasePath(activeKnowledgeBase())).ListQAs()
+	if err != nil {
+		return err
+	}
+	if *limit > 0 && len(qas) > *limit {
+		qas = qas[:*limit]
+	}
+
+	for _, qa := range qas {
+		fmt.Printf("%s  %s  %s\n", fs.ShortID(qa.Key()), qa.Time.Format("2006-01-02 15:04"), truncate(qa.Question, 70))
+	}
+	return nil
+}
+
+// runDelete removes each given Q&A from both stores
+func runDelete(args []string) error {
+	fset := newFlagSet("delete")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() == 0 {
+		return fmt.Errorf("usage: askai delete <id>...")
+	}
+
+	svc, err := openServices()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	for _, id := range fset.Args() {
+		if err := svc.app().DeleteQA(id); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted %s\n", id)
+	}
+	return nil
+}
+
+// runEdit replaces the question and/or answer of a Q&A in both stores
+func runEdit(args []string) error {
+	fset := newFlagSet("edit")
+	question := fset.String("q", "", "new question (re-embedded)")
+	answer := fset.String("a", "", "new answer")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() != 1 || (*question == "" && *answer == "") {
+		return fmt.Errorf("usage: askai edit [-q question] [-a answer] <id>")
+	}
+
+	svc, err := openServices()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	qa, err := svc.app().EditQA(fset.Arg(0), *question, *answer)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s\n", qa.Key())
+	return nil
+}
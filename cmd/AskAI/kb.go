@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+)
+
+func init() {
+	register("kb", "List knowledge bases or set the default one", runKB)
+	register("search", "Search stored Q&As across one or more knowledge bases", runSearch)
+}
+
+// runKB lists the known knowledge bases, or saves the default with -use
+func runKB(args []string) error {
+	fset := newFlagSet("kb")
+	use := fset.String("use", "", `make this knowledge base the default ("auto" follows the git repository)`)
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	if *use != "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		cfg.KnowledgeBase = *use
+		if *use != config.AutoKnowledgeBase {
+			cfg.KnowledgeBase = config.SanitizeKnowledgeBase(*use)
+		}
+		if err := config.Save(cfg); err != nil {
+			return err
+		}
+		fmt.Printf("Default knowledge base set to %s\n", cfg.KnowledgeBase)
+		return nil
+	}
+
+	svc, err := openServices()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	kbs, err := svc.app().KnowledgeBases()
+	if err != nil {
+		return err
+	}
+	for _, kb := range kbs {
+		marker := " "
+		if kb == svc.kb {
+			marker = "*"
+		}
+		fmt.Printf("%s %-20s %s\n", marker, kb, config.CollectionName(kb))
+	}
+	return nil
+}
+
+// runSearch prints the stored Q&As most similar to a question
+func runSearch(args []string) error {
+	fset := newFlagSet("search")
+	kbs := fset.String("kbs", "", `comma-separated knowledge bases to search, or "all" (default: the active one)`)
+	limit := fset.Int("n", 5, "number of results")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	question := strings.Join(fset.Args(), " ")
+	if question == "" {
+		return fmt.Errorf("usage: askai search [-kbs a,b|all] [-n 5] <question>")
+	}
+
+	svc, err := openServices()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+	a := svc.app()
+
+	targets := []string{svc.kb}
+	switch *kbs {
+	case "":
+	case "all":
+		if targets, err = a.KnowledgeBases(); err != nil {
+			return err
+		}
+	default:
+		targets = nil
+		for _, kb := range strings.Split(*kbs, ",") {
+			targets = append(targets, config.SanitizeKnowledgeBase(kb))
+		}
+	}
+
+	hits, err := a.SearchKnowledgeBases(targets, question, *limit)
+	if err != nil {
+		return err
+	}
+	for _, h := range hits {
+		fmt.Printf("%.3f  [%s]  %s\n", h.Score, config.KnowledgeBaseFromCollection(h.Collection), truncate(h.Question, 70))
+		fmt.Printf("       %s\n", truncate(strings.ReplaceAll(h.Answer, "\n", " "), 100))
+	}
+	return nil
+}
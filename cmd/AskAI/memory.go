@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/app"
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
+)
+
+func init() {
+	register("memory", "Review, distill or delete long-term memories", runMemory)
+}
+
+// runMemory lists the long-term memories, shows or deletes one, distills
+// new ones from recent conversations, or turns automatic memory on or off
+func runMemory(args []string) error {
+	fset := newFlagSet("memory")
+	show := fset.String("show", "", "print the memory with this ID (or unique prefix) and the Q&As it came from")
+	del := fset.String("delete", "", "delete the memory with this ID (or unique prefix)")
+	distill := fset.Bool("distill", false, "distill memories from conversations updated in the last week")
+	all := fset.Bool("all", false, "with -distill, read every conversation, however old")
+	auto := fset.String("auto", "", `"on" to distill in the background and recall memories into prompts, "off" to stop`)
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	switch *auto {
+	case "":
+	case "on", "off":
+		if err := config.SaveMemory(*auto == "on"); err != nil {
+			return err
+		}
+		fmt.Printf("Long-term memory turned %s\n", *auto)
+		return nil
+	default:
+		return fmt.Errorf(`-auto takes "on" or "off", not %q`, *auto)
+	}
+
+	svc, err := openServices()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+	a := svc.app()
+
+	switch {
+	case *distill:
+		return distillMemories(a, *all)
+
+	case *del != "":
+		mem, err := a.DeleteMemory(*del)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Deleted %s: %s\n", fs.ShortID(mem.ID), mem.Text)
+		return nil
+
+	case *show != "":
+		mem, err := a.FindMemory(*show)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s\n%s · %s\n", mem.Text, mem.Kind, mem.Created.Format("2006-01-02 15:04"))
+		if mem.Conversation != "" {
+			fmt.Printf("Conversation: %s\n", fs.ShortID(mem.Conversation))
+		}
+		for _, id := range mem.Sources {
+			if qa, err := a.FindQA(id); err == nil {
+				fmt.Printf("  from %s  %s\n", fs.ShortID(id), truncate(qa.Question, 70))
+			} else {
+				fmt.Printf("  from %s  (no longer in the vault)\n", fs.ShortID(id))
+			}
+		}
+		return nil
+	}
+
+	memories, err := a.ListMemories()
+	if err != nil {
+		return err
+	}
+	if len(memories) == 0 {
+		fmt.Println("No memories yet; run askai memory -distill or turn on -auto")
+		return nil
+	}
+	for _, mem := range memories {
+		fmt.Printf("%s  %-10s  %s  %s\n", fs.ShortID(mem.ID), mem.Kind, mem.Created.Format("2006-01-02"), truncate(mem.Text, 70))
+	}
+	return nil
+}
+
+// distillMemories distills memories from recent conversations, or from all
+// of them, and prints the ones stored
+func distillMemories(a *app.App, all bool) error {
+	provider, req, err := backendRequest()
+	if err != nil {
+		return err
+	}
+	since := time.Now().Add(-app.MemoryWindow)
+	if all {
+		since = time.Time{}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := a.DistillMemories(ctx, provider, req, since)
+	for _, mem := range report.Stored {
+		fmt.Printf("+ %s  %s\n", fs.ShortID(mem.ID), mem.Text)
+	}
+	fmt.Printf("Read %d turns in %d conversations: %d new memories, %d already known\n",
+		report.Turns, report.Conversations, len(report.Stored), report.Duplicates)
+	return err
+}
+
+// recallMemories adds the memories relevant to question to req when
+// long-term memory is on. Memory is a help, not a requirement, so failures
+// are reported and the question is asked without it.
+func recallMemories(req *llm.ChatRequest, question string) {
+	cfg, err := config.Load()
+	if err != nil || !cfg.Memory {
+		return
+	}
+	svc, err := openServices()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Asking without memories: %v\n", err)
+		return
+	}
+	defer svc.Close()
+
+	memories, err := svc.app().RecallMemories(question, app.DefaultMemoryRecall)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Asking without memories: %v\n", err)
+		return
+	}
+	req.Messages = llm.WithMemories(req.Messages, memories)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+)
+
+func init() {
+	register("personas", "List personas or set the default one", runPersonas)
+}
+
+// runPersonas lists the personas defined in the vault, or saves the default with -use
+func runPersonas(args []string) error {
+	fset := newFlagSet("personas")
+	use := fset.String("use", "", `make this persona the default ("none" to clear)`)
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	if err := config.EnsurePersonas(); err != nil {
+		return err
+	}
+	personas, err := config.LoadPersonas()
+	if err != nil {
+		return err
+	}
+
+	if *use != "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		cfg.Persona = ""
+		if *use != "none" {
+			p, ok := config.FindPersona(personas, *use)
+			if !ok {
+				return fmt.Errorf("unknown persona %q", *use)
+			}
+			cfg.Persona = p.Name
+		}
+		if err := config.Save(cfg); err != nil {
+			return err
+		}
+		fmt.Printf("Default persona set to %s\n", *use)
+		return nil
+	}
+
+	active, err := config.ActivePersona(*personaFlag)
+	if err != nil {
+		return err
+	}
+	for _, p := range personas {
+		marker := " "
+		if active != nil && active.Name == p.Name {
+			marker = "*"
+		}
+		var overrides []string
+		if p.Model != "" {
+			overrides = append(overrides, "model "+p.Model)
+		}
+		if p.Temperature != nil {
+			overrides = append(overrides, fmt.Sprintf("temperature %.1f", *p.Temperature))
+		}
+		fmt.Printf("%s %-16s %s\n", marker, p.Name, strings.Join(overrides, ", "))
+		fmt.Printf("    %s\n", truncate(p.SystemPrompt, 90))
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+)
+
+func init() {
+	register("profiles", "List chat backend profiles or set the default one", runProfiles)
+}
+
+// runProfiles lists the configured chat backends, or saves the default with -use
+func runProfiles(args []string) error {
+	fset := newFlagSet("profiles")
+	use := fset.String("use", "", "make this profile the default")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	profiles, err := config.Profiles()
+	if err != nil {
+		return err
+	}
+
+	if *use != "" {
+		if _, ok := profiles[*use]; !ok {
+			return fmt.Errorf("unknown profile %q", *use)
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		cfg.Profile = *use
+		if err := config.Save(cfg); err != nil {
+			return err
+		}
+		fmt.Printf("Default profile set to %s\n", *use)
+		return nil
+	}
+
+	active, _, err := config.ActiveProfile(*profileFlag)
+	if err != nil {
+		return err
+	}
+	for _, name := range config.ProfileNames(profiles) {
+		p := profiles[name]
+		marker := " "
+		if name == active {
+			marker = "*"
+		}
+		provider := p.Provider
+		if provider == "" {
+			provider = config.ProviderOllama
+		}
+		line := fmt.Sprintf("%s %-16s %-7s %s %s", marker, name, provider, p.APIURL, p.Model)
+		if len(p.Fallbacks) > 0 {
+			line += " (falls back to " + strings.Join(p.Fallbacks, ", ") + ")"
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
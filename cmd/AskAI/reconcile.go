@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/VarunSharma3520/AskAI/internal/app"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+func init() {
+	register("reconcile", "Compare que_ans.json with Qdrant and optionally repair drift", runReconcile)
+}
+
+// runReconcile reports the differences between the vault and Qdrant and,
+// when asked, copies orphans across, drops duplicates or rebuilds the vault.
+func runReconcile(args []string) error {
+	fset := newFlagSet("reconcile")
+	toQdrant := fset.Bool("to-qdrant", false, "index vault entries missing from Qdrant")
+	toVault := fset.Bool("to-vault", false, "add Qdrant points missing from the vault file")
+	dedupe := fset.Bool("dedupe", false, "remove duplicate copies from both stores")
+	rebuild := fset.Bool("rebuild-vault", false, "rewrite que_ans.json from Qdrant payloads (keeps a .bak)")
+	verbose := fset.Bool("v", false, "list every orphan and duplicate")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	svc, err := openServices()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+	a := svc.app()
+
+	if *rebuild {
+		n, err := a.RebuildVault()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Rebuilt vault file with %d entries from Qdrant\n", n)
+		return nil
+	}
+
+	report, err := a.Reconcile()
+	if err != nil {
+		return err
+	}
+	fmt.Println(report.Summary())
+	if *verbose {
+		printReport(report)
+	}
+
+	if report.Clean() || !(*toQdrant || *toVault || *dedupe) {
+		return nil
+	}
+
+	changes, err := a.Repair(report, app.RepairOptions{
+		ToQdrant: *toQdrant,
+		ToVault:  *toVault,
+		Dedupe:   *dedupe,
+	})
+	fmt.Printf("Applied %d changes\n", changes)
+	return err
+}
+
+// printReport lists the individual entries behind a reconcile summary
+func printReport(r *app.ReconcileReport) {
+	for _, qa := range r.VaultOnly {
+		fmt.Printf("  vault-only   %s  %s\n", fs.ShortID(qa.Key()), truncate(qa.Question, 60))
+	}
+	for _, p := range r.QdrantOnly {
+		fmt.Printf("  qdrant-only  %s  %s\n", fs.ShortID(p.ID), truncate(p.Question, 60))
+	}
+	for _, group := range r.VaultDuplicates {
+		fmt.Printf("  vault-dup    %s  x%d  %s\n", fs.ShortID(group[0].Key()), len(group), truncate(group[0].Question, 60))
+	}
+	for _, group := range r.QdrantDuplicates {
+		fmt.Printf("  qdrant-dup   %s  x%d  %s\n", fs.ShortID(group[0].ID), len(group), truncate(group[0].Question, 60))
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
+)
+
+// routeQuestion picks the route for a question from the routing table in the
+// config and strips an @override prefix from it. Routing is a help, not a
+// requirement, so failures are reported and the question is asked without a route.
+func routeQuestion(question string) (llm.RouteDecision, string) {
+	var routing config.Routing
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Asking without routing: %v\n", err)
+		return llm.RouteDecision{}, question
+	}
+	if cfg.Routing != nil {
+		routing = *cfg.Routing
+	}
+	router, err := llm.NewRouter(routing)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Asking without routing: %v\n", err)
+		return llm.RouteDecision{}, question
+	}
+	if personas, err := config.LoadPersonas(); err == nil {
+		router.Models = config.PersonaModels(personas)
+	}
+	provider, req, err := backendRequest()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Asking without routing: %v\n", err)
+		return llm.RouteDecision{}, question
+	}
+
+	route, question, err := router.Route(context.Background(), provider, req, question)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to classify the question: %v\n", err)
+	}
+	return route, question
+}
+
+// askPersona returns the persona a question is asked as: the route's, if it
+// names one, otherwise the active persona
+func askPersona(route llm.RouteDecision) (*config.Persona, error) {
+	if route.Persona == "" {
+		return config.ActivePersona(*personaFlag)
+	}
+	personas, err := config.LoadPersonas()
+	if err != nil {
+		return nil, err
+	}
+	persona, ok := config.FindPersona(personas, route.Persona)
+	if !ok {
+		return nil, fmt.Errorf("route %s names unknown persona %q", route.Route, route.Persona)
+	}
+	return persona, nil
+}
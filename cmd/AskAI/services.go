@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/VarunSharma3520/AskAI/internal/app"
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/logger"
+	"github.com/VarunSharma3520/AskAI/internal/vector"
+)
+
+// services holds the connections shared by the UI and the CLI commands
+type services struct {
+	conn      *grpc.ClientConn
+	logger    *logger.Logger
+	store     *vector.VectorStore
+	kb        string
+	vaultPath string
+}
+
+// activeKnowledgeBase resolves the knowledge base selected by flag, environment or config
+func activeKnowledgeBase() string {
+	return config.KnowledgeBase(*kbFlag)
+}
+
+// openServices ensures the vault exists and connects the logger, embedder and vector store
+func openServices() (*services, error) {
+	// Resolve the knowledge base and its folder inside the vault
+	kb := activeKnowledgeBase()
+	vaultPath := config.KnowledgeBasePath(kb)
+
+	// Ensure vault exists before starting UI
+	if err := fs.EnsureVaultExists(vaultPath); err != nil {
+		return nil, fmt.Errorf("failed to ensure vault folder exists: %w", err)
+	}
+
+	// Create a gRPC connection to Qdrant
+	conn, err := grpc.NewClient("localhost:6334", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Qdrant client: %w", err)
+	}
+
+	// Initialize the Ollama embedder
+	ollamaURL := os.Getenv("OLLAMA_URL")
+	if ollamaURL == "" {
+		ollamaURL = "http://localhost:11434"
+	}
+
+	// Create Ollama embedder with mxbai-embed-large model
+	embedder := vector.NewOllamaEmbedder(ollamaURL, "mxbai-embed-large", nil)
+
+	// Initialize logger
+	logPath := filepath.Join(config.VaultPath(), "askai.log")
+
+	// Create the log directory if it doesn't exist
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	appLogger, err := logger.NewLogger(logPath)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+
+	// Write the built-in personas to the vault so they can be edited
+	if err := config.EnsurePersonas(); err != nil {
+		appLogger.Warn("Failed to write default personas", map[string]interface{}{"error": err.Error()})
+	}
+
+	// Likewise the built-in prompt templates
+	if err := config.EnsureTemplates(); err != nil {
+		appLogger.Warn("Failed to write default templates", map[string]interface{}{"error": err.Error()})
+	}
+
+	// Initialize vector store with the gRPC connection, embedder, and logger
+	vectorStore := vector.NewVectorStore(conn, config.CollectionName(kb), embedder, appLogger)
+
+	// Ensure the collection exists with the correct vector size
+	// For mxbai-embed-large, the vector size is 1024
+	vectorSize := uint64(1024)
+	if err := vectorStore.EnsureCollection(vectorSize); err != nil {
+		appLogger.Error("Failed to ensure Qdrant collection exists", err, nil)
+		appLogger.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to ensure Qdrant collection exists: %w", err)
+	}
+
+	return &services{
+		conn:      conn,
+		logger:    appLogger,
+		store:     vectorStore,
+		kb:        kb,
+		vaultPath: vaultPath,
+	}, nil
+}
+
+// app returns an App bound to the opened stores
+func (s *services) app() *app.App {
+	return app.New(s.store, s.vaultPath)
+}
+
+// Close releases the logger and the Qdrant connection
+func (s *services) Close() {
+	s.logger.Close()
+	s.conn.Close()
+}
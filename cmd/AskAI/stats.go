@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/VarunSharma3520/AskAI/internal/app"
+	"github.com/VarunSharma3520/AskAI/internal/config"
+)
+
+func init() {
+	register("stats", "Show token usage and speed per model", runStats)
+}
+
+// runStats prints aggregate answer metrics per model from the vault
+func runStats(args []string) error {
+	fset := newFlagSet("stats")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	stats, err := app.New(nil, config.KnowledgeBasePath(activeKnowledgeBase())).ModelStats()
+	if err != nil {
+		return err
+	}
+	if len(stats) == 0 {
+		fmt.Println("No answers with recorded metrics yet")
+		return nil
+	}
+
+	fmt.Printf("%-24s %7s %10s %10s %9s %9s %9s\n", "MODEL", "ANSWERS", "PROMPT", "COMPLETION", "TOK/S", "TTFT", "TOTAL")
+	for _, s := range stats {
+		fmt.Printf("%-24s %7d %10d %10d %9.1f %8.2fs %8.2fs\n",
+			truncate(s.Model, 24), s.Answers, s.PromptTokens, s.CompletionTokens,
+			s.AvgTokensPerSec, s.AvgTTFTMillis/1000, s.AvgTotalMillis/1000)
+	}
+	return nil
+}
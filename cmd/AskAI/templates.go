@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+)
+
+func init() {
+	register("templates", "List prompt templates or show one", runTemplates)
+}
+
+// runTemplates lists the prompt templates in the vault with their
+// placeholders, or prints the source of the named one
+func runTemplates(args []string) error {
+	fset := newFlagSet("templates")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	if err := config.EnsureTemplates(); err != nil {
+		return err
+	}
+	templates, err := config.LoadTemplates()
+	if err != nil {
+		return err
+	}
+
+	if fset.NArg() > 0 {
+		t, ok := config.FindTemplate(templates, strings.Join(fset.Args(), " "))
+		if !ok {
+			return fmt.Errorf("unknown template %q", strings.Join(fset.Args(), " "))
+		}
+		fmt.Print(t.Text)
+		return nil
+	}
+
+	fmt.Printf("Templates in %s:\n", config.TemplatesPath())
+	for _, t := range templates {
+		vars := strings.Join(t.Variables(), ", ")
+		if vars == "" {
+			vars = "no placeholders"
+		}
+		fmt.Printf("  %-16s %s\n", t.Name, vars)
+		if t.Description != "" {
+			fmt.Printf("    %s\n", truncate(t.Description, 90))
+		}
+	}
+	return nil
+}
@@ -1 +1,84 @@
-package app
\ No newline at end of file
+// Package app provides the core application logic for AskAI.
+// It coordinates operations that must keep the vault files and the Qdrant
+// collection consistent with each other, and is shared by the CLI and the TUI.
+package app
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/vector"
+)
+
+// App bundles the stores that every maintenance operation works against.
+type App struct {
+	Store     *vector.VectorStore
+	VaultPath string
+
+	vaultMu    sync.Mutex // Serializes changes to the vault file
+	memoriesMu sync.Mutex // Serializes changes to the memories file
+}
+
+// New creates an App for the given vector store and vault directory.
+//
+// Parameters:
+//   - store: An initialized vector store
+//   - vaultPath: Filesystem path of the vault
+//
+// Returns:
+//   - *App: A new App instance
+func New(store *vector.VectorStore, vaultPath string) *App {
+	return &App{
+		Store:     store,
+		VaultPath: vaultPath,
+	}
+}
+
+// matchesPoint reports whether a vault entry corresponds to a Qdrant point.
+// Entries written before IDs were recorded are matched by their content.
+func matchesPoint(qa fs.QA, p vector.QAPoint) bool {
+	if qa.ID != "" {
+		return qa.ID == p.ID
+	}
+	return qa.Question == p.Question && qa.Answer == p.Answer
+}
+
+// updateVault loads the vault file, applies update to it and saves it if
+// update reports a change. The file is locked for the whole cycle, so slow
+// work such as embedding belongs before the call, not inside update.
+func (a *App) updateVault(update func(qas *fs.QAFile) bool) error {
+	a.vaultMu.Lock()
+	defer a.vaultMu.Unlock()
+
+	qas, err := fs.LoadQAs(a.VaultPath)
+	if err != nil {
+		return err
+	}
+	if !update(qas) {
+		return nil
+	}
+	return fs.SaveQAs(a.VaultPath, qas)
+}
+
+// removeFromVault deletes the entries matching the given points from the vault file.
+func (a *App) removeFromVault(points ...vector.QAPoint) error {
+	return a.updateVault(func(qas *fs.QAFile) bool {
+		return qas.Remove(func(qa fs.QA) bool {
+			for _, p := range points {
+				if matchesPoint(qa, p) {
+					return true
+				}
+			}
+			return false
+		}) > 0
+	})
+}
+
+// requireStore returns an error if the App has no vector store configured.
+func (a *App) requireStore() error {
+	if a.Store == nil {
+		return fmt.Errorf("vector store is not initialized")
+	}
+	return nil
+}
@@ -0,0 +1,388 @@
+package app
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/vector"
+)
+
+// Archive layout
+const (
+	backupFormatVersion = 1
+	manifestName        = "manifest.json"
+	pointsName          = "points.jsonl"
+	vaultPrefix         = "vault/"
+	rootConfigName      = "config.json"
+	restoreBatchSize    = 128
+)
+
+// BackupManifest describes the contents of a backup archive.
+type BackupManifest struct {
+	Version       int               `json:"version"`
+	CreatedAt     time.Time         `json:"created_at"`
+	KnowledgeBase string            `json:"knowledge_base"`
+	Collection    string            `json:"collection"`
+	VectorSize    int               `json:"vector_size"`
+	PointCount    int               `json:"point_count"`
+	PointsSHA256  string            `json:"points_sha256"`
+	Files         map[string]string `json:"files"` // Archive path -> SHA-256 of the contents
+}
+
+// backupPoint is the on-disk form of a Qdrant point in points.jsonl.
+type backupPoint struct {
+	ID      string            `json:"id"`
+	Vector  []float32         `json:"vector"`
+	Payload map[string]string `json:"payload"`
+}
+
+// Backup writes a gzip-compressed tar archive holding the knowledge base's
+// vault files and every point of its collection with vectors and payloads.
+// The shared config file is only included when asked for.
+//
+// Parameters:
+//   - w: Where to write the archive
+//   - kb: The name of the knowledge base being backed up
+//   - withConfig: Whether to include the config file at the vault root
+//
+// Returns:
+//   - *BackupManifest: The manifest stored in the archive
+//   - error: An error if either store cannot be read or the archive cannot be written
+func (a *App) Backup(w io.Writer, kb string, withConfig bool) (*BackupManifest, error) {
+	if err := a.requireStore(); err != nil {
+		return nil, err
+	}
+
+	points, err := a.Store.ScrollAll(true)
+	if err != nil {
+		return nil, err
+	}
+
+	var pointsData bytes.Buffer
+	enc := json.NewEncoder(&pointsData)
+	vectorSize := 0
+	for _, p := range points {
+		if vectorSize == 0 {
+			vectorSize = len(p.Vector)
+		}
+		if err := enc.Encode(backupPoint{ID: p.ID, Vector: p.Vector, Payload: p.Payload}); err != nil {
+			return nil, fmt.Errorf("failed to encode point %s: %w", p.ID, err)
+		}
+	}
+
+	files, err := a.collectVaultFiles(withConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &BackupManifest{
+		Version:       backupFormatVersion,
+		CreatedAt:     time.Now(),
+		KnowledgeBase: kb,
+		Collection:    a.Store.Collection(),
+		VectorSize:    vectorSize,
+		PointCount:    len(points),
+		PointsSHA256:  checksum(pointsData.Bytes()),
+		Files:         make(map[string]string, len(files)),
+	}
+	for name, data := range files {
+		manifest.Files[name] = checksum(data)
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	if err := writeTarFile(tw, manifestName, manifestData); err != nil {
+		return nil, err
+	}
+	if err := writeTarFile(tw, pointsName, pointsData.Bytes()); err != nil {
+		return nil, err
+	}
+	for name, data := range files {
+		if err := writeTarFile(tw, name, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	return manifest, nil
+}
+
+// Restore reads an archive written by Backup, verifies every checksum and
+// restores the vault files and points into this App's vault and Qdrant
+// collection. Existing vault files are only overwritten when force is set; an
+// archived config file is only restored where none exists yet, so restoring
+// never replaces the config other knowledge bases share. After the points are
+// written the collection is counted to confirm nothing was lost.
+//
+// Parameters:
+//   - r: The archive to read
+//   - force: Whether existing vault files may be overwritten
+//
+// Returns:
+//   - *BackupManifest: The manifest of the restored archive
+//   - []string: The archived files left alone because they already exist
+//   - error: An error if the archive is corrupt or either store cannot be written
+func (a *App) Restore(r io.Reader, force bool) (*BackupManifest, []string, error) {
+	if err := a.requireStore(); err != nil {
+		return nil, nil, err
+	}
+
+	entries, err := readArchive(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	manifestData, ok := entries[manifestName]
+	if !ok {
+		return nil, nil, fmt.Errorf("archive has no %s", manifestName)
+	}
+	var manifest BackupManifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if manifest.Version != backupFormatVersion {
+		return nil, nil, fmt.Errorf("unsupported backup format version %d", manifest.Version)
+	}
+
+	// Verify everything before touching either store
+	pointsData := entries[pointsName]
+	if checksum(pointsData) != manifest.PointsSHA256 {
+		return nil, nil, fmt.Errorf("checksum mismatch for %s", pointsName)
+	}
+	for name, sum := range manifest.Files {
+		data, ok := entries[name]
+		if !ok {
+			return nil, nil, fmt.Errorf("archive is missing %s", name)
+		}
+		if checksum(data) != sum {
+			return nil, nil, fmt.Errorf("checksum mismatch for %s", name)
+		}
+	}
+
+	points, err := decodePoints(pointsData)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(points) != manifest.PointCount {
+		return nil, nil, fmt.Errorf("manifest lists %d points but archive holds %d", manifest.PointCount, len(points))
+	}
+
+	restore := make([]string, 0, len(manifest.Files))
+	var skipped []string
+	for name := range manifest.Files {
+		dest := a.restorePath(name)
+		_, err := os.Stat(dest)
+		switch {
+		case err != nil:
+			restore = append(restore, name)
+		case name == vaultPrefix+rootConfigName:
+			skipped = append(skipped, dest)
+		case !force:
+			return nil, nil, fmt.Errorf("%s already exists (use force to overwrite)", dest)
+		default:
+			restore = append(restore, name)
+		}
+	}
+
+	if err := a.restorePoints(points, manifest.VectorSize); err != nil {
+		return nil, nil, err
+	}
+
+	for _, name := range restore {
+		dest := a.restorePath(name)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return nil, nil, fmt.Errorf("failed to create %s: %w", filepath.Dir(dest), err)
+		}
+		if err := os.WriteFile(dest, entries[name], 0644); err != nil {
+			return nil, nil, fmt.Errorf("failed to restore %s: %w", dest, err)
+		}
+	}
+
+	after, err := a.Store.Count()
+	if err != nil {
+		return nil, nil, err
+	}
+	if after < uint64(manifest.PointCount) {
+		return nil, nil, fmt.Errorf("collection holds %d points after restore, expected at least %d", after, manifest.PointCount)
+	}
+	return &manifest, skipped, nil
+}
+
+// restorePoints creates the collection if needed and upserts the points in batches.
+func (a *App) restorePoints(points []vector.QAPoint, vectorSize int) error {
+	if vectorSize > 0 {
+		if err := a.Store.EnsureCollection(uint64(vectorSize)); err != nil {
+			return err
+		}
+	}
+
+	for start := 0; start < len(points); start += restoreBatchSize {
+		end := start + restoreBatchSize
+		if end > len(points) {
+			end = len(points)
+		}
+		if err := a.Store.UpsertPoints(points[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectVaultFiles reads the files that belong in a backup: everything in the
+// knowledge base's vault folder except logs, backups and other knowledge
+// bases, plus the shared config file if withConfig is set.
+func (a *App) collectVaultFiles(withConfig bool) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	kbRoot := filepath.Clean(config.KnowledgeBasesDir())
+
+	err := filepath.WalkDir(a.VaultPath, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if filepath.Clean(p) == kbRoot {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(p, ".log") || strings.HasSuffix(p, ".bak") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(a.VaultPath, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", p, err)
+		}
+		files[vaultPrefix+filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect vault files: %w", err)
+	}
+
+	// The config file lives at the vault root, outside named knowledge bases
+	name := vaultPrefix + rootConfigName
+	if !withConfig {
+		delete(files, name)
+		return files, nil
+	}
+	if _, ok := files[name]; !ok {
+		if data, err := os.ReadFile(filepath.Join(config.VaultPath(), rootConfigName)); err == nil {
+			files[name] = data
+		}
+	}
+	return files, nil
+}
+
+// restorePath maps an archive path to its destination in this App's vault.
+// The config file always goes back to the vault root.
+func (a *App) restorePath(name string) string {
+	rel := strings.TrimPrefix(name, vaultPrefix)
+	if rel == rootConfigName {
+		return filepath.Join(config.VaultPath(), rootConfigName)
+	}
+	return filepath.Join(a.VaultPath, filepath.FromSlash(rel))
+}
+
+// readArchive loads every regular file of a gzip-compressed tar archive into memory.
+func readArchive(r io.Reader) (map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer gz.Close()
+
+	entries := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		// Reject entries that would escape the vault when restored
+		name := path.Clean(hdr.Name)
+		if name == ".." || strings.HasPrefix(name, "../") || path.IsAbs(name) {
+			return nil, fmt.Errorf("archive entry %q has an unsafe path", hdr.Name)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from archive: %w", hdr.Name, err)
+		}
+		entries[name] = data
+	}
+	return entries, nil
+}
+
+// decodePoints parses the points.jsonl contents of an archive.
+func decodePoints(data []byte) ([]vector.QAPoint, error) {
+	var points []vector.QAPoint
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var bp backupPoint
+		if err := dec.Decode(&bp); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", pointsName, err)
+		}
+		points = append(points, vector.QAPoint{ID: bp.ID, Vector: bp.Vector, Payload: bp.Payload})
+	}
+	return points, nil
+}
+
+// writeTarFile adds a regular file to a tar archive.
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write %s header: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// checksum returns the hex-encoded SHA-256 of data.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
+	"github.com/VarunSharma3520/AskAI/internal/vector"
+)
+
+// TitleWindow limits background titling to recently updated conversations;
+// older ones are titled on request
+const TitleWindow = 7 * 24 * time.Hour
+
+// TitleConversations generates a title and synopsis for every conversation
+// with turns its current ones don't cover, saves them in the vault and stores
+// them in Qdrant as a conversation point embedded by title and synopsis.
+//
+// Parameters:
+//   - ctx: Cancels the run; conversations finished before are kept
+//   - provider: The backend to ask
+//   - req: The model and options to ask with
+//   - since: Conversations last updated before this are skipped
+//   - exclude: The ID of a conversation still in progress, or ""
+//
+// Returns:
+//   - []*fs.Conversation: The conversations titled
+//   - error: The first error; the conversations titled until then are returned
+func (a *App) TitleConversations(ctx context.Context, provider llm.ChatProvider, req llm.ChatRequest, since time.Time, exclude string) ([]*fs.Conversation, error) {
+	if err := a.requireStore(); err != nil {
+		return nil, err
+	}
+
+	convs, err := fs.ListConversations(a.VaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var titled []*fs.Conversation
+	for _, c := range convs {
+		if c.ID == exclude || c.Updated.Before(since) || !c.NeedsTitle() {
+			continue
+		}
+		from := 0
+		if c.Summary != "" {
+			from = c.Summarized
+		}
+		title, err := llm.TitleConversation(ctx, provider, req, c.Summary, c.Messages[from:])
+		if err != nil {
+			return titled, err
+		}
+
+		c.SetTitle(title.Title, title.Synopsis)
+		if err := a.indexConversation(c); err != nil {
+			return titled, err
+		}
+		if err := fs.SaveConversation(a.VaultPath, c); err != nil {
+			return titled, err
+		}
+		titled = append(titled, c)
+	}
+	return titled, nil
+}
+
+// indexConversation upserts the conversation's point, keyed by its ID
+func (a *App) indexConversation(c *fs.Conversation) error {
+	embedding, err := a.Store.Embed(c.Title + "\n\n" + c.Synopsis)
+	if err != nil {
+		return fmt.Errorf("failed to embed conversation: %w", err)
+	}
+	return a.Store.UpsertPoints([]vector.QAPoint{{
+		ID:       c.ID,
+		Question: c.Title,
+		Answer:   c.Synopsis,
+		Payload:  vector.ConversationPayload(c.Title, c.Synopsis, c.Turns(), c.Updated),
+		Vector:   embedding,
+	}})
+}
+
+// ConversationTitles maps the ID of each Q&A pair asked in a conversation to
+// the conversation's title, so history lists can show where a pair came from.
+func (a *App) ConversationTitles() (map[string]string, error) {
+	convs, err := fs.ListConversations(a.VaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	titles := make(map[string]string)
+	for _, c := range convs {
+		_, sources, _ := completedTurns(c.Messages, 0)
+		for _, id := range sources {
+			titles[id] = c.Title
+		}
+	}
+	return titles, nil
+}
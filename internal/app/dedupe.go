@@ -0,0 +1,157 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/vector"
+)
+
+// DefaultDuplicateThreshold is the question similarity above which two
+// stored Q&As are considered near-duplicates.
+const DefaultDuplicateThreshold = 0.92
+
+// mergeSeparator separates answers combined by MergeCluster.
+const mergeSeparator = "\n\n---\n\n"
+
+// Cluster is a group of stored Q&As whose questions are near-duplicates.
+type Cluster struct {
+	Entries []vector.QAPoint
+}
+
+// FindDuplicates clusters all stored Q&As whose question vectors are at
+// least threshold similar (cosine).
+//
+// Parameters:
+//   - threshold: Minimum similarity between 0 and 1
+//
+// Returns:
+//   - []Cluster: Clusters of two or more entries, largest first
+//   - error: An error if the points cannot be read from Qdrant
+func (a *App) FindDuplicates(threshold float64) ([]Cluster, error) {
+	if err := a.requireStore(); err != nil {
+		return nil, err
+	}
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("threshold must be in (0, 1], got %.2f", threshold)
+	}
+
+	points, err := a.Store.ScrollQAs(true)
+	if err != nil {
+		return nil, err
+	}
+
+	var clusters []Cluster
+	for _, group := range vector.ClusterBySimilarity(points, threshold) {
+		clusters = append(clusters, Cluster{Entries: group})
+	}
+	return clusters, nil
+}
+
+// KeepOne keeps the entry at index keep and deletes the rest of the cluster
+// from both the vault file and Qdrant.
+func (a *App) KeepOne(c Cluster, keep int) error {
+	if keep < 0 || keep >= len(c.Entries) {
+		return fmt.Errorf("entry %d is not part of the cluster", keep)
+	}
+	return a.DeleteEntries(c.others(keep)...)
+}
+
+// MergeCluster appends the distinct answers of the other entries to the
+// entry at index keep, then deletes the others from both stores.
+// The kept entry retains its question vector, so no re-embedding is needed.
+func (a *App) MergeCluster(c Cluster, keep int) error {
+	if err := a.requireStore(); err != nil {
+		return err
+	}
+	if keep < 0 || keep >= len(c.Entries) {
+		return fmt.Errorf("entry %d is not part of the cluster", keep)
+	}
+
+	kept := c.Entries[keep]
+	answers := []string{kept.Answer}
+	for _, other := range c.others(keep) {
+		if !containsString(answers, other.Answer) {
+			answers = append(answers, other.Answer)
+		}
+	}
+	merged := strings.Join(answers, mergeSeparator)
+
+	payload := make(map[string]string, len(kept.Payload))
+	for k, v := range kept.Payload {
+		payload[k] = v
+	}
+	payload["answer"] = merged
+
+	if err := a.Store.StoreVector(kept.ID, kept.Vector, payload); err != nil {
+		return err
+	}
+
+	err := a.updateVault(func(qas *fs.QAFile) bool {
+		i := qas.Index(func(qa fs.QA) bool { return matchesPoint(qa, kept) })
+		if i < 0 {
+			return false
+		}
+		qas.QAs[i].ID = kept.ID
+		qas.QAs[i].Answer = merged
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.DeleteEntries(c.others(keep)...)
+}
+
+// DeleteEntries removes the given entries from Qdrant and the vault file.
+func (a *App) DeleteEntries(points ...vector.QAPoint) error {
+	if err := a.requireStore(); err != nil {
+		return err
+	}
+	if len(points) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(points))
+	for _, p := range points {
+		ids = append(ids, p.ID)
+	}
+	if err := a.Store.DeletePoints(ids...); err != nil {
+		return err
+	}
+	return a.removeFromVault(points...)
+}
+
+// others returns every entry of the cluster except the one at index keep.
+func (c Cluster) others(keep int) []vector.QAPoint {
+	var rest []vector.QAPoint
+	for i, e := range c.Entries {
+		if i != keep {
+			rest = append(rest, e)
+		}
+	}
+	return rest
+}
+
+// Newest returns the index of the most recently stored entry.
+// Timestamps are RFC 3339 strings, so lexical order matches time order.
+func (c Cluster) Newest() int {
+	newest := 0
+	for i, e := range c.Entries {
+		if e.StoredAt > c.Entries[newest].StoredAt {
+			newest = i
+		}
+	}
+	return newest
+}
+
+// containsString reports whether s is in list.
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
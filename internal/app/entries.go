@@ -0,0 +1,219 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/vector"
+)
+
+// ListQAs returns every Q&A stored in the vault, newest first.
+func (a *App) ListQAs() ([]fs.QA, error) {
+	qas, err := fs.LoadQAs(a.VaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]fs.QA, len(qas.QAs))
+	for i, qa := range qas.QAs {
+		list[len(qas.QAs)-1-i] = qa
+	}
+	return list, nil
+}
+
+// FindQA looks up a vault entry by ID or by an unambiguous ID prefix.
+//
+// Returns:
+//   - fs.QA: The matching entry
+//   - error: An error if no entry or more than one entry matches
+func (a *App) FindQA(id string) (fs.QA, error) {
+	qas, err := fs.LoadQAs(a.VaultPath)
+	if err != nil {
+		return fs.QA{}, err
+	}
+	i, err := findIndex(qas, id)
+	if err != nil {
+		return fs.QA{}, err
+	}
+	return qas.QAs[i], nil
+}
+
+// DeleteQA removes a Q&A from both the vault file and Qdrant.
+//
+// Parameters:
+//   - id: The entry ID or an unambiguous prefix of it
+func (a *App) DeleteQA(id string) error {
+	if err := a.requireStore(); err != nil {
+		return err
+	}
+
+	qas, err := fs.LoadQAs(a.VaultPath)
+	if err != nil {
+		return err
+	}
+	i, err := findIndex(qas, id)
+	if err != nil {
+		return err
+	}
+	qa := qas.QAs[i]
+
+	pointID, err := a.resolvePointID(qa)
+	if err != nil {
+		return err
+	}
+	if pointID != "" {
+		if err := a.Store.DeletePoints(pointID); err != nil {
+			return err
+		}
+	}
+
+	return a.updateVault(func(qas *fs.QAFile) bool {
+		return qas.Remove(func(e fs.QA) bool { return e.Key() == qa.Key() }) > 0
+	})
+}
+
+// EditQA updates the question and/or answer of a stored Q&A in both stores.
+// Empty arguments keep the current value. The question is re-embedded only
+// when it changes; otherwise the existing vector is reused. An entry that has
+// no point in Qdrant gets one.
+//
+// Parameters:
+//   - id: The entry ID or an unambiguous prefix of it
+//   - question: The new question, or "" to keep the current one
+//   - answer: The new answer, or "" to keep the current one
+//
+// Returns:
+//   - fs.QA: The updated entry
+//   - error: An error if the entry cannot be found or either store fails
+func (a *App) EditQA(id, question, answer string) (fs.QA, error) {
+	if err := a.requireStore(); err != nil {
+		return fs.QA{}, err
+	}
+
+	qas, err := fs.LoadQAs(a.VaultPath)
+	if err != nil {
+		return fs.QA{}, err
+	}
+	i, err := findIndex(qas, id)
+	if err != nil {
+		return fs.QA{}, err
+	}
+	qa := qas.QAs[i]
+
+	if question == "" {
+		question = qa.Question
+	}
+	if answer == "" {
+		answer = qa.Answer
+	}
+	if question == qa.Question && answer == qa.Answer {
+		return qa, nil
+	}
+
+	pointID, err := a.resolvePointID(qa)
+	if err != nil {
+		return fs.QA{}, err
+	}
+
+	payload := vector.QAPayload(qa.Question, qa.Answer, qa.Time)
+	var embedding []float32
+
+	if pointID != "" {
+		// An entry whose point is gone from Qdrant is stored again under its ID
+		existing, err := a.Store.GetQA(pointID)
+		if err != nil && !errors.Is(err, vector.ErrPointNotFound) {
+			return fs.QA{}, err
+		}
+		if existing != nil {
+			for k, v := range existing.Payload {
+				payload[k] = v
+			}
+			embedding = existing.Vector
+		}
+	} else {
+		pointID = qa.Key()
+	}
+
+	if question != qa.Question || embedding == nil {
+		embedding, err = a.Store.Embed(question)
+		if err != nil {
+			return fs.QA{}, fmt.Errorf("failed to embed question: %w", err)
+		}
+	}
+
+	payload["question"] = question
+	payload["answer"] = answer
+	payload["edited_at"] = time.Now().Format(time.RFC3339)
+
+	if err := a.Store.StoreVector(pointID, embedding, payload); err != nil {
+		return fs.QA{}, err
+	}
+
+	key := qa.Key()
+	qa.ID = pointID
+	qa.Question = question
+	qa.Answer = answer
+
+	err = a.updateVault(func(qas *fs.QAFile) bool {
+		i := qas.Index(func(e fs.QA) bool { return e.Key() == key })
+		if i < 0 {
+			return false
+		}
+		qas.QAs[i] = qa
+		return true
+	})
+	if err != nil {
+		return fs.QA{}, err
+	}
+	return qa, nil
+}
+
+// resolvePointID returns the Qdrant point ID of a vault entry.
+// Entries written before IDs were recorded are located by their content;
+// an empty ID means the entry has no point in Qdrant.
+func (a *App) resolvePointID(qa fs.QA) (string, error) {
+	if qa.ID != "" {
+		return qa.ID, nil
+	}
+
+	points, err := a.Store.ScrollQAs(false)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range points {
+		if matchesPoint(qa, p) {
+			return p.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// findIndex returns the position of the entry whose key equals id or
+// starts with it, requiring the prefix to be unambiguous.
+func findIndex(qas *fs.QAFile, id string) (int, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return -1, fmt.Errorf("no ID given")
+	}
+
+	if i := qas.Index(func(qa fs.QA) bool { return qa.Key() == id }); i >= 0 {
+		return i, nil
+	}
+
+	found := -1
+	for i, qa := range qas.QAs {
+		if strings.HasPrefix(qa.Key(), id) {
+			if found >= 0 {
+				return -1, fmt.Errorf("ID prefix %q is ambiguous", id)
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return -1, fmt.Errorf("no Q&A with ID %q", id)
+	}
+	return found, nil
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/vector"
+)
+
+// KnowledgeBases lists every knowledge base that has a Qdrant collection or a
+// vault folder, sorted by name.
+func (a *App) KnowledgeBases() ([]string, error) {
+	if err := a.requireStore(); err != nil {
+		return nil, err
+	}
+
+	found := map[string]bool{config.DefaultKnowledgeBase: true}
+
+	collections, err := a.Store.ListCollections()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range collections {
+		if kb := config.KnowledgeBaseFromCollection(c); kb != "" {
+			found[kb] = true
+		}
+	}
+
+	entries, err := os.ReadDir(config.KnowledgeBasesDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to list knowledge base folders: %w", err)
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			found[e.Name()] = true
+		}
+	}
+
+	names := make([]string, 0, len(found))
+	for kb := range found {
+		names = append(names, kb)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// SearchKnowledgeBases finds the stored Q&As most similar to question across
+// several knowledge bases. The question is embedded once and every base is
+// searched with the same vector; results are merged by score.
+//
+// Parameters:
+//   - kbs: The knowledge bases to search
+//   - question: The text to search for
+//   - limit: The maximum number of results overall
+//
+// Returns:
+//   - []vector.ScoredQA: The best matches, highest score first
+//   - error: An error if embedding or any search fails
+func (a *App) SearchKnowledgeBases(kbs []string, question string, limit int) ([]vector.ScoredQA, error) {
+	if err := a.requireStore(); err != nil {
+		return nil, err
+	}
+
+	embedding, err := a.Store.Embed(question)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed question: %w", err)
+	}
+
+	collections, err := a.Store.ListCollections()
+	if err != nil {
+		return nil, err
+	}
+	exists := make(map[string]bool, len(collections))
+	for _, c := range collections {
+		exists[c] = true
+	}
+
+	var hits []vector.ScoredQA
+	for _, kb := range kbs {
+		// Knowledge bases that were never written to have no collection yet
+		if !exists[config.CollectionName(kb)] {
+			continue
+		}
+		store := a.Store.WithCollection(config.CollectionName(kb))
+		results, err := store.SearchQAs(embedding, uint64(limit))
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, results...)
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Score > hits[j].Score
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
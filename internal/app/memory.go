@@ -0,0 +1,314 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
+	"github.com/VarunSharma3520/AskAI/internal/vector"
+)
+
+// Memory recall and distillation settings
+const (
+	// DefaultMemoryRecall is the number of memories recalled for a question
+	DefaultMemoryRecall = 5
+	// MemoryRecallScore is the similarity a memory needs to be recalled
+	MemoryRecallScore = 0.55
+	// memoryDuplicateScore is the similarity above which a new memory is
+	// taken to restate an existing one and is not stored
+	memoryDuplicateScore = 0.92
+	// MemoryWindow limits distillation to recently updated conversations
+	MemoryWindow = 7 * 24 * time.Hour
+)
+
+// ListMemories returns every long-term memory in the vault, newest first.
+func (a *App) ListMemories() ([]fs.Memory, error) {
+	mf, err := fs.LoadMemories(a.VaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]fs.Memory, len(mf.Memories))
+	for i, mem := range mf.Memories {
+		list[len(mf.Memories)-1-i] = mem
+	}
+	return list, nil
+}
+
+// FindMemory looks up a memory by ID or by an unambiguous ID prefix.
+func (a *App) FindMemory(id string) (fs.Memory, error) {
+	mf, err := fs.LoadMemories(a.VaultPath)
+	if err != nil {
+		return fs.Memory{}, err
+	}
+	i, err := mf.Find(id)
+	if err != nil {
+		return fs.Memory{}, err
+	}
+	return mf.Memories[i], nil
+}
+
+// StoreMemory embeds a memory, stores it in Qdrant as a memory point and
+// appends it to the vault's memories file. A memory that restates one
+// already stored is skipped.
+//
+// Parameters:
+//   - mem: The memory; its ID and creation time are filled in
+//
+// Returns:
+//   - fs.Memory: The stored memory
+//   - bool: False if the memory was skipped as a duplicate
+//   - error: An error if embedding or either store fails
+func (a *App) StoreMemory(mem fs.Memory) (fs.Memory, bool, error) {
+	if err := a.requireStore(); err != nil {
+		return fs.Memory{}, false, err
+	}
+
+	embedding, err := a.Store.Embed(mem.Text)
+	if err != nil {
+		return fs.Memory{}, false, fmt.Errorf("failed to embed memory: %w", err)
+	}
+	similar, err := a.Store.SearchMemories(embedding, 1)
+	if err != nil {
+		return fs.Memory{}, false, err
+	}
+	if len(similar) > 0 && similar[0].Score >= memoryDuplicateScore {
+		return fs.Memory{}, false, nil
+	}
+
+	mem.ID = fs.MemoryID(mem.Text)
+	mem.Created = time.Now()
+	if mem.Kind == "" {
+		mem.Kind = fs.MemoryFact
+	}
+	point := vector.QAPoint{
+		ID:      mem.ID,
+		Answer:  mem.Text,
+		Payload: vector.MemoryPayload(mem.Text, mem.Kind, mem.Sources, mem.Created),
+		Vector:  embedding,
+	}
+	if mem.Conversation != "" {
+		point.Payload["conversation"] = mem.Conversation
+	}
+	if err := a.Store.UpsertPoints([]vector.QAPoint{point}); err != nil {
+		return fs.Memory{}, false, err
+	}
+
+	stored, added := mem, false
+	err = a.updateMemories(func(mf *fs.MemoryFile) bool {
+		if i, err := mf.Find(mem.ID); err == nil && mf.Memories[i].ID == mem.ID {
+			stored = mf.Memories[i]
+			return false
+		}
+		mf.Memories = append(mf.Memories, mem)
+		added = true
+		return true
+	})
+	if err != nil {
+		return fs.Memory{}, false, err
+	}
+	return stored, added, nil
+}
+
+// DeleteMemory removes a memory from both the vault and Qdrant.
+//
+// Parameters:
+//   - id: The memory ID or an unambiguous prefix of it
+//
+// Returns:
+//   - fs.Memory: The deleted memory
+//   - error: An error if no memory matches or either store fails
+func (a *App) DeleteMemory(id string) (fs.Memory, error) {
+	if err := a.requireStore(); err != nil {
+		return fs.Memory{}, err
+	}
+
+	mf, err := fs.LoadMemories(a.VaultPath)
+	if err != nil {
+		return fs.Memory{}, err
+	}
+	i, err := mf.Find(id)
+	if err != nil {
+		return fs.Memory{}, err
+	}
+	mem := mf.Memories[i]
+
+	if err := a.Store.DeletePoints(mem.ID); err != nil {
+		return fs.Memory{}, err
+	}
+	err = a.updateMemories(func(mf *fs.MemoryFile) bool {
+		i := slices.IndexFunc(mf.Memories, func(m fs.Memory) bool { return m.ID == mem.ID })
+		if i < 0 {
+			return false
+		}
+		mf.Memories = slices.Delete(mf.Memories, i, i+1)
+		return true
+	})
+	if err != nil {
+		return fs.Memory{}, err
+	}
+	return mem, nil
+}
+
+// updateMemories loads the memories file, applies update to it and saves it
+// if update reports a change, holding the file's lock for the whole cycle
+// like updateVault does for the vault file.
+func (a *App) updateMemories(update func(mf *fs.MemoryFile) bool) error {
+	a.memoriesMu.Lock()
+	defer a.memoriesMu.Unlock()
+
+	mf, err := fs.LoadMemories(a.VaultPath)
+	if err != nil {
+		return err
+	}
+	if !update(mf) {
+		return nil
+	}
+	return fs.SaveMemories(a.VaultPath, mf)
+}
+
+// RecallMemories returns the memories relevant to a question, most similar first.
+//
+// Parameters:
+//   - question: The question about to be asked
+//   - limit: The most memories to return
+//
+// Returns:
+//   - []string: The text of each memory scoring at least MemoryRecallScore
+//   - error: An error if embedding or the search fails
+func (a *App) RecallMemories(question string, limit int) ([]string, error) {
+	if err := a.requireStore(); err != nil {
+		return nil, err
+	}
+
+	embedding, err := a.Store.Embed(question)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed question: %w", err)
+	}
+	hits, err := a.Store.SearchMemories(embedding, uint64(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	var memories []string
+	for _, hit := range hits {
+		if hit.Score >= MemoryRecallScore && hit.Answer != "" {
+			memories = append(memories, hit.Answer)
+		}
+	}
+	return memories, nil
+}
+
+// DistillReport describes what a distillation run did.
+type DistillReport struct {
+	Conversations int         // Conversations with new turns that were distilled
+	Turns         int         // Question and answer turns read
+	Stored        []fs.Memory // New memories
+	Duplicates    int         // Memories skipped because they were already known
+}
+
+// DistillMemories asks the model for durable facts and preferences in the
+// turns of recently updated conversations that haven't been distilled yet,
+// and stores them as memories linked to the Q&A pairs they came from.
+// Progress is recorded per conversation, so every turn is distilled once.
+//
+// Parameters:
+//   - ctx: Cancels the run; conversations finished before are kept
+//   - provider: The backend to ask
+//   - req: The model and options to ask with
+//   - since: Conversations last updated before this are skipped
+//
+// Returns:
+//   - DistillReport: What was distilled and stored
+//   - error: The first error; the report covers the work done until then
+func (a *App) DistillMemories(ctx context.Context, provider llm.ChatProvider, req llm.ChatRequest, since time.Time) (DistillReport, error) {
+	var report DistillReport
+	if err := a.requireStore(); err != nil {
+		return report, err
+	}
+
+	convs, err := fs.ListConversations(a.VaultPath)
+	if err != nil {
+		return report, err
+	}
+	mf, err := fs.LoadMemories(a.VaultPath)
+	if err != nil {
+		return report, err
+	}
+
+	for _, c := range convs {
+		if c.Updated.Before(since) {
+			continue
+		}
+		turns, sources, end := completedTurns(c.Messages, mf.Distilled[c.ID])
+		if len(sources) == 0 {
+			continue
+		}
+
+		extracted, err := llm.ExtractMemories(ctx, provider, req, turns)
+		if err != nil {
+			return report, err
+		}
+		report.Conversations++
+		report.Turns += len(sources)
+
+		for _, ex := range extracted {
+			if ex.Text == "" {
+				continue
+			}
+			mem := fs.Memory{Text: ex.Text, Kind: ex.Kind, Conversation: c.ID}
+			for _, t := range ex.Turns {
+				mem.Sources = append(mem.Sources, sources[t-1])
+			}
+			stored, ok, err := a.StoreMemory(mem)
+			if err != nil {
+				return report, err
+			}
+			if !ok {
+				report.Duplicates++
+				continue
+			}
+			report.Stored = append(report.Stored, stored)
+		}
+
+		// StoreMemory rewrote the file, so record progress on a fresh copy
+		err = a.updateMemories(func(mf *fs.MemoryFile) bool {
+			mf.Distilled[c.ID] = end
+			return true
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// completedTurns collects the question and final answer of each turn
+// completed after message from. Tool exchanges and system messages are left out.
+//
+// Returns:
+//   - []fs.Message: Alternating questions and answers
+//   - []string: The Q&A ID of each turn
+//   - int: The index of the first message after the last completed turn
+func completedTurns(messages []fs.Message, from int) ([]fs.Message, []string, int) {
+	var turns []fs.Message
+	var sources []string
+	end := from
+	var question *fs.Message
+	for i := from; i < len(messages); i++ {
+		msg := messages[i]
+		switch {
+		case msg.Role == fs.RoleUser:
+			question = &messages[i]
+		case msg.Role == fs.RoleAssistant && len(msg.ToolCalls) == 0 && question != nil:
+			turns = append(turns, *question, msg)
+			sources = append(sources, fs.QAID(question.Content, msg.Content))
+			question = nil
+			end = i + 1
+		}
+	}
+	return turns, sources, end
+}
@@ -0,0 +1,284 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/vector"
+)
+
+// ReconcileReport describes how the vault file and the Qdrant collection differ.
+type ReconcileReport struct {
+	Matched          int                // Entries present in both stores
+	VaultOnly        []fs.QA            // Vault entries without a Qdrant point
+	QdrantOnly       []vector.QAPoint   // Qdrant points without a vault entry
+	VaultDuplicates  [][]fs.QA          // Vault entries sharing the same ID or content
+	QdrantDuplicates [][]vector.QAPoint // Points holding the same question and answer
+	MissingIDs       int                // Vault entries matched by content that lack an ID
+
+	// links maps the content key of each entry counted in MissingIDs to its point ID
+	links map[string]string
+}
+
+// Clean reports whether both stores hold exactly the same entries.
+func (r *ReconcileReport) Clean() bool {
+	return len(r.VaultOnly) == 0 && len(r.QdrantOnly) == 0 &&
+		len(r.VaultDuplicates) == 0 && len(r.QdrantDuplicates) == 0 &&
+		r.MissingIDs == 0
+}
+
+// RepairOptions selects which differences Repair fixes.
+type RepairOptions struct {
+	ToQdrant bool // Index vault-only entries into Qdrant
+	ToVault  bool // Add Qdrant-only points to the vault file
+	Dedupe   bool // Drop duplicate copies from both stores
+}
+
+// Reconcile compares every point in Qdrant with the vault file.
+// Entries are matched by their stable ID, falling back to the content-derived
+// key for vault entries written before IDs were recorded.
+//
+// Returns:
+//   - *ReconcileReport: The differences found
+//   - error: An error if either store cannot be read
+func (a *App) Reconcile() (*ReconcileReport, error) {
+	if err := a.requireStore(); err != nil {
+		return nil, err
+	}
+
+	qas, err := fs.LoadQAs(a.VaultPath)
+	if err != nil {
+		return nil, err
+	}
+	points, err := a.Store.ScrollQAs(false)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconcileReport{links: make(map[string]string)}
+	byID, byContent := indexPoints(points)
+
+	seen := make(map[string][]fs.QA)
+	var order []string
+	matchedPoints := make(map[string]bool)
+
+	for _, qa := range qas.QAs {
+		key := qa.Key()
+		if _, ok := seen[key]; !ok {
+			order = append(order, key)
+		}
+		seen[key] = append(seen[key], qa)
+		if len(seen[key]) > 1 {
+			continue
+		}
+
+		if p, ok := byID[qa.ID]; ok && qa.ID != "" {
+			matchedPoints[p.ID] = true
+			report.Matched++
+			continue
+		}
+		if qa.ID == "" {
+			if group := byContent[key]; len(group) > 0 {
+				matchedPoints[group[0].ID] = true
+				report.Matched++
+				report.MissingIDs++
+				report.links[key] = group[0].ID
+				continue
+			}
+		}
+		report.VaultOnly = append(report.VaultOnly, qa)
+	}
+
+	for _, key := range order {
+		if len(seen[key]) > 1 {
+			report.VaultDuplicates = append(report.VaultDuplicates, seen[key])
+		}
+	}
+
+	// Group copies of the same content, putting the point the vault refers to first
+	grouped := make(map[string]bool)
+	extraCopies := make(map[string]bool)
+	for _, p := range points {
+		key := fs.QAID(p.Question, p.Answer)
+		group := byContent[key]
+		if len(group) < 2 || grouped[key] {
+			continue
+		}
+		grouped[key] = true
+		sorted := append([]vector.QAPoint(nil), group...)
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return matchedPoints[sorted[i].ID] && !matchedPoints[sorted[j].ID]
+		})
+		report.QdrantDuplicates = append(report.QdrantDuplicates, sorted)
+		for _, dup := range sorted[1:] {
+			extraCopies[dup.ID] = true
+		}
+	}
+
+	for _, p := range points {
+		// A second copy of a point is a duplicate, not an orphan
+		if matchedPoints[p.ID] || extraCopies[p.ID] {
+			continue
+		}
+		report.QdrantOnly = append(report.QdrantOnly, p)
+	}
+
+	return report, nil
+}
+
+// Repair fixes the differences in a report according to opts.
+// It never deletes an entry that exists on only one side; orphans are copied
+// to the other store instead. Duplicate copies are removed when opts.Dedupe is set.
+//
+// Returns:
+//   - int: The number of changes applied
+//   - error: The first error encountered
+func (a *App) Repair(report *ReconcileReport, opts RepairOptions) (int, error) {
+	if err := a.requireStore(); err != nil {
+		return 0, err
+	}
+	changes := 0
+
+	if opts.ToQdrant {
+		for _, qa := range report.VaultOnly {
+			if err := a.indexEntry(qa); err != nil {
+				return changes, err
+			}
+			if qa.ID == "" {
+				report.links[qa.Key()] = qa.Key()
+			}
+			changes++
+		}
+	}
+
+	if opts.Dedupe {
+		for _, group := range report.QdrantDuplicates {
+			ids := make([]string, 0, len(group)-1)
+			for _, p := range group[1:] {
+				ids = append(ids, p.ID)
+			}
+			if err := a.Store.DeletePoints(ids...); err != nil {
+				return changes, err
+			}
+			changes += len(ids)
+		}
+	}
+
+	// Rewrite the vault file once for all vault-side changes
+	err := a.updateVault(func(qas *fs.QAFile) bool {
+		vaultChanged := false
+
+		if opts.Dedupe && len(report.VaultDuplicates) > 0 {
+			seen := make(map[string]bool)
+			changes += qas.Remove(func(qa fs.QA) bool {
+				key := qa.Key()
+				if seen[key] {
+					return true
+				}
+				seen[key] = true
+				return false
+			})
+			vaultChanged = true
+		}
+
+		for i := range qas.QAs {
+			if qas.QAs[i].ID != "" {
+				continue
+			}
+			if id, ok := report.links[qas.QAs[i].Key()]; ok {
+				qas.QAs[i].ID = id
+				vaultChanged = true
+				changes++
+			}
+		}
+
+		if opts.ToVault {
+			for _, p := range report.QdrantOnly {
+				qas.QAs = append(qas.QAs, qaFromPoint(p))
+				changes++
+			}
+			vaultChanged = vaultChanged || len(report.QdrantOnly) > 0
+		}
+		return vaultChanged
+	})
+	return changes, err
+}
+
+// RebuildVault replaces the vault file with the Q&As stored in Qdrant.
+// The previous file is kept next to it with a ".bak" suffix.
+//
+// Returns:
+//   - int: The number of entries written
+//   - error: An error if Qdrant cannot be read or the file cannot be written
+func (a *App) RebuildVault() (int, error) {
+	if err := a.requireStore(); err != nil {
+		return 0, err
+	}
+
+	points, err := a.Store.ScrollQAs(false)
+	if err != nil {
+		return 0, err
+	}
+
+	a.vaultMu.Lock()
+	defer a.vaultMu.Unlock()
+
+	if err := fs.BackupFile(fs.QAFilePath(a.VaultPath)); err != nil {
+		return 0, err
+	}
+
+	qas := &fs.QAFile{}
+	for _, p := range points {
+		qas.QAs = append(qas.QAs, qaFromPoint(p))
+	}
+	sort.SliceStable(qas.QAs, func(i, j int) bool {
+		return qas.QAs[i].Time.Before(qas.QAs[j].Time)
+	})
+
+	if err := fs.SaveQAs(a.VaultPath, qas); err != nil {
+		return 0, err
+	}
+	return len(qas.QAs), nil
+}
+
+// qaFromPoint converts a Qdrant point back into a vault entry.
+func qaFromPoint(p vector.QAPoint) fs.QA {
+	stored, err := time.Parse(time.RFC3339, p.StoredAt)
+	if err != nil {
+		stored = time.Time{}
+	}
+	qa := fs.QA{
+		ID:       p.ID,
+		Question: p.Question,
+		Answer:   p.Answer,
+		Time:     stored,
+		Persona:  p.Payload["persona"],
+		Model:    p.Payload["model"],
+		Endpoint: p.Payload["endpoint"],
+		Template: p.Payload["template"],
+		Thinking: p.Payload["thinking"],
+	}
+	if images := p.Payload["images"]; images != "" {
+		qa.Images = strings.Split(images, ",")
+	}
+	if opts := p.Payload["options"]; opts != "" && json.Valid([]byte(opts)) {
+		qa.Options = json.RawMessage(opts)
+	}
+	if data := p.Payload["metrics"]; data != "" {
+		var m fs.Metrics
+		if json.Unmarshal([]byte(data), &m) == nil {
+			qa.Metrics = &m
+		}
+	}
+	return qa
+}
+
+// Summary renders a one-line summary of the report.
+func (r *ReconcileReport) Summary() string {
+	return fmt.Sprintf("%d matched, %d vault-only, %d qdrant-only, %d vault duplicate groups, %d qdrant duplicate groups, %d entries missing IDs",
+		r.Matched, len(r.VaultOnly), len(r.QdrantOnly), len(r.VaultDuplicates), len(r.QdrantDuplicates), r.MissingIDs)
+}
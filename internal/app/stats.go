@@ -0,0 +1,83 @@
+package app
+
+import (
+	"sort"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// ModelStats aggregates the metrics of every stored answer from one model.
+type ModelStats struct {
+	Model            string
+	Answers          int     // Answers with recorded metrics
+	PromptTokens     int     // Total prompt tokens
+	CompletionTokens int     // Total completion tokens
+	AvgTTFTMillis    float64 // Mean time to first token
+	AvgTotalMillis   float64 // Mean time to a complete answer
+	AvgTokensPerSec  float64 // Mean throughput of answers that report it
+}
+
+// ModelStats aggregates the recorded metrics of the vault's Q&As per model,
+// busiest model first. Entries stored before metrics were recorded are skipped.
+//
+// Returns:
+//   - []ModelStats: One entry per model
+//   - error: An error if the vault cannot be read
+func (a *App) ModelStats() ([]ModelStats, error) {
+	qas, err := fs.LoadQAs(a.VaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	type totals struct {
+		ModelStats
+		ttft, total, rate float64
+		rated             int
+	}
+	byModel := make(map[string]*totals)
+
+	for _, qa := range qas.QAs {
+		if qa.Metrics == nil {
+			continue
+		}
+		name := qa.Model
+		if name == "" {
+			name = "unknown"
+		}
+		t, ok := byModel[name]
+		if !ok {
+			t = &totals{ModelStats: ModelStats{Model: name}}
+			byModel[name] = t
+		}
+
+		m := qa.Metrics
+		t.Answers++
+		t.PromptTokens += m.PromptTokens
+		t.CompletionTokens += m.CompletionTokens
+		t.ttft += float64(m.TTFTMillis)
+		t.total += float64(m.TotalMillis)
+		if m.TokensPerSecond > 0 {
+			t.rate += m.TokensPerSecond
+			t.rated++
+		}
+	}
+
+	stats := make([]ModelStats, 0, len(byModel))
+	for _, t := range byModel {
+		s := t.ModelStats
+		s.AvgTTFTMillis = t.ttft / float64(t.Answers)
+		s.AvgTotalMillis = t.total / float64(t.Answers)
+		if t.rated > 0 {
+			s.AvgTokensPerSec = t.rate / float64(t.rated)
+		}
+		stats = append(stats, s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Answers != stats[j].Answers {
+			return stats[i].Answers > stats[j].Answers
+		}
+		return stats[i].Model < stats[j].Model
+	})
+	return stats, nil
+}
@@ -0,0 +1,200 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/vector"
+)
+
+// StoreQA embeds a question-answer pair, stores it in Qdrant and appends it
+// to the vault file. Both stores use the same stable ID, so storing the same
+// pair twice neither duplicates the point nor the vault entry.
+//
+// Parameters:
+//   - entry: The pair to store; its ID and time are filled in, other metadata is kept
+//
+// Returns:
+//   - fs.QA: The stored vault entry
+//   - error: An error if embedding or either store fails
+func (a *App) StoreQA(entry fs.QA) (fs.QA, error) {
+	if err := a.requireStore(); err != nil {
+		return fs.QA{}, err
+	}
+
+	question, answer := entry.Question, entry.Answer
+
+	// Generate embeddings for the question and answer
+	questionEmbedding, err := a.Store.Embed(question)
+	if err != nil {
+		return fs.QA{}, fmt.Errorf("failed to embed question: %w", err)
+	}
+
+	answerEmbedding, err := a.Store.Embed(answer)
+	if err != nil {
+		return fs.QA{}, fmt.Errorf("failed to embed answer: %w", err)
+	}
+
+	qa := entry
+	qa.ID = fs.QAID(question, answer)
+	qa.Time = time.Now()
+
+	if err := a.Store.StoreQA(qa.ID, question, answer, questionEmbedding, answerEmbedding, entryMetadata(qa)); err != nil {
+		return fs.QA{}, fmt.Errorf("failed to store Q&A in vector database: %w", err)
+	}
+
+	err = a.updateVault(func(qas *fs.QAFile) bool {
+		if i := qas.Index(func(e fs.QA) bool { return e.Key() == qa.ID }); i >= 0 {
+			qa = qas.QAs[i]
+			return false
+		}
+		qas.QAs = append(qas.QAs, qa)
+		return true
+	})
+	if err != nil {
+		return fs.QA{}, err
+	}
+	return qa, nil
+}
+
+// IndexVault makes sure every vault entry has a point in Qdrant.
+// Entries that already have a point are skipped; entries written before IDs
+// were recorded are linked to their existing point where one matches. The
+// vault file itself is only rewritten to record those IDs, never appended to.
+//
+// Parameters:
+//   - progress: Optional callback invoked after each entry with (done, total)
+//
+// Returns:
+//   - int: The number of entries newly indexed
+//   - error: An error if the stores cannot be read or written
+func (a *App) IndexVault(progress func(done, total int)) (int, error) {
+	if err := a.requireStore(); err != nil {
+		return 0, err
+	}
+
+	qas, err := fs.LoadQAs(a.VaultPath)
+	if err != nil {
+		return 0, err
+	}
+
+	points, err := a.Store.ScrollQAs(false)
+	if err != nil {
+		return 0, err
+	}
+	byID, byContent := indexPoints(points)
+
+	indexed := 0
+	links := make(map[string]string) // Content key → ID, for entries without an ID
+	var firstErr error
+
+	for i, qa := range qas.QAs {
+		if progress != nil {
+			progress(i+1, len(qas.QAs))
+		}
+		if qa.Question == "" || qa.Answer == "" {
+			continue
+		}
+
+		if qa.ID == "" {
+			if matches := byContent[qa.Key()]; len(matches) > 0 {
+				links[qa.Key()] = matches[0].ID
+				continue
+			}
+			links[qa.Key()] = qa.Key()
+		}
+		if _, ok := byID[qa.Key()]; ok {
+			continue
+		}
+
+		if err := a.indexEntry(qa); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		indexed++
+	}
+
+	if len(links) == 0 {
+		return indexed, firstErr
+	}
+	// Record the IDs in a fresh copy of the file, which may have changed while embedding
+	err = a.updateVault(func(qas *fs.QAFile) bool {
+		changed := false
+		for i := range qas.QAs {
+			if qas.QAs[i].ID != "" {
+				continue
+			}
+			if id, ok := links[qas.QAs[i].Key()]; ok {
+				qas.QAs[i].ID = id
+				changed = true
+			}
+		}
+		return changed
+	})
+	if err != nil {
+		return indexed, err
+	}
+	return indexed, firstErr
+}
+
+// indexEntry embeds a vault entry's question and stores it under the entry's ID.
+func (a *App) indexEntry(qa fs.QA) error {
+	embedding, err := a.Store.Embed(qa.Question)
+	if err != nil {
+		return fmt.Errorf("failed to embed question: %w", err)
+	}
+	payload := vector.QAPayload(qa.Question, qa.Answer, qa.Time)
+	for k, v := range entryMetadata(qa) {
+		payload[k] = v
+	}
+	return a.Store.StoreVector(qa.Key(), embedding, payload)
+}
+
+// entryMetadata returns the optional payload fields recorded for a vault entry.
+func entryMetadata(qa fs.QA) map[string]string {
+	meta := make(map[string]string)
+	if qa.Persona != "" {
+		meta["persona"] = qa.Persona
+	}
+	if qa.Model != "" {
+		meta["model"] = qa.Model
+	}
+	if qa.Endpoint != "" {
+		meta["endpoint"] = qa.Endpoint
+	}
+	if qa.Template != "" {
+		meta["template"] = qa.Template
+	}
+	if qa.Thinking != "" {
+		meta["thinking"] = qa.Thinking
+	}
+	if len(qa.Images) > 0 {
+		meta["images"] = strings.Join(qa.Images, ",")
+	}
+	if len(qa.Options) > 0 {
+		meta["options"] = string(qa.Options)
+	}
+	if qa.Metrics != nil {
+		if data, err := json.Marshal(qa.Metrics); err == nil {
+			meta["metrics"] = string(data)
+		}
+	}
+	return meta
+}
+
+// indexPoints maps points by ID and by the content-derived key of their Q&A.
+func indexPoints(points []vector.QAPoint) (map[string]vector.QAPoint, map[string][]vector.QAPoint) {
+	byID := make(map[string]vector.QAPoint, len(points))
+	byContent := make(map[string][]vector.QAPoint, len(points))
+	for _, p := range points {
+		byID[p.ID] = p
+		key := fs.QAID(p.Question, p.Answer)
+		byContent[key] = append(byContent[key], p)
+	}
+	return byID, byContent
+}
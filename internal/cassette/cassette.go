@@ -0,0 +1,333 @@
+// Package cassette records the HTTP traffic of the chat and embedding
+// backends to a file and replays it, so flows that talk to Ollama or an
+// OpenAI-compatible server can be run offline and deterministically.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Environment variables that put every backend client behind a cassette
+const (
+	// EnvPath names the cassette file
+	EnvPath = "ASKAI_CASSETTE"
+	// EnvMode is "record" or "replay", the default
+	EnvMode = "ASKAI_CASSETTE_MODE"
+)
+
+// Mode says whether a Transport records or replays
+type Mode string
+
+const (
+	// Record sends requests to the server and saves every exchange
+	Record Mode = "record"
+	// Replay answers requests from the cassette without any network access
+	Replay Mode = "replay"
+)
+
+// Cassette is the recorded traffic, as saved in a cassette file
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one request and the response it got
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request identifies a recorded request. Headers aren't recorded, so API keys
+// never end up in a cassette.
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"` // Path and query; the host is ignored so servers may move
+	Body   string `json:"body,omitempty"`
+}
+
+// Response is a recorded response. Its body is kept as the chunks it was
+// read in, so streamed NDJSON and SSE responses replay chunk by chunk.
+type Response struct {
+	Status      int      `json:"status"`
+	ContentType string   `json:"content_type,omitempty"`
+	Chunks      []string `json:"chunks"`
+	// Truncated is set when the body was closed before it was read to the
+	// end, so Chunks hold only what the client read. Replaying it fails with
+	// io.ErrUnexpectedEOF after the last chunk instead of ending cleanly.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Load reads a cassette file
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path, creating its directory if needed
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Transport is an http.RoundTripper that records exchanges to a cassette
+// file or replays them from it. It is safe for concurrent use.
+type Transport struct {
+	// Next sends requests while recording; nil uses http.DefaultTransport
+	Next http.RoundTripper
+
+	path     string
+	mode     Mode
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool // Interactions already replayed
+}
+
+// New creates a transport for the cassette at path. Recording starts a new
+// cassette, replacing the file once the first exchange completes; replaying
+// loads the file.
+//
+// Parameters:
+//   - path: The cassette file
+//   - mode: Record or Replay
+//
+// Returns:
+//   - *Transport: The transport
+//   - error: An error if the mode is unknown or the cassette cannot be loaded
+func New(path string, mode Mode) (*Transport, error) {
+	t := &Transport{path: path, mode: mode, cassette: &Cassette{}}
+	switch mode {
+	case Record:
+	case Replay:
+		c, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		t.cassette = c
+		t.used = make([]bool, len(c.Interactions))
+	default:
+		return nil, fmt.Errorf("unknown cassette mode %q: use %q or %q", mode, Record, Replay)
+	}
+	return t, nil
+}
+
+// RoundTrip records or replays a request
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	recorded, err := recordRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if t.mode == Replay {
+		return t.replay(req, recorded)
+	}
+	return t.record(req, recorded)
+}
+
+// recordRequest reads the request's body, leaving it in place to be sent
+func recordRequest(req *http.Request) (Request, error) {
+	r := Request{Method: req.Method, URL: req.URL.RequestURI()}
+	if req.Body == nil || req.Body == http.NoBody {
+		return r, nil
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return r, fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	r.Body = string(data)
+	return r, nil
+}
+
+// replay answers a request with the first unused exchange recorded for it,
+// so a request sent twice gets the two responses in the order they were recorded
+func (t *Transport) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, in := range t.cassette.Interactions {
+		if t.used[i] || in.Request != recorded {
+			continue
+		}
+		t.used[i] = true
+		header := make(http.Header)
+		if in.Response.ContentType != "" {
+			header.Set("Content-Type", in.Response.ContentType)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.Status, http.StatusText(in.Response.Status)),
+			StatusCode:    in.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          &chunkReader{chunks: in.Response.Chunks, truncated: in.Response.Truncated},
+			ContentLength: -1,
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("cassette %s has no recorded response for %s %s", filepath.Base(t.path), recorded.Method, recorded.URL)
+}
+
+// record sends a request and records the response as it is read
+func (t *Transport) record(req *http.Request, recorded Request) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &recordingBody{
+		body: resp.Body,
+		t:    t,
+		interaction: Interaction{
+			Request:  recorded,
+			Response: Response{Status: resp.StatusCode, ContentType: resp.Header.Get("Content-Type")},
+		},
+	}
+	return resp, nil
+}
+
+// add appends a completed exchange and saves the cassette
+func (t *Transport) add(in Interaction) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cassette.Interactions = append(t.cassette.Interactions, in)
+	return t.cassette.Save(t.path)
+}
+
+// recordingBody passes a response body on while recording the chunks read
+// from it. The exchange is saved once the body is read to the end, or marked
+// truncated and saved when it is closed before that.
+type recordingBody struct {
+	body        io.ReadCloser
+	t           *Transport
+	interaction Interaction
+	saved       bool
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if n > 0 {
+		b.interaction.Response.Chunks = append(b.interaction.Response.Chunks, string(p[:n]))
+	}
+	if errors.Is(err, io.EOF) {
+		if serr := b.save(); serr != nil {
+			return n, serr
+		}
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	err := b.body.Close()
+	if !b.saved {
+		// The client stopped reading, e.g. after a stream's last event or
+		// on cancellation; whatever followed was never seen
+		b.interaction.Response.Truncated = true
+	}
+	if serr := b.save(); serr != nil {
+		return serr
+	}
+	return err
+}
+
+// save records the exchange once
+func (b *recordingBody) save() error {
+	if b.saved {
+		return nil
+	}
+	b.saved = true
+	return b.t.add(b.interaction)
+}
+
+// chunkReader replays a body one recorded chunk per read
+type chunkReader struct {
+	chunks    []string
+	rest      string // What is left of the current chunk
+	truncated bool   // The recording stopped before the end of the body
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if r.rest == "" {
+		if len(r.chunks) == 0 {
+			if r.truncated {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, io.EOF
+		}
+		r.rest, r.chunks = r.chunks[0], r.chunks[1:]
+	}
+	n := copy(p, r.rest)
+	r.rest = r.rest[n:]
+	return n, nil
+}
+
+func (r *chunkReader) Close() error {
+	return nil
+}
+
+var (
+	envOnce   sync.Once
+	envClient *http.Client
+)
+
+// Client returns the HTTP client the backends use: one recording to or
+// replaying from the cassette named by ASKAI_CASSETTE if it is set, and
+// http.DefaultClient otherwise. All callers share one transport, so chat and
+// embedding traffic end up in the same cassette.
+func Client() *http.Client {
+	envOnce.Do(func() {
+		path := os.Getenv(EnvPath)
+		if path == "" {
+			envClient = http.DefaultClient
+			return
+		}
+		mode := Mode(strings.ToLower(os.Getenv(EnvMode)))
+		if mode == "" {
+			mode = Replay
+		}
+		t, err := New(path, mode)
+		if err != nil {
+			// Fail every request rather than silently going to the network
+			envClient = &http.Client{Transport: failingTransport{err}}
+			return
+		}
+		envClient = &http.Client{Transport: t}
+	})
+	return envClient
+}
+
+// failingTransport fails every request with the error that kept the
+// cassette from being opened
+type failingTransport struct {
+	err error
+}
+
+func (f failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, f.err
+}
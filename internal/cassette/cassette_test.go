@@ -0,0 +1,222 @@
+package cassette
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ndjsonLines is the streamed body the test server sends, one flush per line
+var ndjsonLines = []string{
+	`{"message":{"content":"Hel"},"done":false}`,
+	`{"message":{"content":"lo"},"done":false}`,
+	`{"message":{"content":""},"done":true}`,
+}
+
+// ndjsonServer streams ndjsonLines, flushing each one and then waiting for
+// a value on next before sending the following line, so every line arrives
+// in a read of its own
+func ndjsonServer(t *testing.T, next <-chan struct{}) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		for i, line := range ndjsonLines {
+			if i > 0 {
+				select {
+				case <-next:
+				case <-r.Context().Done():
+					return
+				}
+			}
+			fmt.Fprintln(w, line)
+			w.(http.Flusher).Flush()
+		}
+	}))
+}
+
+// post sends a chat request through the transport
+func post(t *testing.T, tr http.RoundTripper, url, body string) *http.Response {
+	t.Helper()
+	resp, err := (&http.Client{Transport: tr}).Post(url+"/api/chat", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	return resp
+}
+
+// readChunks reads a body one Read at a time, returning what each read got.
+// After each read it lets the server send its next line if next is set.
+func readChunks(t *testing.T, body io.Reader, next chan<- struct{}) ([]string, error) {
+	t.Helper()
+	var chunks []string
+	buf := make([]byte, 4096)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			chunks = append(chunks, string(buf[:n]))
+			if next != nil {
+				select {
+				case next <- struct{}{}:
+				default:
+				}
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return chunks, nil
+			}
+			return chunks, err
+		}
+	}
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chat.json")
+	next := make(chan struct{}, 1)
+	srv := ndjsonServer(t, next)
+
+	rec, err := New(path, Record)
+	if err != nil {
+		t.Fatalf("New(Record): %v", err)
+	}
+	resp := post(t, rec, srv.URL, `{"model":"m"}`)
+	live, err := readChunks(t, resp.Body, next)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("reading the live body: %v", err)
+	}
+	if want := strings.Join(ndjsonLines, "\n") + "\n"; strings.Join(live, "") != want {
+		t.Fatalf("live body = %q, want %q", strings.Join(live, ""), want)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(c.Interactions) != 1 {
+		t.Fatalf("recorded %d interactions, want 1", len(c.Interactions))
+	}
+	in := c.Interactions[0]
+	if in.Request.Method != http.MethodPost || in.Request.URL != "/api/chat" || in.Request.Body != `{"model":"m"}` {
+		t.Errorf("Request = %+v, want POST /api/chat with its body", in.Request)
+	}
+	if in.Response.Status != http.StatusOK || in.Response.ContentType != "application/x-ndjson" || in.Response.Truncated {
+		t.Errorf("Response = %+v, want a complete 200 NDJSON response", in.Response)
+	}
+	if len(in.Response.Chunks) != len(ndjsonLines) {
+		t.Errorf("recorded %d chunks, want one per streamed line (%d)", len(in.Response.Chunks), len(ndjsonLines))
+	}
+
+	// Nothing is listening any more; the replay must not need the network
+	srv.Close()
+
+	rep, err := New(path, Replay)
+	if err != nil {
+		t.Fatalf("New(Replay): %v", err)
+	}
+	resp = post(t, rep, srv.URL, `{"model":"m"}`)
+	replayed, err := readChunks(t, resp.Body, nil)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("reading the replayed body: %v", err)
+	}
+	if strings.Join(replayed, "") != strings.Join(live, "") {
+		t.Errorf("replayed body = %q, want %q", strings.Join(replayed, ""), strings.Join(live, ""))
+	}
+	if len(replayed) != len(in.Response.Chunks) {
+		t.Errorf("replayed in %d reads, want one per recorded chunk (%d)", len(replayed), len(in.Response.Chunks))
+	}
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Errorf("replayed %d %q, want 200 application/x-ndjson", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	// Each recorded exchange answers one request
+	if _, err := (&http.Client{Transport: rep}).Post(srv.URL+"/api/chat", "application/json", strings.NewReader(`{"model":"m"}`)); err == nil {
+		t.Error("a second identical request was replayed from a single recording")
+	}
+}
+
+func TestReplayMatchesRequestBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chat.json")
+	c := &Cassette{Interactions: []Interaction{{
+		Request:  Request{Method: http.MethodPost, URL: "/api/chat", Body: `{"model":"a"}`},
+		Response: Response{Status: http.StatusOK, Chunks: []string{"a"}},
+	}}}
+	if err := c.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	rep, err := New(path, Replay)
+	if err != nil {
+		t.Fatalf("New(Replay): %v", err)
+	}
+
+	_, err = (&http.Client{Transport: rep}).Post("http://localhost:1/api/chat", "application/json", strings.NewReader(`{"model":"b"}`))
+	if err == nil || !strings.Contains(err.Error(), "no recorded response") {
+		t.Errorf("err = %v, want no recorded response", err)
+	}
+}
+
+func TestRecordTruncatedBody(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chat.json")
+	// The server never gets to send its second line
+	srv := ndjsonServer(t, nil)
+	defer srv.Close()
+
+	rec, err := New(path, Record)
+	if err != nil {
+		t.Fatalf("New(Record): %v", err)
+	}
+	resp := post(t, rec, srv.URL, `{"model":"m"}`)
+	buf := make([]byte, 4096)
+	n, err := resp.Body.Read(buf)
+	if err != nil && !errors.Is(err, io.EOF) {
+		t.Fatalf("Read: %v", err)
+	}
+	first := string(buf[:n])
+	// Stop before the end, as a cancelled stream would
+	resp.Body.Close()
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(c.Interactions) != 1 {
+		t.Fatalf("recorded %d interactions, want 1", len(c.Interactions))
+	}
+	got := c.Interactions[0].Response
+	if !got.Truncated {
+		t.Error("a body closed before EOF was not marked truncated")
+	}
+	if strings.Join(got.Chunks, "") != first {
+		t.Errorf("recorded %q, want only what was read: %q", strings.Join(got.Chunks, ""), first)
+	}
+
+	rep, err := New(path, Replay)
+	if err != nil {
+		t.Fatalf("New(Replay): %v", err)
+	}
+	resp = post(t, rep, srv.URL, `{"model":"m"}`)
+	defer resp.Body.Close()
+	replayed, err := readChunks(t, resp.Body, nil)
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("replaying a truncated body ended with %v, want io.ErrUnexpectedEOF", err)
+	}
+	if strings.Join(replayed, "") != first {
+		t.Errorf("replayed %q, want %q", strings.Join(replayed, ""), first)
+	}
+}
+
+func TestNewUnknownMode(t *testing.T) {
+	if _, err := New(filepath.Join(t.TempDir(), "c.json"), "rewind"); err == nil {
+		t.Error("New accepted an unknown mode")
+	}
+}
@@ -0,0 +1,61 @@
+package cassette_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/VarunSharma3520/AskAI/internal/cassette"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
+	"github.com/VarunSharma3520/AskAI/internal/vector"
+)
+
+// TestOllamaFlowOffline embeds a question and streams its answer from a
+// recorded Ollama session. The URL points nowhere, so any request the
+// cassette can't answer fails.
+func TestOllamaFlowOffline(t *testing.T) {
+	tr, err := cassette.New("testdata/ollama_flow.json", cassette.Replay)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	client := &http.Client{Transport: tr}
+	const url = "http://127.0.0.1:1"
+	const question = "How does Go do concurrency?"
+
+	embedding, err := vector.NewOllamaEmbedder(url, "mxbai-embed-large", client).Embed(question)
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if want := []float32{0.25, -0.5, 0.125, 1}; len(embedding) != len(want) || embedding[0] != want[0] || embedding[3] != want[3] {
+		t.Errorf("embedding = %v, want %v", embedding, want)
+	}
+
+	req := llm.ChatRequest{Model: "llama3.1", Messages: []fs.Message{{Role: fs.RoleUser, Content: question}}}
+	var tokens []string
+	var resp *llm.ChatResponse
+	for ev := range llm.StreamEvents(context.Background(), llm.NewOllamaProvider(url, client), req) {
+		switch ev.Kind {
+		case llm.EventToken:
+			tokens = append(tokens, ev.Text)
+		case llm.EventDone:
+			resp = ev.Response
+		case llm.EventError:
+			t.Fatalf("stream failed: %v", ev.Err)
+		}
+	}
+
+	if resp == nil {
+		t.Fatal("stream ended without a done event")
+	}
+	if len(tokens) != 3 || strings.Join(tokens, "") != "Go uses goroutines." {
+		t.Errorf("tokens = %q, want the three recorded tokens", tokens)
+	}
+	if resp.Content != "Go uses goroutines." || resp.Model != "llama3.1" {
+		t.Errorf("response = %q from %q, want the recorded answer from llama3.1", resp.Content, resp.Model)
+	}
+	if resp.Metrics.PromptTokens != 18 || resp.Metrics.CompletionTokens != 3 || resp.Metrics.TokensPerSecond != 2 {
+		t.Errorf("Metrics = %+v, want 18 prompt, 3 completion, 2 tokens/s", resp.Metrics)
+	}
+}
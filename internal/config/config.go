@@ -5,8 +5,10 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // ScreenMode represents the different UI modes of the application
@@ -102,62 +104,175 @@ func Temperature() float64 {
 
 // Config represents the application's configuration that can be saved and loaded
 type Config struct {
-	ModelName   string  `json:"model_name"`
-	Temperature float64 `json:"temperature"`
-	APIURL      string  `json:"api_url,omitempty"`
+	ModelName     string             `json:"model_name"`
+	Temperature   float64            `json:"temperature"`
+	APIURL        string             `json:"api_url,omitempty"`
+	KnowledgeBase string             `json:"knowledge_base,omitempty"` // Knowledge base name, or "auto" to follow the git repository
+	Persona       string             `json:"persona,omitempty"`        // Name of the default persona
+	Profile       string             `json:"profile,omitempty"`        // Name of the default chat backend profile
+	Profiles      map[string]Profile `json:"profiles,omitempty"`       // Chat backend profiles by name
+	Options       GenerationOptions  `json:"options"`                  // Generation options sent with every request
+	CompareModels []string           `json:"compare_models,omitempty"` // Models compare mode sends a question to
+	KeepThinking  bool               `json:"keep_thinking,omitempty"`  // Store the model's reasoning with each Q&A
+	Memory        bool               `json:"memory,omitempty"`         // Distill long-term memories and recall them into prompts
+	TitleModel    string             `json:"title_model,omitempty"`    // Small model that titles finished conversations; the chat model if empty
+	Routing       *Routing           `json:"routing,omitempty"`        // Picks a model or persona for each question, or nil to always use the current one
 }
 
-// SaveConfig saves the current configuration to a file in the vault directory
-func SaveConfig(modelName string, temperature float64, apiURL string) error {
-	config := Config{
-		ModelName:   modelName,
-		Temperature: temperature,
-		APIURL:      apiURL,
+// configPath returns the path of the config file inside the vault
+func configPath() string {
+	return filepath.Join(VaultPath(), "config.json")
+}
+
+// Load reads the config file, returning the defaults if it doesn't exist.
+// Fields missing from the file keep their default values.
+func Load() (Config, error) {
+	cfg := Config{
+		ModelName:   defaultModel,
+		Temperature: defaultTemp,
+		APIURL:      defaultAPIURL,
+	}
+
+	data, err := os.ReadFile(configPath())
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return Config{}, err
 	}
 
-	// Create the config file path
-	configPath := filepath.Join(VaultPath(), "config.json")
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, err
+	}
+
+	// Handle missing API URL in config file
+	if cfg.APIURL == "" {
+		cfg.APIURL = defaultAPIURL
+	}
+
+	if err := cfg.Options.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid options in %s: %w", configPath(), err)
+	}
+	for name, p := range cfg.Profiles {
+		if err := p.Validate(); err != nil {
+			return Config{}, fmt.Errorf("invalid profile %q in %s: %w", name, configPath(), err)
+		}
+	}
+	if cfg.Routing != nil {
+		if err := cfg.Routing.Validate(); err != nil {
+			return Config{}, fmt.Errorf("invalid routing in %s: %w", configPath(), err)
+		}
+	}
+	return cfg, nil
+}
+
+// Save writes the configuration to the config file in the vault directory
+func Save(cfg Config) error {
+	path := configPath()
 
 	// Create the vault directory if it doesn't exist
-	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		return err
 	}
 
 	// Marshal the config to JSON
-	data, err := json.MarshalIndent(config, "", "  ")
+	data, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
 		return err
 	}
 
 	// Write the config file
-	return os.WriteFile(configPath, data, 0600)
+	return os.WriteFile(path, data, 0600)
 }
 
-// LoadConfig loads the configuration from the config file if it exists
-func LoadConfig() (string, float64, string, error) {
-	configPath := filepath.Join(VaultPath(), "config.json")
+// SaveConfig saves the model, temperature and API URL to the config file,
+// preserving any other settings already stored there
+func SaveConfig(modelName string, temperature float64, apiURL string) error {
+	cfg, err := Load()
+	if err != nil {
+		return err
+	}
+
+	cfg.ModelName = modelName
+	cfg.Temperature = temperature
+	cfg.APIURL = apiURL
 
-	// If config file doesn't exist, return defaults
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return defaultModel, defaultTemp, defaultAPIURL, nil
+	return Save(cfg)
+}
+
+// SaveSettings saves the settings chosen on the options screen, including the
+// default persona ("" for none) and generation options, preserving any other settings
+func SaveSettings(modelName string, temperature float64, apiURL, persona string, opts GenerationOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
 	}
 
-	// Read the config file
-	data, err := os.ReadFile(configPath)
+	cfg, err := Load()
 	if err != nil {
-		return "", 0, "", err
+		return err
 	}
 
-	// Unmarshal the config
-	var config Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return "", 0, "", err
+	cfg.ModelName = modelName
+	cfg.Temperature = temperature
+	cfg.APIURL = apiURL
+	cfg.Persona = persona
+	cfg.Options = opts
+
+	return Save(cfg)
+}
+
+// SaveCompareModels saves the models compare mode uses, preserving any other settings
+func SaveCompareModels(models []string) error {
+	cfg, err := Load()
+	if err != nil {
+		return err
 	}
+	cfg.CompareModels = models
+	return Save(cfg)
+}
 
-	// Handle missing API URL in config file
-	if config.APIURL == "" {
-		config.APIURL = defaultAPIURL
+// SaveKeepThinking saves whether the model's reasoning is stored with each
+// Q&A, preserving any other settings
+func SaveKeepThinking(keep bool) error {
+	cfg, err := Load()
+	if err != nil {
+		return err
+	}
+	cfg.KeepThinking = keep
+	return Save(cfg)
+}
+
+// SaveMemory saves whether long-term memory is on, preserving any other settings
+func SaveMemory(on bool) error {
+	cfg, err := Load()
+	if err != nil {
+		return err
 	}
+	cfg.Memory = on
+	return Save(cfg)
+}
 
-	return config.ModelName, config.Temperature, config.APIURL, nil
+// ParseModelList splits a comma-separated list of model names, dropping
+// empty entries and duplicates
+func ParseModelList(s string) []string {
+	var models []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		models = append(models, name)
+	}
+	return models
+}
+
+// LoadConfig loads the configuration from the config file if it exists
+func LoadConfig() (string, float64, string, error) {
+	cfg, err := Load()
+	if err != nil {
+		return "", 0, "", err
+	}
+	return cfg.ModelName, cfg.Temperature, cfg.APIURL, nil
 }
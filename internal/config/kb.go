@@ -0,0 +1,108 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// Knowledge base defaults
+const (
+	// DefaultKnowledgeBase is the knowledge base used when none is selected.
+	// It maps to the original collection and the vault root for compatibility.
+	DefaultKnowledgeBase = "default"
+	// AutoKnowledgeBase selects the knowledge base named after the current git repository
+	AutoKnowledgeBase = "auto"
+	// defaultCollection is the Qdrant collection of the default knowledge base
+	defaultCollection = "askai_questions"
+	// collectionPrefix prefixes the Qdrant collection of every named knowledge base
+	collectionPrefix = "askai_kb_"
+	// kbDir is the vault subfolder holding the named knowledge bases
+	kbDir = "kb"
+)
+
+// KnowledgeBase resolves which knowledge base to use.
+// The explicit name (e.g. from --kb) wins, then the ASKAI_KB environment
+// variable, then the knowledge_base setting in the config file. The value
+// "auto" names the knowledge base after the enclosing git repository.
+//
+// Parameters:
+//   - explicit: A name given on the command line, or ""
+//
+// Returns:
+//   - string: A sanitized knowledge base name
+func KnowledgeBase(explicit string) string {
+	name := explicit
+	if name == "" {
+		name = os.Getenv("ASKAI_KB")
+	}
+	if name == "" {
+		if cfg, err := Load(); err == nil {
+			name = cfg.KnowledgeBase
+		}
+	}
+
+	if name == AutoKnowledgeBase {
+		name = ""
+		if wd, err := os.Getwd(); err == nil {
+			if root := fs.GitRepoRoot(wd); root != "" {
+				name = filepath.Base(root)
+			}
+		}
+	}
+
+	name = SanitizeKnowledgeBase(name)
+	if name == "" {
+		return DefaultKnowledgeBase
+	}
+	return name
+}
+
+// SanitizeKnowledgeBase lowercases a name and replaces every character that is
+// not safe in a collection or folder name with an underscore.
+func SanitizeKnowledgeBase(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// CollectionName returns the Qdrant collection backing a knowledge base
+func CollectionName(kb string) string {
+	if kb == "" || kb == DefaultKnowledgeBase {
+		return defaultCollection
+	}
+	return collectionPrefix + kb
+}
+
+// KnowledgeBaseFromCollection returns the knowledge base backed by a collection,
+// or "" if the collection does not belong to AskAI
+func KnowledgeBaseFromCollection(collection string) string {
+	if collection == defaultCollection {
+		return DefaultKnowledgeBase
+	}
+	if strings.HasPrefix(collection, collectionPrefix) {
+		return strings.TrimPrefix(collection, collectionPrefix)
+	}
+	return ""
+}
+
+// KnowledgeBasePath returns the vault folder holding a knowledge base's files
+func KnowledgeBasePath(kb string) string {
+	if kb == "" || kb == DefaultKnowledgeBase {
+		return VaultPath()
+	}
+	return filepath.Join(KnowledgeBasesDir(), kb)
+}
+
+// KnowledgeBasesDir returns the vault folder that contains the named knowledge bases
+func KnowledgeBasesDir() string {
+	return filepath.Join(VaultPath(), kbDir)
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Limits used to validate generation options
+const (
+	maxStopSequences = 4
+	maxNumCtx        = 1 << 20
+)
+
+// GenerationOptions are the sampling and length settings sent with each chat
+// request, besides the temperature. Zero values leave the server's default in place.
+type GenerationOptions struct {
+	MaxTokens int      `json:"max_tokens,omitempty"` // Maximum tokens to generate (num_predict), 0 for unlimited
+	TopP      float64  `json:"top_p,omitempty"`      // Nucleus sampling probability mass, between 0 and 1
+	TopK      int      `json:"top_k,omitempty"`      // Sample from the k most likely tokens
+	NumCtx    int      `json:"num_ctx,omitempty"`    // Context window size in tokens (Ollama only)
+	Seed      int      `json:"seed,omitempty"`       // Sampling seed for reproducible answers, 0 for random
+	Stop      []string `json:"stop,omitempty"`       // Sequences that end the answer
+}
+
+// Validate checks that every option is within its allowed range
+func (o GenerationOptions) Validate() error {
+	switch {
+	case o.MaxTokens < 0:
+		return fmt.Errorf("max_tokens must be 0 (unlimited) or positive, got %d", o.MaxTokens)
+	case o.TopP < 0 || o.TopP > 1:
+		return fmt.Errorf("top_p must be between 0 and 1, got %g", o.TopP)
+	case o.TopK < 0:
+		return fmt.Errorf("top_k must not be negative, got %d", o.TopK)
+	case o.NumCtx < 0 || o.NumCtx > maxNumCtx:
+		return fmt.Errorf("num_ctx must be between 0 and %d, got %d", maxNumCtx, o.NumCtx)
+	case o.Seed < 0:
+		return fmt.Errorf("seed must be 0 (random) or positive, got %d", o.Seed)
+	case len(o.Stop) > maxStopSequences:
+		return fmt.Errorf("at most %d stop sequences are allowed, got %d", maxStopSequences, len(o.Stop))
+	}
+	for _, s := range o.Stop {
+		if s == "" {
+			return fmt.Errorf("stop sequences must not be empty")
+		}
+	}
+	return nil
+}
+
+// Merge returns o with every non-zero option of over applied on top
+func (o GenerationOptions) Merge(over GenerationOptions) GenerationOptions {
+	if over.MaxTokens != 0 {
+		o.MaxTokens = over.MaxTokens
+	}
+	if over.TopP != 0 {
+		o.TopP = over.TopP
+	}
+	if over.TopK != 0 {
+		o.TopK = over.TopK
+	}
+	if over.NumCtx != 0 {
+		o.NumCtx = over.NumCtx
+	}
+	if over.Seed != 0 {
+		o.Seed = over.Seed
+	}
+	if len(over.Stop) > 0 {
+		o.Stop = over.Stop
+	}
+	return o
+}
+
+// ParseStop splits a comma-separated list of stop sequences. The escapes \n
+// and \t stand for a newline and a tab.
+func ParseStop(s string) []string {
+	var stop []string
+	for _, part := range strings.Split(s, ",") {
+		part = strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(strings.TrimSpace(part))
+		if part != "" {
+			stop = append(stop, part)
+		}
+	}
+	return stop
+}
+
+// FormatStop renders stop sequences in the form accepted by ParseStop
+func FormatStop(stop []string) string {
+	escaped := make([]string, len(stop))
+	for i, s := range stop {
+		escaped[i] = strings.NewReplacer("\n", `\n`, "\t", `\t`).Replace(s)
+	}
+	return strings.Join(escaped, ",")
+}
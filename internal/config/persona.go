@@ -0,0 +1,161 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// personasFileName is the file in the vault root holding the persona definitions
+const personasFileName = "personas.json"
+
+// Persona is a named system prompt with optional model and temperature overrides.
+type Persona struct {
+	Name         string   `json:"name"`
+	SystemPrompt string   `json:"system_prompt"`
+	Model        string   `json:"model,omitempty"`       // Model to switch to, or "" to keep the current one
+	Temperature  *float64 `json:"temperature,omitempty"` // Temperature to switch to, or nil to keep the current one
+	// Options override the configured generation options where set
+	Options *GenerationOptions `json:"options,omitempty"`
+}
+
+// personasFile is the on-disk form of the persona list
+type personasFile struct {
+	Personas []Persona `json:"personas"`
+}
+
+// builtinPersonas are written to the vault the first time personas are needed
+func builtinPersonas() []Persona {
+	low := 0.2
+	return []Persona{
+		{
+			Name:         "Go reviewer",
+			SystemPrompt: "You are a senior Go engineer reviewing code. Point out bugs, race conditions, unidiomatic code and missing error handling. Be specific and suggest concrete fixes.",
+			Temperature:  &low,
+		},
+		{
+			Name:         "shell expert",
+			SystemPrompt: "You are an expert in POSIX shell, bash and common Unix tools. Answer with working commands first, then a short explanation. Warn about commands that are destructive.",
+			Temperature:  &low,
+		},
+		{
+			Name:         "terse",
+			SystemPrompt: "Answer as briefly as possible. No preamble, no summary, no pleasantries.",
+			Options:      &GenerationOptions{MaxTokens: 256},
+		},
+	}
+}
+
+// personasPath returns the path of the persona file inside the vault
+func personasPath() string {
+	return filepath.Join(VaultPath(), personasFileName)
+}
+
+// LoadPersonas reads the persona definitions from the vault.
+// The built-in personas are returned if the file doesn't exist yet.
+func LoadPersonas() ([]Persona, error) {
+	data, err := os.ReadFile(personasPath())
+	if os.IsNotExist(err) {
+		return builtinPersonas(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read personas: %w", err)
+	}
+
+	var f personasFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse personas: %w", err)
+	}
+	for _, p := range f.Personas {
+		if p.Options == nil {
+			continue
+		}
+		if err := p.Options.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid options for persona %q: %w", p.Name, err)
+		}
+	}
+	return f.Personas, nil
+}
+
+// SavePersonas writes the persona definitions to the vault
+func SavePersonas(personas []Persona) error {
+	path := personasPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create vault directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(personasFile{Personas: personas}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal personas: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write personas: %w", err)
+	}
+	return nil
+}
+
+// EnsurePersonas writes the built-in personas to the vault if no persona file
+// exists, so they can be edited by hand
+func EnsurePersonas() error {
+	if _, err := os.Stat(personasPath()); !os.IsNotExist(err) {
+		return err
+	}
+	return SavePersonas(builtinPersonas())
+}
+
+// FindPersona looks up a persona by name, ignoring case
+func FindPersona(personas []Persona, name string) (*Persona, bool) {
+	for i := range personas {
+		if strings.EqualFold(personas[i].Name, strings.TrimSpace(name)) {
+			return &personas[i], true
+		}
+	}
+	return nil, false
+}
+
+// PersonaModels returns the models the personas switch to
+func PersonaModels(personas []Persona) []string {
+	var models []string
+	for _, p := range personas {
+		if p.Model != "" {
+			models = append(models, p.Model)
+		}
+	}
+	return models
+}
+
+// ActivePersona resolves which persona to use.
+// The explicit name (e.g. from --persona) wins, then the persona setting in the
+// config file. It returns nil if no persona is selected.
+//
+// Parameters:
+//   - explicit: A name given on the command line, or ""
+//
+// Returns:
+//   - *Persona: The selected persona, or nil for none
+//   - error: An error if the personas cannot be read or the name is unknown
+func ActivePersona(explicit string) (*Persona, error) {
+	name := explicit
+	if name == "" {
+		cfg, err := Load()
+		if err != nil {
+			return nil, err
+		}
+		name = cfg.Persona
+	}
+	if name == "" {
+		return nil, nil
+	}
+
+	personas, err := LoadPersonas()
+	if err != nil {
+		return nil, err
+	}
+	p, ok := FindPersona(personas, name)
+	if !ok {
+		return nil, fmt.Errorf("unknown persona %q", name)
+	}
+	return p, nil
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Chat provider kinds
+const (
+	// ProviderOllama uses Ollama's native chat API
+	ProviderOllama = "ollama"
+	// ProviderOpenAI uses an OpenAI-compatible /v1/chat/completions API
+	ProviderOpenAI = "openai"
+	// DefaultProfile is the built-in profile pointing at the local Ollama server
+	DefaultProfile = "default"
+)
+
+// Profile describes a chat backend: which kind of API it speaks, where it is
+// and how to authenticate
+type Profile struct {
+	Provider  string `json:"provider"`              // "ollama" or "openai"
+	APIURL    string `json:"api_url"`               // Base URL of the server
+	APIKey    string `json:"api_key,omitempty"`     // API key, if the server needs one
+	APIKeyEnv string `json:"api_key_env,omitempty"` // Environment variable holding the API key
+	Model     string `json:"model,omitempty"`       // Model to use, or "" to keep the configured one
+	Timeout   int    `json:"timeout,omitempty"`     // Seconds a request may wait for its first token, or 0 for no limit
+	Retries   int    `json:"retries,omitempty"`     // Extra attempts when a request fails before its first token
+	// Fallbacks name the profiles tried in order when this one keeps failing
+	Fallbacks []string `json:"fallbacks,omitempty"`
+}
+
+// Key returns the profile's API key, reading it from the environment if configured that way
+func (p Profile) Key() string {
+	if p.APIKey != "" {
+		return p.APIKey
+	}
+	if p.APIKeyEnv != "" {
+		return os.Getenv(p.APIKeyEnv)
+	}
+	return ""
+}
+
+// Validate checks that the timeout and retry settings are usable
+func (p Profile) Validate() error {
+	if p.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative, got %d", p.Timeout)
+	}
+	if p.Retries < 0 {
+		return fmt.Errorf("retries must not be negative, got %d", p.Retries)
+	}
+	return nil
+}
+
+// defaultProfile is the profile used when none is selected
+func defaultProfile() Profile {
+	return Profile{Provider: ProviderOllama, APIURL: APIURL()}
+}
+
+// Profiles returns the profiles defined in the config file, plus the default
+// profile unless the file overrides it. Profiles without an API URL get the
+// default one, whether they are selected or used as a fallback.
+func Profiles() (map[string]Profile, error) {
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := map[string]Profile{DefaultProfile: defaultProfile()}
+	for name, p := range cfg.Profiles {
+		if p.APIURL == "" {
+			p.APIURL = defaultProfile().APIURL
+		}
+		profiles[name] = p
+	}
+	return profiles, nil
+}
+
+// ProfileNames returns the names of all profiles in sorted order
+func ProfileNames(profiles map[string]Profile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActiveProfile resolves which chat backend profile to use.
+// The explicit name (e.g. from --profile) wins, then the ASKAI_PROFILE
+// environment variable, then the profile setting in the config file.
+//
+// Parameters:
+//   - explicit: A name given on the command line, or ""
+//
+// Returns:
+//   - string: The name of the selected profile
+//   - Profile: The selected profile
+//   - error: An error if the config cannot be read or the name is unknown
+func ActiveProfile(explicit string) (string, Profile, error) {
+	cfg, err := Load()
+	if err != nil {
+		return "", Profile{}, err
+	}
+
+	name := explicit
+	if name == "" {
+		name = os.Getenv("ASKAI_PROFILE")
+	}
+	if name == "" {
+		name = cfg.Profile
+	}
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	profiles, err := Profiles()
+	if err != nil {
+		return "", Profile{}, err
+	}
+	p, ok := profiles[name]
+	if !ok {
+		return "", Profile{}, fmt.Errorf("unknown profile %q", name)
+	}
+	return name, p, nil
+}
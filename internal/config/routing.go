@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Route sends the questions it matches to a model and/or persona
+type Route struct {
+	Name string `json:"name"`
+	// Keywords select the route when one of them appears in the question as
+	// a whole word or phrase, ignoring case
+	Keywords []string `json:"keywords,omitempty"`
+	// Pattern is a regular expression that selects the route when it matches the question
+	Pattern string `json:"pattern,omitempty"`
+	// Description tells the classifier which questions the route is for
+	Description string `json:"description,omitempty"`
+	Model       string `json:"model,omitempty"`   // Model to answer with, or "" to keep the current one
+	Persona     string `json:"persona,omitempty"` // Persona to answer as, or "" for none
+}
+
+// Routing is the routing table. Routes' rules are tried in order; if none
+// matches, the classifier model, if set, picks a route by its description.
+type Routing struct {
+	Routes []Route `json:"routes"`
+	// Classifier is a small model that picks a route when no rule matches,
+	// or "" to route by rules only
+	Classifier string `json:"classifier,omitempty"`
+	// Default names the route for questions nothing else matched, or "" to
+	// keep the current model
+	Default string `json:"default,omitempty"`
+}
+
+// Validate checks that routes have unique names, their patterns compile and
+// the default route exists. "none" is not a valid name, as the classifier
+// answers it when no route fits.
+func (r Routing) Validate() error {
+	names := make(map[string]bool, len(r.Routes))
+	for _, route := range r.Routes {
+		name := strings.ToLower(strings.TrimSpace(route.Name))
+		if name == "" {
+			return fmt.Errorf("every route needs a name")
+		}
+		if name == "none" {
+			return fmt.Errorf("route name %q is reserved for questions no route fits", route.Name)
+		}
+		if names[name] {
+			return fmt.Errorf("route %q is defined twice", route.Name)
+		}
+		names[name] = true
+		if route.Pattern != "" {
+			if _, err := regexp.Compile(route.Pattern); err != nil {
+				return fmt.Errorf("invalid pattern for route %q: %w", route.Name, err)
+			}
+		}
+		if route.Model == "" && route.Persona == "" {
+			return fmt.Errorf("route %q sets neither a model nor a persona", route.Name)
+		}
+	}
+	if r.Default != "" {
+		if _, ok := r.Find(r.Default); !ok {
+			return fmt.Errorf("default route %q is not defined", r.Default)
+		}
+	}
+	return nil
+}
+
+// Find returns the route with the given name, ignoring case
+func (r Routing) Find(name string) (*Route, bool) {
+	for i := range r.Routes {
+		if strings.EqualFold(r.Routes[i].Name, strings.TrimSpace(name)) {
+			return &r.Routes[i], true
+		}
+	}
+	return nil, false
+}
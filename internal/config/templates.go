@@ -0,0 +1,224 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// TemplatesDir is the folder in the vault root holding one prompt template per file
+const TemplatesDir = "templates"
+
+// templateExt is the extension of prompt template files
+const templateExt = ".tmpl"
+
+// PromptTemplate is reusable prompt text with text/template placeholders such
+// as {{.Error}}. A comment at the start, {{/* like this */}}, describes it.
+type PromptTemplate struct {
+	Name        string // The file name without extension
+	Description string // The leading comment, if any
+	Text        string // The template source
+
+	tmpl *template.Template
+	vars []string
+}
+
+// builtinTemplates are written to the vault the first time templates are needed
+var builtinTemplates = map[string]string{
+	"explain-error": `{{/* Explain an error message and how to fix it */}}Explain this error and how to fix it:
+
+{{.Error}}
+`,
+	"table-tests": `{{/* Write table-driven Go tests for some code */}}Write table-driven Go tests for the following code. Cover edge cases and error paths.
+
+{{.Code}}
+`,
+	"review": `{{/* Review code with a focus of your choice */}}Review this code, focusing on {{.Focus}}:
+
+{{.Code}}
+`,
+}
+
+// TemplatesPath returns the folder holding the prompt templates
+func TemplatesPath() string {
+	return filepath.Join(VaultPath(), TemplatesDir)
+}
+
+// ParsePromptTemplate parses template source
+//
+// Parameters:
+//   - name: The template's name
+//   - text: The template source
+//
+// Returns:
+//   - *PromptTemplate: The parsed template
+//   - error: An error if the source is not a valid template
+func ParsePromptTemplate(name, text string) (*PromptTemplate, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %q: %w", name, err)
+	}
+
+	t := &PromptTemplate{Name: name, Text: text, tmpl: tmpl}
+	if s := strings.TrimSpace(text); strings.HasPrefix(s, "{{/*") {
+		if end := strings.Index(s, "*/}}"); end >= 0 {
+			t.Description = strings.TrimSpace(s[len("{{/*"):end])
+		}
+	}
+	seen := map[string]bool{}
+	if tmpl.Tree != nil {
+		collectVars(tmpl.Tree.Root, seen, &t.vars)
+	}
+	return t, nil
+}
+
+// collectVars appends the top-level fields a template uses, such as Error in
+// {{.Error}}, in the order they first appear
+func collectVars(node parse.Node, seen map[string]bool, vars *[]string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectVars(child, seen, vars)
+		}
+	case *parse.ActionNode:
+		collectVars(n.Pipe, seen, vars)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectVars(cmd, seen, vars)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectVars(arg, seen, vars)
+		}
+	case *parse.IfNode:
+		collectVars(n.Pipe, seen, vars)
+		collectVars(n.List, seen, vars)
+		collectVars(n.ElseList, seen, vars)
+	case *parse.RangeNode:
+		// Fields inside the body refer to the element, not to a placeholder
+		collectVars(n.Pipe, seen, vars)
+		collectVars(n.ElseList, seen, vars)
+	case *parse.WithNode:
+		collectVars(n.Pipe, seen, vars)
+		collectVars(n.ElseList, seen, vars)
+	case *parse.TemplateNode:
+		collectVars(n.Pipe, seen, vars)
+	case *parse.FieldNode:
+		if name := n.Ident[0]; !seen[name] {
+			seen[name] = true
+			*vars = append(*vars, name)
+		}
+	}
+}
+
+// Variables returns the names of the template's placeholders
+func (t *PromptTemplate) Variables() []string {
+	return t.vars
+}
+
+// Render fills in the template's placeholders
+//
+// Parameters:
+//   - values: The value of each placeholder
+//
+// Returns:
+//   - string: The prompt
+//   - error: An error if a placeholder has no value or the template fails
+func (t *PromptTemplate) Render(values map[string]string) (string, error) {
+	for _, name := range t.vars {
+		if _, ok := values[name]; !ok {
+			return "", fmt.Errorf("template %q needs a value for %s", t.Name, name)
+		}
+	}
+	var sb strings.Builder
+	if err := t.tmpl.Execute(&sb, values); err != nil {
+		return "", fmt.Errorf("failed to render template %q: %w", t.Name, err)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// LoadTemplates reads the prompt templates from the vault, sorted by name.
+// The built-in templates are returned if the folder doesn't exist yet.
+func LoadTemplates() ([]*PromptTemplate, error) {
+	entries, err := os.ReadDir(TemplatesPath())
+	if os.IsNotExist(err) {
+		return parseBuiltinTemplates(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates: %w", err)
+	}
+
+	var templates []*PromptTemplate
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != templateExt {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(TemplatesPath(), e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		t, err := ParsePromptTemplate(strings.TrimSuffix(e.Name(), templateExt), string(data))
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// parseBuiltinTemplates returns the built-in templates, sorted by name
+func parseBuiltinTemplates() []*PromptTemplate {
+	names := make([]string, 0, len(builtinTemplates))
+	for name := range builtinTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	templates := make([]*PromptTemplate, 0, len(names))
+	for _, name := range names {
+		t, err := ParsePromptTemplate(name, builtinTemplates[name])
+		if err != nil {
+			panic(err) // The built-in templates are known to parse
+		}
+		templates = append(templates, t)
+	}
+	return templates
+}
+
+// EnsureTemplates writes the built-in templates to the vault if the templates
+// folder doesn't exist, so they can be edited and added to by hand
+func EnsureTemplates() error {
+	dir := TemplatesPath()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create templates directory: %w", err)
+	}
+	for name, text := range builtinTemplates {
+		if err := os.WriteFile(filepath.Join(dir, name+templateExt), []byte(text), 0644); err != nil {
+			return fmt.Errorf("failed to write template: %w", err)
+		}
+	}
+	return nil
+}
+
+// FindTemplate looks up a template by name, ignoring case
+func FindTemplate(templates []*PromptTemplate, name string) (*PromptTemplate, bool) {
+	for _, t := range templates {
+		if strings.EqualFold(t.Name, strings.TrimSpace(name)) {
+			return t, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,82 @@
+package fs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// AttachmentsDir is the folder inside the vault holding attached images.
+const AttachmentsDir = "attachments"
+
+// imageTypes maps the image extensions that can be attached to their MIME types.
+var imageTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+// SaveAttachment copies an image into the vault's attachments folder. Files
+// are named after their content, so attaching the same image twice stores it once.
+//
+// Parameters:
+//   - vaultPath: The vault directory to copy into
+//   - src: The image file to attach
+//
+// Returns:
+//   - string: The attachment's path inside the vault, e.g. "attachments/3fa1….png"
+//   - error: An error if the file isn't an image or cannot be copied
+func SaveAttachment(vaultPath, src string) (string, error) {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to read attachment: %w", err)
+	}
+	ext := strings.ToLower(filepath.Ext(src))
+	if ext == ".jpeg" {
+		ext = ".jpg"
+	}
+	if _, ok := imageTypes[ext]; !ok {
+		return "", fmt.Errorf("%s is not a supported image (png, jpg, gif or webp)", filepath.Base(src))
+	}
+	if !strings.HasPrefix(http.DetectContentType(data), "image/") {
+		return "", fmt.Errorf("%s does not contain an image", filepath.Base(src))
+	}
+
+	sum := sha256.Sum256(data)
+	ref := path.Join(AttachmentsDir, hex.EncodeToString(sum[:16])+ext)
+	dest := filepath.Join(vaultPath, filepath.FromSlash(ref))
+	if _, err := os.Stat(dest); err == nil {
+		return ref, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", fmt.Errorf("failed to create attachments directory: %w", err)
+	}
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write attachment: %w", err)
+	}
+	return ref, nil
+}
+
+// LoadAttachment reads an attachment saved by SaveAttachment.
+func LoadAttachment(vaultPath, ref string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(vaultPath, filepath.FromSlash(ref)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachment %s: %w", ref, err)
+	}
+	return data, nil
+}
+
+// AttachmentType returns the MIME type of an attachment, from its extension.
+func AttachmentType(ref string) string {
+	if t, ok := imageTypes[strings.ToLower(path.Ext(ref))]; ok {
+		return t
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,282 @@
+package fs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConversationsDir is the folder inside the vault holding one JSON file per conversation.
+const ConversationsDir = "conversations"
+
+// Message roles understood by the chat API.
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleTool      = "tool" // The result of a tool the assistant called
+)
+
+// Message is a single turn of a conversation.
+type Message struct {
+	Role    string    `json:"role"`    // system, user, assistant or tool
+	Content string    `json:"content"` // The text of the turn
+	Time    time.Time `json:"time"`    // When the turn was added
+	// ToolCalls are the tools an assistant turn asked to run
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolName is the tool whose result a tool turn holds
+	ToolName string `json:"tool_name,omitempty"`
+	// Images are images attached to a user turn, as paths inside the vault
+	Images []string `json:"images,omitempty"`
+}
+
+// ToolCall is a request from the model to run a tool.
+type ToolCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"` // A JSON object
+}
+
+// Conversation is an ordered list of messages that is sent to the model as a
+// whole, so follow-up questions can refer to earlier turns.
+type Conversation struct {
+	ID       string    `json:"id"`
+	Title    string    `json:"title"`
+	Persona  string    `json:"persona,omitempty"` // Persona whose system prompt opens the conversation
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+	Messages []Message `json:"messages"`
+	// Summary stands in for the earlier turns once they no longer fit the
+	// model's context window; Summarized is the index of the first message
+	// after the ones it covers
+	Summary    string `json:"summary,omitempty"`
+	Summarized int    `json:"summarized,omitempty"`
+	// Synopsis is a one-paragraph summary of the whole conversation, generated
+	// with its title once the conversation has ended; Titled is the number of
+	// messages the two cover
+	Synopsis string `json:"synopsis,omitempty"`
+	Titled   int    `json:"titled,omitempty"`
+}
+
+// SummaryPrefix opens the system message that stands in for summarized turns.
+const SummaryPrefix = "Summary of the earlier conversation:\n"
+
+// NewConversation starts an empty conversation with a fresh ID.
+func NewConversation() *Conversation {
+	now := time.Now()
+	return &Conversation{
+		ID:      uuid.NewString(),
+		Created: now,
+		Updated: now,
+	}
+}
+
+// Append adds a turn to the conversation. The first user turn also becomes
+// the conversation's title.
+func (c *Conversation) Append(role, content string) {
+	c.AppendMessage(Message{Role: role, Content: content})
+}
+
+// AppendMessage adds a message, such as a tool call or its result, to the
+// conversation, stamping it with the current time.
+func (c *Conversation) AppendMessage(msg Message) {
+	now := time.Now()
+	msg.Time = now
+	c.Messages = append(c.Messages, msg)
+	c.Updated = now
+	if c.Title == "" && msg.Role == RoleUser {
+		c.Title = strings.TrimSpace(strings.SplitN(msg.Content, "\n", 2)[0])
+	}
+}
+
+// NeedsTitle reports whether the conversation has turns that its generated
+// title and synopsis don't cover yet.
+func (c *Conversation) NeedsTitle() bool {
+	return c.Turns() > 0 && len(c.Messages) > c.Titled
+}
+
+// SetTitle replaces the title and synopsis with generated ones covering every
+// message so far. Updated is left alone, as no turn was added.
+func (c *Conversation) SetTitle(title, synopsis string) {
+	if title != "" {
+		c.Title = title
+	}
+	c.Synopsis = synopsis
+	c.Titled = len(c.Messages)
+}
+
+// Prompt returns the messages to send before the next question: the leading
+// system messages, the summary of the summarized turns and the turns after it.
+func (c *Conversation) Prompt() []Message {
+	nSystem := c.systemMessages()
+	from := c.Summarized
+	if from < nSystem {
+		from = nSystem
+	}
+	if from > len(c.Messages) {
+		from = len(c.Messages)
+	}
+
+	prompt := make([]Message, 0, len(c.Messages)-from+nSystem+1)
+	prompt = append(prompt, c.Messages[:nSystem]...)
+	if c.Summary != "" {
+		prompt = append(prompt, Message{Role: RoleSystem, Content: SummaryPrefix + c.Summary})
+	}
+	return append(prompt, c.Messages[from:]...)
+}
+
+// Summarize replaces the next n unsummarized turns with summary, which must
+// also cover whatever the previous summary did.
+func (c *Conversation) Summarize(summary string, n int) {
+	if c.Summarized < c.systemMessages() {
+		c.Summarized = c.systemMessages()
+	}
+	c.Summarized += n
+	if c.Summarized > len(c.Messages) {
+		c.Summarized = len(c.Messages)
+	}
+	c.Summary = summary
+	c.Updated = time.Now()
+}
+
+// Unsummarized returns how many messages follow the system messages and the
+// summarized turns.
+func (c *Conversation) Unsummarized() int {
+	from := c.Summarized
+	if from < c.systemMessages() {
+		from = c.systemMessages()
+	}
+	if from > len(c.Messages) {
+		return 0
+	}
+	return len(c.Messages) - from
+}
+
+// systemMessages returns the number of system messages opening the conversation.
+func (c *Conversation) systemMessages() int {
+	n := 0
+	for n < len(c.Messages) && c.Messages[n].Role == RoleSystem {
+		n++
+	}
+	return n
+}
+
+// Turns returns the number of user messages in the conversation.
+func (c *Conversation) Turns() int {
+	n := 0
+	for _, msg := range c.Messages {
+		if msg.Role == RoleUser {
+			n++
+		}
+	}
+	return n
+}
+
+// ConversationPath returns the file a conversation is stored in.
+func ConversationPath(vaultPath, id string) string {
+	return filepath.Join(vaultPath, ConversationsDir, id+".json")
+}
+
+// SaveConversation writes a conversation to the vault, replacing any earlier
+// version of it. Empty conversations are not written.
+//
+// Parameters:
+//   - vaultPath: The vault directory to write into
+//   - c: The conversation to persist
+//
+// Returns:
+//   - error: An error if the file cannot be written
+func SaveConversation(vaultPath string, c *Conversation) error {
+	if len(c.Messages) == 0 {
+		return nil
+	}
+
+	dir := filepath.Join(vaultPath, ConversationsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create conversations directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversation: %w", err)
+	}
+
+	if err := os.WriteFile(ConversationPath(vaultPath, c.ID), data, 0644); err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	return nil
+}
+
+// LoadConversation reads a conversation from the vault by ID.
+func LoadConversation(vaultPath, id string) (*Conversation, error) {
+	data, err := os.ReadFile(ConversationPath(vaultPath, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conversation %s: %w", id, err)
+	}
+
+	var c Conversation
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse conversation %s: %w", id, err)
+	}
+	return &c, nil
+}
+
+// ListConversations reads every conversation in the vault, most recently updated first.
+// A missing conversations folder yields an empty list.
+func ListConversations(vaultPath string) ([]*Conversation, error) {
+	entries, err := os.ReadDir(filepath.Join(vaultPath, ConversationsDir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+
+	var convs []*Conversation
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		c, err := LoadConversation(vaultPath, strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		convs = append(convs, c)
+	}
+
+	sort.Slice(convs, func(i, j int) bool {
+		return convs[i].Updated.After(convs[j].Updated)
+	})
+	return convs, nil
+}
+
+// FindConversation loads the conversation whose ID equals or starts with id.
+// A prefix must match exactly one conversation.
+func FindConversation(vaultPath, id string) (*Conversation, error) {
+	convs, err := ListConversations(vaultPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *Conversation
+	for _, c := range convs {
+		if c.ID == id {
+			return c, nil
+		}
+		if strings.HasPrefix(c.ID, id) {
+			if found != nil {
+				return nil, fmt.Errorf("conversation ID %q is ambiguous", id)
+			}
+			found = c
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("no conversation with ID %q", id)
+	}
+	return found, nil
+}
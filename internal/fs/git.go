@@ -0,0 +1,31 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// GitRepoRoot walks up from dir looking for a directory containing ".git".
+//
+// Parameters:
+//   - dir: The directory to start searching from
+//
+// Returns:
+//   - string: The repository root, or "" if dir is not inside a git repository
+func GitRepoRoot(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,119 @@
+package fs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MemoriesFileName is the name of the JSON file holding long-term memories inside the vault.
+const MemoriesFileName = "memories.json"
+
+// Kinds of memory.
+const (
+	MemoryFact       = "fact"       // Something true about the user's world, e.g. "the team deploys on k8s"
+	MemoryPreference = "preference" // How the user likes to work or be answered
+)
+
+// memoryNamespace is the UUID namespace used to derive stable IDs for memories.
+var memoryNamespace = uuid.MustParse("0b5f3d2e-8c47-4f6a-9e1d-7a2c4b9e6f13")
+
+// Memory is a durable fact or preference distilled from conversations.
+type Memory struct {
+	ID      string    `json:"id"`
+	Text    string    `json:"text"` // The fact, as a short sentence
+	Kind    string    `json:"kind"` // fact or preference
+	Created time.Time `json:"created"`
+	// Sources are the IDs of the Q&A pairs the memory was distilled from
+	Sources []string `json:"sources,omitempty"`
+	// Conversation is the conversation the memory was distilled from
+	Conversation string `json:"conversation,omitempty"`
+}
+
+// MemoryID derives a stable ID for a memory from its text.
+func MemoryID(text string) string {
+	return uuid.NewSHA1(memoryNamespace, []byte(strings.ToLower(strings.TrimSpace(text)))).String()
+}
+
+// MemoryFile represents the structure of the saved memories file.
+type MemoryFile struct {
+	Memories []Memory `json:"memories"`
+	// Distilled records, per conversation ID, how many of its messages have
+	// been distilled into memories
+	Distilled map[string]int `json:"distilled,omitempty"`
+}
+
+// MemoriesFilePath returns the path of the memories file inside the given vault.
+func MemoriesFilePath(vaultPath string) string {
+	return filepath.Join(vaultPath, MemoriesFileName)
+}
+
+// LoadMemories reads the memories file from the vault.
+// A missing file is not an error and yields an empty MemoryFile.
+func LoadMemories(vaultPath string) (*MemoryFile, error) {
+	mf := MemoryFile{Distilled: map[string]int{}}
+
+	data, err := os.ReadFile(MemoriesFilePath(vaultPath))
+	if os.IsNotExist(err) {
+		return &mf, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memories file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &mf); err != nil {
+		return nil, fmt.Errorf("failed to parse memories file: %w", err)
+	}
+	if mf.Distilled == nil {
+		mf.Distilled = map[string]int{}
+	}
+	return &mf, nil
+}
+
+// SaveMemories writes the memories file to the vault, creating the vault if needed.
+func SaveMemories(vaultPath string, mf *MemoryFile) error {
+	if err := os.MkdirAll(vaultPath, 0755); err != nil {
+		return fmt.Errorf("failed to create vault directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(mf, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal memories: %w", err)
+	}
+
+	if err := os.WriteFile(MemoriesFilePath(vaultPath), data, 0644); err != nil {
+		return fmt.Errorf("failed to write memories to file: %w", err)
+	}
+	return nil
+}
+
+// Find returns the position of the memory whose ID equals or starts with id.
+// A prefix must match exactly one memory.
+func (f *MemoryFile) Find(id string) (int, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return -1, fmt.Errorf("no ID given")
+	}
+
+	found := -1
+	for i, mem := range f.Memories {
+		if mem.ID == id {
+			return i, nil
+		}
+		if strings.HasPrefix(mem.ID, id) {
+			if found >= 0 {
+				return -1, fmt.Errorf("memory ID %q is ambiguous", id)
+			}
+			found = i
+		}
+	}
+	if found < 0 {
+		return -1, fmt.Errorf("no memory with ID %q", id)
+	}
+	return found, nil
+}
@@ -0,0 +1,170 @@
+package fs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QAFileName is the name of the JSON file holding stored Q&A pairs inside the vault.
+const QAFileName = "que_ans.json"
+
+// qaNamespace is the UUID namespace used to derive stable IDs for Q&A pairs.
+var qaNamespace = uuid.MustParse("6f1c1c52-3f0e-4a53-9d2f-5a0b8c2f7e41")
+
+// QA represents a single question-answer pair with metadata.
+// It's used for both in-memory representation and JSON serialization.
+type QA struct {
+	ID       string    `json:"id,omitempty"`       // Qdrant point ID of the pair
+	Question string    `json:"question"`           // The user's question
+	Answer   string    `json:"answer"`             // The AI's response
+	Time     time.Time `json:"time"`               // When the Q&A was created
+	Persona  string    `json:"persona,omitempty"`  // Persona the answer was generated with
+	Model    string    `json:"model,omitempty"`    // Model that generated the answer
+	Endpoint string    `json:"endpoint,omitempty"` // Profile of the backend that answered
+	Template string    `json:"template,omitempty"` // Prompt template the question was written with
+	Thinking string    `json:"thinking,omitempty"` // The model's reasoning, if kept; never embedded
+	Images   []string  `json:"images,omitempty"`   // Images attached to the question, as paths inside the vault
+	// Options are the generation options the answer was produced with, so it can be reproduced
+	Options json.RawMessage `json:"options,omitempty"`
+	Metrics *Metrics        `json:"metrics,omitempty"` // Token usage and timing of the answer
+}
+
+// Metrics records token usage and timing of a generated answer.
+type Metrics struct {
+	PromptTokens     int     `json:"prompt_tokens,omitempty"`     // Tokens in the request, as counted by the server
+	CompletionTokens int     `json:"completion_tokens,omitempty"` // Tokens in the answer, as counted by the server
+	TTFTMillis       int64   `json:"ttft_ms"`                     // Time to first token
+	TotalMillis      int64   `json:"total_ms"`                    // Time until the answer was complete
+	TokensPerSecond  float64 `json:"tokens_per_second,omitempty"` // Generation throughput
+}
+
+// QAFile represents the structure of the saved Q&A data file.
+// It's used to marshal and unmarshal Q&A pairs to/from JSON.
+type QAFile struct {
+	QAs []QA `json:"qas"` // Collection of Q&A pairs
+}
+
+// QAID derives a stable ID for a question-answer pair.
+// The same pair always maps to the same ID, so storing it twice is idempotent.
+func QAID(question, answer string) string {
+	return uuid.NewSHA1(qaNamespace, []byte(question+"\x00"+answer)).String()
+}
+
+// Key returns the entry's ID, deriving one from its content for entries
+// written before IDs were recorded.
+func (qa QA) Key() string {
+	if qa.ID != "" {
+		return qa.ID
+	}
+	return QAID(qa.Question, qa.Answer)
+}
+
+// ShortID shortens an ID to its first 8 characters for display. Shorter IDs,
+// such as the numeric IDs of points other tools stored in Qdrant, are
+// returned whole.
+func ShortID(id string) string {
+	if len(id) <= 8 {
+		return id
+	}
+	return id[:8]
+}
+
+// QAFilePath returns the path of the Q&A file inside the given vault.
+func QAFilePath(vaultPath string) string {
+	return filepath.Join(vaultPath, QAFileName)
+}
+
+// LoadQAs reads the Q&A file from the vault.
+// A missing file is not an error and yields an empty QAFile.
+//
+// Parameters:
+//   - vaultPath: The vault directory containing the Q&A file
+//
+// Returns:
+//   - *QAFile: The parsed Q&A pairs
+//   - error: An error if the file exists but cannot be read or parsed
+func LoadQAs(vaultPath string) (*QAFile, error) {
+	var qas QAFile
+
+	data, err := os.ReadFile(QAFilePath(vaultPath))
+	if os.IsNotExist(err) {
+		return &qas, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Q&A file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &qas); err != nil {
+		return nil, fmt.Errorf("failed to parse Q&A file: %w", err)
+	}
+	return &qas, nil
+}
+
+// SaveQAs writes the Q&A file to the vault, creating the vault if needed.
+//
+// Parameters:
+//   - vaultPath: The vault directory to write into
+//   - qas: The Q&A pairs to persist
+//
+// Returns:
+//   - error: An error if the file cannot be written
+func SaveQAs(vaultPath string, qas *QAFile) error {
+	if err := os.MkdirAll(vaultPath, 0755); err != nil {
+		return fmt.Errorf("failed to create vault directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(qas, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal Q&As: %w", err)
+	}
+
+	if err := os.WriteFile(QAFilePath(vaultPath), data, 0644); err != nil {
+		return fmt.Errorf("failed to write Q&As to file: %w", err)
+	}
+	return nil
+}
+
+// BackupFile copies the file at path to path + ".bak", replacing any previous backup.
+// A missing file is not an error.
+func BackupFile(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s for backup: %w", path, err)
+	}
+	if err := os.WriteFile(path+".bak", data, 0644); err != nil {
+		return fmt.Errorf("failed to write backup of %s: %w", path, err)
+	}
+	return nil
+}
+
+// Remove deletes every entry for which match returns true and
+// reports how many entries were removed.
+func (f *QAFile) Remove(match func(QA) bool) int {
+	kept := f.QAs[:0]
+	for _, qa := range f.QAs {
+		if !match(qa) {
+			kept = append(kept, qa)
+		}
+	}
+	removed := len(f.QAs) - len(kept)
+	f.QAs = kept
+	return removed
+}
+
+// Index returns the position of the first entry for which match returns true, or -1.
+func (f *QAFile) Index(match func(QA) bool) int {
+	for i, qa := range f.QAs {
+		if match(qa) {
+			return i
+		}
+	}
+	return -1
+}
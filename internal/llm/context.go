@@ -0,0 +1,301 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// Context window defaults, used when the request doesn't say
+const (
+	// DefaultContextWindow is Ollama's context length when num_ctx isn't set
+	DefaultContextWindow = 4096
+	// DefaultAnswerReserve is kept free for the answer when max_tokens isn't set
+	DefaultAnswerReserve = 1024
+	// messageOverhead approximates the tokens a chat template adds per message
+	messageOverhead = 4
+	// defaultCharsPerToken is the estimate used before a model has been measured
+	defaultCharsPerToken = 4.0
+	// summaryShare is the part of the history budget a summary may take
+	summaryShare = 4
+	// contextShare is the part of the free budget tool results may take
+	contextShare = 3
+)
+
+// TokenEstimator estimates how many tokens text takes. It starts from about
+// four characters per token and learns each model's ratio from the prompt
+// token counts servers report. It is safe for concurrent use.
+type TokenEstimator struct {
+	mu     sync.Mutex
+	ratios map[string]float64 // Characters per token, per model
+}
+
+// NewTokenEstimator returns an estimator that hasn't measured any model yet
+func NewTokenEstimator() *TokenEstimator {
+	return &TokenEstimator{ratios: make(map[string]float64)}
+}
+
+// Observe records that a prompt of the given messages took tokens tokens on model
+func (e *TokenEstimator) Observe(model string, messages []fs.Message, tokens int) {
+	chars := 0
+	for _, msg := range messages {
+		chars += utf8.RuneCountInString(msg.Content)
+	}
+	tokens -= messageOverhead * len(messages)
+	if model == "" || chars == 0 || tokens <= 0 {
+		return
+	}
+
+	ratio := float64(chars) / float64(tokens)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if old, ok := e.ratios[model]; ok {
+		// Smooth out prompts that tokenize unusually well or badly
+		ratio = (old + ratio) / 2
+	}
+	e.ratios[model] = ratio
+}
+
+// Estimate returns the approximate number of tokens text takes on model
+func (e *TokenEstimator) Estimate(model, text string) int {
+	ratio := defaultCharsPerToken
+	e.mu.Lock()
+	if r, ok := e.ratios[model]; ok {
+		ratio = r
+	}
+	e.mu.Unlock()
+
+	chars := utf8.RuneCountInString(text)
+	if chars == 0 {
+		return 0
+	}
+	return int(float64(chars)/ratio) + 1
+}
+
+// EstimateMessages returns the approximate prompt tokens of messages on model
+func (e *TokenEstimator) EstimateMessages(model string, messages []fs.Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += e.Estimate(model, msg.Content) + messageOverhead
+	}
+	return total
+}
+
+// ContextBudget is the size of a model's context window and how much of it
+// is kept free for the answer
+type ContextBudget struct {
+	Window  int // Tokens the model can attend to
+	Reserve int // Tokens kept for the answer
+}
+
+// BudgetFor returns the budget of a request: num_ctx, or Ollama's default,
+// minus max_tokens, or a default reserve, for the answer
+func BudgetFor(opts Options) ContextBudget {
+	b := ContextBudget{Window: DefaultContextWindow, Reserve: DefaultAnswerReserve}
+	if opts.NumCtx > 0 {
+		b.Window = opts.NumCtx
+	}
+	if opts.MaxTokens > 0 {
+		b.Reserve = opts.MaxTokens
+	}
+	if b.Reserve > b.Window/2 {
+		b.Reserve = b.Window / 2
+	}
+	return b
+}
+
+// ContextPlan is how a prompt is fitted into a context window: the tokens
+// each part takes, what was trimmed, and which history must be summarized
+type ContextPlan struct {
+	Budget   ContextBudget
+	System   int // Tokens of the system messages, including any summary
+	Context  int // Tokens of tool calls and results, after trimming
+	History  int // Tokens of the other earlier messages that are kept
+	Question int // Tokens of the question
+
+	// Messages is the prompt to send, with oversized tool results trimmed
+	Messages []fs.Message
+	// Summarize is the number of history messages, counted from the start
+	// of the history, that don't fit and should be replaced by a summary
+	Summarize int
+	// SummaryTokens is the size a summary of them may have
+	SummaryTokens int
+	// Decisions explains each trimming decision, for the debug view
+	Decisions []string
+}
+
+// Total returns the estimated prompt tokens of the plan
+func (p ContextPlan) Total() int {
+	return p.System + p.Context + p.History + p.Question
+}
+
+// PlanContext fits a prompt into a context budget. messages are the leading
+// system messages, the history and the question as the last message. The
+// system messages and the question are always kept. Tool results may take a
+// third of what is left; older ones are trimmed first. If the history still
+// doesn't fit, the plan names the oldest messages to summarize, ending at a
+// user message so that whole turns are summarized.
+//
+// Parameters:
+//   - est: Estimates the tokens of each message
+//   - model: The model the prompt is for
+//   - budget: The context window and answer reserve
+//   - messages: The prompt as it would be sent
+//
+// Returns:
+//   - ContextPlan: The fitted prompt and the decisions taken
+func PlanContext(est *TokenEstimator, model string, budget ContextBudget, messages []fs.Message) ContextPlan {
+	plan := ContextPlan{Budget: budget}
+	if len(messages) == 0 {
+		return plan
+	}
+
+	nSystem := 0
+	for nSystem < len(messages)-1 && messages[nSystem].Role == fs.RoleSystem {
+		nSystem++
+	}
+	system := messages[:nSystem]
+	history := append([]fs.Message(nil), messages[nSystem:len(messages)-1]...)
+	question := messages[len(messages)-1]
+
+	plan.System = est.EstimateMessages(model, system)
+	plan.Question = est.EstimateMessages(model, []fs.Message{question})
+	free := budget.Window - budget.Reserve - plan.System - plan.Question
+	if free < 0 {
+		plan.Decisions = append(plan.Decisions, fmt.Sprintf("system prompt and question alone take %d tokens, over the %d available", plan.System+plan.Question, budget.Window-budget.Reserve))
+		free = 0
+	}
+
+	// Trim tool results, newest first, to their share of the free budget
+	contextCap := free / contextShare
+	for i := len(history) - 1; i >= 0; i-- {
+		msg := &history[i]
+		if msg.Role != fs.RoleTool && len(msg.ToolCalls) == 0 {
+			continue
+		}
+		tokens := est.EstimateMessages(model, []fs.Message{*msg})
+		if plan.Context+tokens <= contextCap {
+			plan.Context += tokens
+			continue
+		}
+		left := contextCap - plan.Context - messageOverhead
+		if msg.Role == fs.RoleTool {
+			msg.Content = trimToTokens(est, model, msg.Content, left)
+			trimmed := est.EstimateMessages(model, []fs.Message{*msg})
+			plan.Decisions = append(plan.Decisions, fmt.Sprintf("trimmed %s result from %d to %d tokens", toolName(*msg), tokens, trimmed))
+			tokens = trimmed
+		}
+		plan.Context += tokens
+	}
+	free -= plan.Context
+
+	// Keep the newest history that fits; summarize the rest
+	historyTokens := make([]int, len(history))
+	for i, msg := range history {
+		if msg.Role != fs.RoleTool && len(msg.ToolCalls) == 0 {
+			historyTokens[i] = est.EstimateMessages(model, []fs.Message{msg})
+			plan.History += historyTokens[i]
+		}
+	}
+	if plan.History > free {
+		plan.SummaryTokens = free / summaryShare
+		keep := free - plan.SummaryTokens
+		drop := 0
+		for drop < len(history) && plan.History > keep {
+			plan.History -= historyTokens[drop]
+			drop++
+		}
+		// Don't split a turn: drop up to the start of the next question
+		for drop < len(history) && history[drop].Role != fs.RoleUser {
+			plan.History -= historyTokens[drop]
+			drop++
+		}
+		dropped := est.EstimateMessages(model, history[:drop])
+		plan.Context = 0
+		for _, msg := range history[drop:] {
+			if msg.Role == fs.RoleTool || len(msg.ToolCalls) > 0 {
+				plan.Context += est.EstimateMessages(model, []fs.Message{msg})
+			}
+		}
+		plan.Summarize = drop
+		history = history[drop:]
+		plan.Decisions = append(plan.Decisions, fmt.Sprintf("summarizing %d earlier messages (%d tokens) into at most %d tokens", drop, dropped, plan.SummaryTokens))
+	}
+
+	plan.Messages = make([]fs.Message, 0, nSystem+len(history)+1)
+	plan.Messages = append(plan.Messages, system...)
+	plan.Messages = append(plan.Messages, history...)
+	plan.Messages = append(plan.Messages, question)
+	return plan
+}
+
+// trimToTokens shortens text to about tokens tokens, marking the cut
+func trimToTokens(est *TokenEstimator, model, text string, tokens int) string {
+	const marker = "\n[trimmed to fit the context window]"
+	if tokens <= est.Estimate(model, marker) {
+		return "[omitted to fit the context window]"
+	}
+	r := []rune(text)
+	keep := len(r) * tokens / (est.Estimate(model, text) + 1)
+	if keep >= len(r) {
+		return text
+	}
+	return string(r[:keep]) + marker
+}
+
+// toolName names the tool a message belongs to, for decisions
+func toolName(msg fs.Message) string {
+	if msg.ToolName != "" {
+		return msg.ToolName
+	}
+	return "tool"
+}
+
+// summaryPrompt asks the model to condense earlier turns
+const summaryPrompt = `Summarize the conversation above for your own later reference. Keep every fact, decision, name, number and piece of code the user may refer back to; drop pleasantries. Write at most %d words. Reply with the summary only.`
+
+// Summarize condenses messages, continuing an earlier summary if there is one
+//
+// Parameters:
+//   - ctx: Cancels the request
+//   - provider: The backend to ask
+//   - req: The model and options to summarize with; its messages are replaced
+//   - previous: The summary of the messages before these, or ""
+//   - messages: The messages to summarize
+//   - tokens: The size the summary may have
+//
+// Returns:
+//   - string: The summary, covering previous and messages
+//   - error: An error if the request fails
+func Summarize(ctx context.Context, provider ChatProvider, req ChatRequest, previous string, messages []fs.Message, tokens int) (string, error) {
+	var transcript []fs.Message
+	if previous != "" {
+		transcript = append(transcript, fs.Message{Role: fs.RoleSystem, Content: fs.SummaryPrefix + previous})
+	}
+	for _, msg := range messages {
+		// Tool exchanges are too verbose to be worth summarizing verbatim
+		if msg.Role == fs.RoleTool || len(msg.ToolCalls) > 0 {
+			continue
+		}
+		transcript = append(transcript, fs.Message{Role: msg.Role, Content: msg.Content})
+	}
+	if tokens < 64 {
+		tokens = 64
+	}
+	words := tokens * 3 / 4
+	transcript = append(transcript, fs.Message{Role: fs.RoleUser, Content: fmt.Sprintf(summaryPrompt, words)})
+
+	req.Messages = transcript
+	req.Tools = nil
+	req.Format = nil
+	req.Options.MaxTokens = tokens
+	resp, err := Stream(ctx, provider, req, func(string) error { return nil })
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize the conversation: %w", err)
+	}
+	return strings.TrimSpace(resp.Content), nil
+}
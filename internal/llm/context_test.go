@@ -0,0 +1,270 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// sized returns a message an unmeasured estimator puts at tokens tokens,
+// message overhead included
+func sized(role string, tokens int) fs.Message {
+	return fs.Message{Role: role, Content: strings.Repeat("x", 4*(tokens-messageOverhead-1))}
+}
+
+// roles lists the roles of messages, for comparing prompts
+func roles(messages []fs.Message) string {
+	var r []string
+	for _, msg := range messages {
+		r = append(r, msg.Role)
+	}
+	return strings.Join(r, ",")
+}
+
+func TestPlanContext(t *testing.T) {
+	const (
+		sys  = fs.RoleSystem
+		user = fs.RoleUser
+		asst = fs.RoleAssistant
+	)
+	turns := func(n int) []fs.Message {
+		var messages []fs.Message
+		for i := 0; i < n; i++ {
+			messages = append(messages, sized(user, 14), sized(asst, 14))
+		}
+		return messages
+	}
+	prompt := func(system fs.Message, history []fs.Message) []fs.Message {
+		return append(append([]fs.Message{system}, history...), sized(user, 14))
+	}
+
+	tests := []struct {
+		name          string
+		budget        ContextBudget
+		messages      []fs.Message
+		summarize     int
+		summaryTokens int
+		history       int
+		kept          string // Roles of the planned prompt
+		decision      string // A substring of the first decision, or "" for none
+	}{
+		{
+			name:     "fits",
+			budget:   ContextBudget{Window: 1000, Reserve: 200},
+			messages: prompt(sized(sys, 14), turns(2)),
+			history:  56,
+			kept:     "system,user,assistant,user,assistant,user",
+		},
+		{
+			// 80 tokens are free: a quarter for the summary leaves 60 for
+			// history, so the long question must go and its answer with it
+			name:          "drops up to the next user message",
+			budget:        ContextBudget{Window: 308, Reserve: 200},
+			messages:      prompt(sized(sys, 14), append([]fs.Message{sized(user, 40), sized(asst, 14)}, turns(1)...)),
+			summarize:     2,
+			summaryTokens: 20,
+			history:       28,
+			kept:          "system,user,assistant,user",
+			decision:      "summarizing 2 earlier messages (54 tokens) into at most 20 tokens",
+		},
+		{
+			name:          "drops a trailing answer with no user message after it",
+			budget:        ContextBudget{Window: 245, Reserve: 200},
+			messages:      prompt(sized(sys, 14), append(turns(1), sized(asst, 14))),
+			summarize:     3,
+			summaryTokens: 4,
+			kept:          "system,user",
+			decision:      "summarizing 3 earlier messages",
+		},
+		{
+			name:      "system prompt and question over the window",
+			budget:    ContextBudget{Window: 100, Reserve: 50},
+			messages:  prompt(sized(sys, 104), turns(1)),
+			summarize: 2,
+			kept:      "system,user",
+			decision:  "system prompt and question alone take 118 tokens, over the 50 available",
+		},
+		{
+			name:     "question only",
+			budget:   ContextBudget{Window: 1000, Reserve: 200},
+			messages: []fs.Message{sized(user, 14)},
+			kept:     "user",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := PlanContext(NewTokenEstimator(), "m", tt.budget, tt.messages)
+			if plan.Summarize != tt.summarize || plan.SummaryTokens != tt.summaryTokens {
+				t.Errorf("Summarize = %d into %d tokens, want %d into %d", plan.Summarize, plan.SummaryTokens, tt.summarize, tt.summaryTokens)
+			}
+			if plan.History != tt.history {
+				t.Errorf("History = %d tokens, want %d", plan.History, tt.history)
+			}
+			if got := roles(plan.Messages); got != tt.kept {
+				t.Errorf("Messages = %s, want %s", got, tt.kept)
+			}
+			switch {
+			case tt.decision == "" && len(plan.Decisions) > 0:
+				t.Errorf("Decisions = %q, want none", plan.Decisions)
+			case tt.decision != "" && (len(plan.Decisions) == 0 || !strings.Contains(plan.Decisions[0], tt.decision)):
+				t.Errorf("Decisions = %q, want %q first", plan.Decisions, tt.decision)
+			}
+		})
+	}
+}
+
+func TestPlanContextTrimsToolResultsNewestFirst(t *testing.T) {
+	call := func() fs.Message {
+		return fs.Message{Role: fs.RoleAssistant, ToolCalls: []fs.ToolCall{{Name: "t", Arguments: []byte(`{}`)}}}
+	}
+	result := func(name string) fs.Message {
+		msg := sized(fs.RoleTool, 64)
+		msg.ToolName = name
+		return msg
+	}
+	messages := []fs.Message{
+		sized(fs.RoleSystem, 14),
+		sized(fs.RoleUser, 14),
+		call(), result("fetch"),
+		call(), result("read_file"),
+		call(), result("grep"),
+		sized(fs.RoleAssistant, 14),
+		sized(fs.RoleUser, 14),
+	}
+	// 300 tokens are free, so tool calls and results may take 100: the
+	// newest result fits whole, the one before is cut and the oldest omitted
+	plan := PlanContext(NewTokenEstimator(), "m", ContextBudget{Window: 528, Reserve: 200}, messages)
+
+	if plan.Summarize != 0 {
+		t.Errorf("Summarize = %d, want 0", plan.Summarize)
+	}
+	if len(plan.Messages) != len(messages) {
+		t.Fatalf("planned %d messages, want all %d", len(plan.Messages), len(messages))
+	}
+	if got := plan.Messages[7].Content; got != messages[7].Content {
+		t.Errorf("the newest result was changed to %q", got)
+	}
+	trimmed := plan.Messages[5].Content
+	if !strings.HasSuffix(trimmed, "[trimmed to fit the context window]") || len(trimmed) >= len(messages[5].Content) {
+		t.Errorf("the middle result = %q, want it cut and marked", trimmed)
+	}
+	if got := plan.Messages[3].Content; got != "[omitted to fit the context window]" {
+		t.Errorf("the oldest result = %q, want it omitted", got)
+	}
+	if messages[5].Content == trimmed {
+		t.Error("PlanContext changed the messages it was given")
+	}
+
+	want := []string{"trimmed read_file result from 64 to", "trimmed fetch result from 64 to"}
+	if len(plan.Decisions) != len(want) {
+		t.Fatalf("Decisions = %q, want %d", plan.Decisions, len(want))
+	}
+	for i, d := range want {
+		if !strings.HasPrefix(plan.Decisions[i], d) {
+			t.Errorf("Decisions[%d] = %q, want %q…", i, plan.Decisions[i], d)
+		}
+	}
+}
+
+// TestPlanContextSummarizeCountsConversationMessages checks that the
+// Summarize count of a prompt built from Conversation.Prompt, capped at
+// Conversation.Unsummarized as the UI does, summarizes exactly the history
+// the plan leaves out
+func TestPlanContextSummarizeCountsConversationMessages(t *testing.T) {
+	tests := []struct {
+		name      string
+		extra     []fs.Message // Added after the conversation, before the question
+		free      int          // Tokens left for history after system and question
+		summarize int
+		kept      int // Conversation messages left in the prompt
+		planned   int // History messages in the planned prompt
+	}{
+		{"new question", nil, 50, 2, 2, 2},
+		{"nothing to drop", nil, 1000, 0, 4, 4},
+		{"continuation kept", []fs.Message{sized(fs.RoleAssistant, 14)}, 1000, 0, 4, 5},
+		// A continuation's stopped answer isn't in the conversation yet, so
+		// the plan may name one more message than the UI can summarize
+		{"continuation dropped", []fs.Message{sized(fs.RoleAssistant, 14)}, 0, 5, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := &fs.Conversation{
+				Messages: []fs.Message{
+					sized(fs.RoleSystem, 14),
+					sized(fs.RoleUser, 14), sized(fs.RoleAssistant, 14),
+					sized(fs.RoleUser, 14), sized(fs.RoleAssistant, 14),
+					sized(fs.RoleUser, 14), sized(fs.RoleAssistant, 14),
+				},
+				Summarized: 3,
+				Summary:    "the first turn",
+			}
+			messages := append(append(conv.Prompt(), tt.extra...), sized(fs.RoleUser, 14))
+			est := NewTokenEstimator()
+			fixed := est.EstimateMessages("m", messages[:2]) + 14
+			plan := PlanContext(est, "m", ContextBudget{Window: fixed + 200 + tt.free, Reserve: 200}, messages)
+			if plan.Summarize != tt.summarize {
+				t.Fatalf("Summarize = %d, want %d", plan.Summarize, tt.summarize)
+			}
+
+			n := plan.Summarize
+			if unsummarized := conv.Unsummarized(); n > unsummarized {
+				n = unsummarized
+			}
+			conv.Summarize("the first turns", n)
+			rest := conv.Prompt()[2:]
+			history := plan.Messages[2 : len(plan.Messages)-1]
+			if len(rest) != tt.kept || len(history) != tt.planned {
+				t.Fatalf("kept %d conversation messages and planned %d, want %d and %d", len(rest), len(history), tt.kept, tt.planned)
+			}
+			for i := range rest {
+				if rest[i].Role != history[i].Role || rest[i].Content != history[i].Content {
+					t.Errorf("message %d left in the conversation isn't the one planned", i)
+				}
+			}
+		})
+	}
+}
+
+func TestTrimToTokens(t *testing.T) {
+	est := NewTokenEstimator()
+	long := strings.Repeat("é", 400)
+	tests := []struct {
+		name   string
+		text   string
+		tokens int
+		want   func(string) error
+	}{
+		{"fits", "short", 20, func(s string) error {
+			if s != "short" {
+				return fmt.Errorf("changed to %q", s)
+			}
+			return nil
+		}},
+		{"no room for the marker", long, 5, func(s string) error {
+			if s != "[omitted to fit the context window]" {
+				return fmt.Errorf("= %q, want it omitted", s)
+			}
+			return nil
+		}},
+		{"cut on a rune boundary", long, 20, func(s string) error {
+			kept, ok := strings.CutSuffix(s, "\n[trimmed to fit the context window]")
+			if !ok || !utf8.ValidString(kept) || !strings.HasPrefix(long, kept) {
+				return fmt.Errorf("= %q, want a prefix of the text and the marker", s)
+			}
+			if got := est.Estimate("m", kept); got > 20 {
+				return fmt.Errorf("kept %d tokens, want at most 20", got)
+			}
+			return nil
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.want(trimToTokens(est, "m", tt.text, tt.tokens)); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+)
+
+// Backoff before the first retry; it doubles with every further attempt
+var (
+	retryBackoff    = 500 * time.Millisecond
+	maxRetryBackoff = 8 * time.Second
+)
+
+// Endpoint is one backend a FailoverProvider can send requests to
+type Endpoint struct {
+	Name     string // Profile name, recorded with the answer
+	Provider ChatProvider
+	Model    string        // Model to ask for, or "" for the requested one
+	Timeout  time.Duration // Limit for connecting and receiving the first token, or 0 for none
+	Retries  int           // Extra attempts for failures before the first token
+}
+
+// FailoverProvider tries a list of endpoints in order. Requests that fail
+// before the first token are retried with exponential backoff, then handed
+// to the next endpoint. Once tokens have been passed on, errors are returned
+// as they are, since the answer can't be taken back.
+type FailoverProvider struct {
+	endpoints []Endpoint
+}
+
+// NewFailoverProvider creates a provider trying the endpoints in order
+func NewFailoverProvider(endpoints ...Endpoint) *FailoverProvider {
+	return &FailoverProvider{endpoints: endpoints}
+}
+
+// NewProfileProvider creates the chat backend for a profile together with
+// the fallbacks it names, applying each profile's timeout and retries.
+//
+// Parameters:
+//   - name: The name of the profile, recorded with answers
+//   - p: The profile
+//
+// Returns:
+//   - *FailoverProvider: The backend
+//   - error: An error if a profile is invalid or a fallback is unknown
+func NewProfileProvider(name string, p config.Profile) (*FailoverProvider, error) {
+	primary, err := profileEndpoint(name, p)
+	if err != nil {
+		return nil, err
+	}
+	endpoints := []Endpoint{primary}
+	if len(p.Fallbacks) == 0 {
+		return NewFailoverProvider(endpoints...), nil
+	}
+
+	profiles, err := config.Profiles()
+	if err != nil {
+		return nil, err
+	}
+	for _, fallback := range p.Fallbacks {
+		fp, ok := profiles[fallback]
+		if !ok {
+			return nil, fmt.Errorf("unknown fallback profile %q", fallback)
+		}
+		e, err := profileEndpoint(fallback, fp)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, e)
+	}
+	return NewFailoverProvider(endpoints...), nil
+}
+
+// profileEndpoint creates the endpoint for a single profile
+func profileEndpoint(name string, p config.Profile) (Endpoint, error) {
+	if err := p.Validate(); err != nil {
+		return Endpoint{}, fmt.Errorf("invalid profile %q: %w", name, err)
+	}
+	provider, err := NewProvider(p)
+	if err != nil {
+		return Endpoint{}, fmt.Errorf("invalid profile %q: %w", name, err)
+	}
+	return Endpoint{
+		Name:     name,
+		Provider: provider,
+		Model:    p.Model,
+		Timeout:  time.Duration(p.Timeout) * time.Second,
+		Retries:  p.Retries,
+	}, nil
+}
+
+// Name identifies the kind of the first endpoint
+func (p *FailoverProvider) Name() string {
+	if len(p.endpoints) == 0 {
+		return "failover"
+	}
+	return p.endpoints[0].Provider.Name()
+}
+
+// ChatStream sends the request to the first endpoint that answers. The
+// response's Endpoint names the one that did.
+func (p *FailoverProvider) ChatStream(ctx context.Context, req ChatRequest, onToken func(string) error) (*ChatResponse, error) {
+	if len(p.endpoints) == 0 {
+		return nil, errors.New("no chat endpoints configured")
+	}
+
+	var errs []error
+	for _, e := range p.endpoints {
+		resp, started, err := p.tryEndpoint(ctx, e, req, onToken)
+		if err == nil {
+			resp.Endpoint = e.Name
+			return resp, nil
+		}
+		if started || ctx.Err() != nil {
+			return nil, err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", e.Name, err))
+	}
+	if len(errs) == 1 {
+		return nil, errs[0]
+	}
+	return nil, fmt.Errorf("all endpoints failed: %w", errors.Join(errs...))
+}
+
+// tryEndpoint sends the request to one endpoint, retrying transient failures
+// that happen before the first token. started reports whether any tokens were
+// passed on.
+func (p *FailoverProvider) tryEndpoint(ctx context.Context, e Endpoint, req ChatRequest, onToken func(string) error) (resp *ChatResponse, started bool, err error) {
+	if e.Model != "" {
+		req.Model = e.Model
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err = p.attempt(ctx, e, req, func(s string) error {
+			started = true
+			return onToken(s)
+		})
+		if err == nil || started || ctx.Err() != nil {
+			return resp, started, err
+		}
+		if attempt >= e.Retries || !retryable(err) {
+			return nil, false, err
+		}
+
+		backoff := retryBackoff << attempt
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+}
+
+// attempt sends the request once. The endpoint's timeout covers connecting
+// and waiting for the first token; once the answer is streaming it may take
+// as long as it needs.
+func (p *FailoverProvider) attempt(ctx context.Context, e Endpoint, req ChatRequest, onToken func(string) error) (*ChatResponse, error) {
+	if e.Timeout <= 0 {
+		return e.Provider.ChatStream(ctx, req, onToken)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var mu sync.Mutex
+	started := false
+	timer := time.AfterFunc(e.Timeout, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if !started {
+			cancel(context.DeadlineExceeded)
+		}
+	})
+	defer timer.Stop()
+
+	resp, err := e.Provider.ChatStream(ctx, req, func(s string) error {
+		mu.Lock()
+		started = true
+		mu.Unlock()
+		return onToken(s)
+	})
+	if err != nil && errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("no answer within %s: %w", e.Timeout, context.DeadlineExceeded)
+	}
+	return resp, err
+}
+
+// ListModels returns the models of the first endpoint
+func (p *FailoverProvider) ListModels(ctx context.Context) ([]string, error) {
+	if len(p.endpoints) == 0 {
+		return nil, errors.New("no chat endpoints configured")
+	}
+	return p.endpoints[0].Provider.ListModels(ctx)
+}
+
+// ModelInfos describes the models of the first endpoint
+func (p *FailoverProvider) ModelInfos(ctx context.Context) ([]ModelInfo, error) {
+	if len(p.endpoints) == 0 {
+		return nil, errors.New("no chat endpoints configured")
+	}
+	return ListModelInfos(ctx, p.endpoints[0].Provider)
+}
+
+// PullModel downloads a model on the first endpoint
+func (p *FailoverProvider) PullModel(ctx context.Context, name string, onProgress func(PullProgress)) error {
+	if len(p.endpoints) == 0 {
+		return errors.New("no chat endpoints configured")
+	}
+	return PullModel(ctx, p.endpoints[0].Provider, name, onProgress)
+}
+
+// retryable reports whether an error is worth retrying: network failures,
+// timeouts, answers cut off, rate limits and server errors
+func retryable(err error) bool {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.StatusCode == http.StatusTooManyRequests || status.StatusCode >= 500
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,264 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+)
+
+// slowProvider waits before its first token and between the others
+type slowProvider struct {
+	first, between time.Duration
+	tokens         int
+}
+
+func (p *slowProvider) Name() string { return "slow" }
+
+func (p *slowProvider) ListModels(ctx context.Context) ([]string, error) { return nil, nil }
+
+func (p *slowProvider) ChatStream(ctx context.Context, req ChatRequest, onToken func(string) error) (*ChatResponse, error) {
+	wait := p.first
+	for i := 0; i < p.tokens; i++ {
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if err := onToken("x"); err != nil {
+			return nil, err
+		}
+		wait = p.between
+	}
+	return &ChatResponse{Content: "done"}, nil
+}
+
+func TestFailoverTimeoutCoversFirstTokenOnly(t *testing.T) {
+	// The whole answer takes well over the timeout, but its first token doesn't
+	p := NewFailoverProvider(Endpoint{
+		Name:     "slow",
+		Provider: &slowProvider{first: 10 * time.Millisecond, between: 20 * time.Millisecond, tokens: 10},
+		Timeout:  50 * time.Millisecond,
+	})
+	resp, err := p.ChatStream(context.Background(), ChatRequest{}, func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("a streaming answer was cut off: %v", err)
+	}
+	if resp.Content != "done" {
+		t.Errorf("Content = %q, want done", resp.Content)
+	}
+}
+
+func TestFailoverTimeoutBeforeFirstToken(t *testing.T) {
+	p := NewFailoverProvider(Endpoint{
+		Name:     "stuck",
+		Provider: &slowProvider{first: time.Minute, tokens: 1},
+		Timeout:  20 * time.Millisecond,
+	})
+	start := time.Now()
+	_, err := p.ChatStream(context.Background(), ChatRequest{}, func(string) error { return nil })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want a timeout", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("the timeout took %s to fire", time.Since(start))
+	}
+}
+
+// flakyProvider fails its first attempts with the given errors, then answers.
+// With tokens set it sends them before failing.
+type flakyProvider struct {
+	errs     []error
+	tokens   []string
+	attempts []time.Time // When each attempt was made
+	models   []string    // The model each attempt asked for
+}
+
+func (p *flakyProvider) Name() string { return "flaky" }
+
+func (p *flakyProvider) ListModels(ctx context.Context) ([]string, error) { return nil, nil }
+
+func (p *flakyProvider) ChatStream(ctx context.Context, req ChatRequest, onToken func(string) error) (*ChatResponse, error) {
+	p.attempts = append(p.attempts, time.Now())
+	p.models = append(p.models, req.Model)
+	for _, tok := range p.tokens {
+		if err := onToken(tok); err != nil {
+			return nil, err
+		}
+	}
+	if n := len(p.attempts); n <= len(p.errs) {
+		return nil, p.errs[n-1]
+	}
+	return &ChatResponse{Model: req.Model, Content: "answer"}, nil
+}
+
+// fastBackoff shortens the retry backoff for the duration of a test
+func fastBackoff(t *testing.T) {
+	t.Helper()
+	backoff, limit := retryBackoff, maxRetryBackoff
+	retryBackoff, maxRetryBackoff = 10*time.Millisecond, 25*time.Millisecond
+	t.Cleanup(func() { retryBackoff, maxRetryBackoff = backoff, limit })
+}
+
+func TestFailoverRetries(t *testing.T) {
+	fastBackoff(t)
+	tests := []struct {
+		name     string
+		err      error
+		attempts int // Attempts made with 3 retries, the last one answering if it retried at all
+	}{
+		{"server error", &StatusError{Provider: "flaky", StatusCode: http.StatusBadGateway}, 4},
+		{"rate limit", &StatusError{Provider: "flaky", StatusCode: http.StatusTooManyRequests}, 4},
+		{"cut off", fmt.Errorf("stream ended: %w", io.ErrUnexpectedEOF), 4},
+		{"client error", &StatusError{Provider: "flaky", StatusCode: http.StatusBadRequest}, 1},
+		{"not found", &StatusError{Provider: "flaky", StatusCode: http.StatusNotFound}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &flakyProvider{errs: []error{tt.err, tt.err, tt.err}}
+			p := NewFailoverProvider(Endpoint{Name: "flaky", Provider: provider, Retries: 3})
+			resp, err := p.ChatStream(context.Background(), ChatRequest{}, func(string) error { return nil })
+			if len(provider.attempts) != tt.attempts {
+				t.Errorf("made %d attempts, want %d", len(provider.attempts), tt.attempts)
+			}
+			if tt.attempts == 1 {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("err = %v, want the first attempt's", err)
+				}
+				return
+			}
+			if err != nil || resp.Content != "answer" {
+				t.Errorf("ChatStream = %+v, %v, want the answer after the retries", resp, err)
+			}
+		})
+	}
+}
+
+func TestFailoverBackoff(t *testing.T) {
+	fastBackoff(t)
+	busy := &StatusError{Provider: "flaky", StatusCode: http.StatusServiceUnavailable}
+	provider := &flakyProvider{errs: []error{busy, busy, busy}}
+	p := NewFailoverProvider(Endpoint{Name: "flaky", Provider: provider, Retries: 3})
+	if _, err := p.ChatStream(context.Background(), ChatRequest{}, func(string) error { return nil }); err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+
+	// 10ms, doubled to 20ms, then held at the 25ms limit
+	for i, want := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 25 * time.Millisecond} {
+		if gap := provider.attempts[i+1].Sub(provider.attempts[i]); gap < want {
+			t.Errorf("retry %d came after %s, want at least %s", i+1, gap, want)
+		}
+	}
+}
+
+func TestFailoverNextEndpoint(t *testing.T) {
+	fastBackoff(t)
+	down := &StatusError{Provider: "flaky", StatusCode: http.StatusInternalServerError}
+	first := &flakyProvider{errs: []error{down, down}}
+	second := &flakyProvider{}
+	p := NewFailoverProvider(
+		Endpoint{Name: "gpu", Provider: first, Retries: 1},
+		Endpoint{Name: "laptop", Provider: second, Model: "llama3.2:3b"},
+	)
+
+	resp, err := p.ChatStream(context.Background(), ChatRequest{Model: "llama3.1:70b"}, func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	if len(first.attempts) != 2 || len(second.attempts) != 1 {
+		t.Errorf("attempts = %d, %d, want 2 on the first endpoint and 1 on the second", len(first.attempts), len(second.attempts))
+	}
+	if resp.Endpoint != "laptop" {
+		t.Errorf("Endpoint = %q, want laptop", resp.Endpoint)
+	}
+	if first.models[0] != "llama3.1:70b" || second.models[0] != "llama3.2:3b" {
+		t.Errorf("models = %v, %v, want the requested one, then the fallback's own", first.models, second.models)
+	}
+}
+
+func TestFailoverAllEndpointsFail(t *testing.T) {
+	p := NewFailoverProvider(
+		Endpoint{Name: "a", Provider: &flakyProvider{errs: []error{errors.New("refused")}}},
+		Endpoint{Name: "b", Provider: &flakyProvider{errs: []error{errors.New("reset")}}},
+	)
+	_, err := p.ChatStream(context.Background(), ChatRequest{}, func(string) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "a: refused") || !strings.Contains(err.Error(), "b: reset") {
+		t.Errorf("err = %v, want both endpoints' errors", err)
+	}
+}
+
+func TestFailoverNotAfterTokens(t *testing.T) {
+	fastBackoff(t)
+	cut := fmt.Errorf("stream ended: %w", io.ErrUnexpectedEOF)
+	first := &flakyProvider{errs: []error{cut}, tokens: []string{"Hel"}}
+	second := &flakyProvider{}
+	p := NewFailoverProvider(
+		Endpoint{Name: "gpu", Provider: first, Retries: 2},
+		Endpoint{Name: "laptop", Provider: second},
+	)
+
+	var tokens []string
+	_, err := p.ChatStream(context.Background(), ChatRequest{}, func(s string) error {
+		tokens = append(tokens, s)
+		return nil
+	})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("err = %v, want the error after the first tokens", err)
+	}
+	if len(first.attempts) != 1 || len(second.attempts) != 0 {
+		t.Errorf("attempts = %d, %d, want no retry and no failover once tokens were sent", len(first.attempts), len(second.attempts))
+	}
+	if strings.Join(tokens, "") != "Hel" {
+		t.Errorf("tokens = %q, want only the first endpoint's", tokens)
+	}
+}
+
+func TestFailoverEndpointOfPrimary(t *testing.T) {
+	p := NewFailoverProvider(Endpoint{Name: "gpu", Provider: &flakyProvider{}}, Endpoint{Name: "laptop", Provider: &flakyProvider{}})
+	resp, err := p.ChatStream(context.Background(), ChatRequest{}, func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	if resp.Endpoint != "gpu" {
+		t.Errorf("Endpoint = %q, want gpu", resp.Endpoint)
+	}
+}
+
+func TestProfileFallbackGetsDefaultURL(t *testing.T) {
+	// The fallback sets no api_url, so it must use the default Ollama server
+	srv := streamServer(t, "application/x-ndjson", []string{
+		`{"model":"llama3.2:3b","message":{"role":"assistant","content":"hi"},"done":true}`,
+	})
+	t.Setenv("OLLAMA_API_URL", srv.URL)
+	vault := t.TempDir()
+	t.Setenv("ASKAI_VAULT", vault)
+	cfg := `{"profiles": {"laptop": {"provider": "ollama", "model": "llama3.2:3b"}}}`
+	if err := os.WriteFile(filepath.Join(vault, "config.json"), []byte(cfg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer down.Close()
+	p, err := NewProfileProvider("gpu", config.Profile{Provider: "ollama", APIURL: down.URL, Fallbacks: []string{"laptop"}})
+	if err != nil {
+		t.Fatalf("NewProfileProvider: %v", err)
+	}
+	resp, err := p.ChatStream(context.Background(), ChatRequest{Model: "llama3.1"}, func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	if resp.Endpoint != "laptop" || resp.Content != "hi" {
+		t.Errorf("ChatStream = %q from %q, want the fallback's answer", resp.Content, resp.Endpoint)
+	}
+}
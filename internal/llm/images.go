@@ -0,0 +1,25 @@
+package llm
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// encodeImages reads the images attached to a message from the vault and
+// returns them base64-encoded, as vision models expect them
+func encodeImages(vaultPath string, refs []string) ([]string, error) {
+	if len(refs) > 0 && vaultPath == "" {
+		return nil, fmt.Errorf("image %s: the request names no vault to load it from", refs[0])
+	}
+	var images []string
+	for _, ref := range refs {
+		data, err := fs.LoadAttachment(vaultPath, ref)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, base64.StdEncoding.EncodeToString(data))
+	}
+	return images, nil
+}
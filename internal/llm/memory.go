@@ -0,0 +1,131 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// MemoryPrefix opens the system message that carries recalled memories
+const MemoryPrefix = "Things you remember about the user from earlier conversations. Rely on them where relevant, without mentioning that you remember them:\n"
+
+// ExtractedMemory is a fact or preference the model found in a transcript
+type ExtractedMemory struct {
+	Text  string `json:"text"`
+	Kind  string `json:"kind"`  // fs.MemoryFact or fs.MemoryPreference
+	Turns []int  `json:"turns"` // The numbers of the turns it came from, counted from 1
+}
+
+// memorySchema constrains what ExtractMemories asks for
+var memorySchema = mustParseSchema(`{
+	"type": "object",
+	"properties": {
+		"memories": {
+			"type": "array",
+			"items": {
+				"type": "object",
+				"properties": {
+					"text": {"type": "string", "minLength": 1},
+					"kind": {"enum": ["fact", "preference"]},
+					"turns": {"type": "array", "items": {"type": "integer", "minimum": 1}}
+				},
+				"required": ["text", "kind", "turns"]
+			}
+		}
+	},
+	"required": ["memories"]
+}`)
+
+// mustParseSchema parses a schema that is part of the program
+func mustParseSchema(s string) *Schema {
+	schema, err := ParseSchema([]byte(s))
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// extractPrompt asks the model to distill durable memories from numbered turns
+const extractPrompt = `Below are numbered turns of a conversation between a user and an assistant. Extract durable facts about the user's world (e.g. "The team uses Go 1.25", "Services are deployed on Kubernetes") and lasting preferences (e.g. "Prefers table-driven tests") that will still matter in future conversations.
+
+Leave out anything only relevant to the question at hand, general knowledge, and what the assistant said unless the user confirmed it. Write each memory as one short, self-contained sentence in the third person, and list the turns it came from. Return an empty list if there is nothing worth remembering.
+
+%s`
+
+// ExtractMemories asks the model for the durable facts and preferences in a
+// list of question and answer turns
+//
+// Parameters:
+//   - ctx: Cancels the request
+//   - provider: The backend to ask
+//   - req: The model and options to ask with; its messages are replaced
+//   - turns: The turns to distill, as alternating user and assistant messages
+//
+// Returns:
+//   - []ExtractedMemory: The memories found, with turn numbers within range
+//   - error: An error if the request fails or the answer can't be parsed
+func ExtractMemories(ctx context.Context, provider ChatProvider, req ChatRequest, turns []fs.Message) ([]ExtractedMemory, error) {
+	var transcript strings.Builder
+	n := 0
+	for _, msg := range turns {
+		switch msg.Role {
+		case fs.RoleUser:
+			n++
+			fmt.Fprintf(&transcript, "[%d] User: %s\n", n, msg.Content)
+		case fs.RoleAssistant:
+			fmt.Fprintf(&transcript, "[%d] Assistant: %s\n\n", n, msg.Content)
+		}
+	}
+
+	req.Messages = []fs.Message{{Role: fs.RoleUser, Content: fmt.Sprintf(extractPrompt, transcript.String())}}
+	req.Tools = nil
+	out, _, err := AskStructured(ctx, provider, req, memorySchema, DefaultRepairs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract memories: %w", err)
+	}
+
+	var result struct {
+		Memories []ExtractedMemory `json:"memories"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse memories: %w", err)
+	}
+	for i := range result.Memories {
+		mem := &result.Memories[i]
+		mem.Text = strings.TrimSpace(mem.Text)
+		valid := mem.Turns[:0]
+		for _, t := range mem.Turns {
+			if t >= 1 && t <= n {
+				valid = append(valid, t)
+			}
+		}
+		mem.Turns = valid
+	}
+	return result.Memories, nil
+}
+
+// WithMemories adds recalled memories to a prompt as a system message after
+// the prompt's leading system messages. Without memories the prompt is
+// returned as it is.
+func WithMemories(messages []fs.Message, memories []string) []fs.Message {
+	if len(memories) == 0 {
+		return messages
+	}
+	var sb strings.Builder
+	sb.WriteString(MemoryPrefix)
+	for _, mem := range memories {
+		sb.WriteString("- " + mem + "\n")
+	}
+
+	n := 0
+	for n < len(messages)-1 && messages[n].Role == fs.RoleSystem {
+		n++
+	}
+	out := make([]fs.Message, 0, len(messages)+1)
+	out = append(out, messages[:n]...)
+	out = append(out, fs.Message{Role: fs.RoleSystem, Content: strings.TrimSpace(sb.String())})
+	return append(out, messages[n:]...)
+}
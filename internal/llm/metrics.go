@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// Stream runs a chat stream on the provider and completes the response's
+// metrics with the time to first token, the total time and, if the server
+// didn't report it, the throughput measured on the client. The model's
+// thinking, if any, is left out of the answer; see StreamThinking.
+//
+// Parameters:
+//   - ctx: Cancels the request
+//   - provider: The backend to stream from
+//   - req: The chat request
+//   - onToken: Called with every chunk of the answer
+//
+// Returns:
+//   - *ChatResponse: The full answer and its metrics
+//   - error: An error if the request fails or onToken aborts it
+func Stream(ctx context.Context, provider ChatProvider, req ChatRequest, onToken func(string) error) (*ChatResponse, error) {
+	return StreamThinking(ctx, provider, req, onToken, nil)
+}
+
+// StreamThinking runs a chat stream like Stream, passing the model's
+// thinking to onThinking as it arrives. Thinking is what reasoning models
+// write in <think> tags or what the backend returns separately; it is
+// removed from the response's Content and returned in its Thinking.
+//
+// Parameters:
+//   - ctx: Cancels the request
+//   - provider: The backend to stream from
+//   - req: The chat request
+//   - onToken: Called with every chunk of the answer
+//   - onThinking: Called with every chunk of thinking; may be nil
+//
+// Returns:
+//   - *ChatResponse: The full answer, its thinking and its metrics
+//   - error: An error if the request fails or a callback aborts it
+func StreamThinking(ctx context.Context, provider ChatProvider, req ChatRequest, onToken, onThinking func(string) error) (*ChatResponse, error) {
+	start := time.Now()
+	var first time.Time
+	var split thinkSplitter
+
+	pass := func(answer, thinking string) error {
+		if thinking != "" && onThinking != nil {
+			if err := onThinking(thinking); err != nil {
+				return err
+			}
+		}
+		if answer != "" {
+			return onToken(answer)
+		}
+		return nil
+	}
+
+	resp, err := provider.ChatStream(ctx, req, func(s string) error {
+		if first.IsZero() {
+			first = time.Now()
+		}
+		return pass(split.feed(s))
+	})
+	if err == nil {
+		err = pass(split.flush())
+	}
+	if err != nil {
+		return nil, err
+	}
+	resp.Content, resp.Thinking = SplitThinking(resp.Content)
+
+	total := time.Since(start)
+	m := &resp.Metrics
+	m.TotalMillis = total.Milliseconds()
+	if !first.IsZero() {
+		m.TTFTMillis = first.Sub(start).Milliseconds()
+		if generating := total - first.Sub(start); m.TokensPerSecond == 0 && m.CompletionTokens > 0 && generating > 0 {
+			m.TokensPerSecond = float64(m.CompletionTokens) / generating.Seconds()
+		}
+	}
+	return resp, nil
+}
+
+// FormatMetrics renders metrics for the status bar, e.g.
+// "42.1 tok/s · first token 0.35s · total 4.20s · 57→176 tokens"
+func FormatMetrics(m fs.Metrics) string {
+	var parts []string
+	if m.TokensPerSecond > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f tok/s", m.TokensPerSecond))
+	}
+	parts = append(parts,
+		fmt.Sprintf("first token %.2fs", float64(m.TTFTMillis)/1000),
+		fmt.Sprintf("total %.2fs", float64(m.TotalMillis)/1000),
+	)
+	if m.PromptTokens > 0 || m.CompletionTokens > 0 {
+		parts = append(parts, fmt.Sprintf("%d→%d tokens", m.PromptTokens, m.CompletionTokens))
+	}
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ModelInfo describes a model a backend can serve
+type ModelInfo struct {
+	Name          string
+	Size          int64  // Bytes on disk, or 0 if unknown
+	Family        string // e.g. "llama"
+	ParameterSize string // e.g. "8.0B"
+	Quantization  string // e.g. "Q4_0"
+}
+
+// PullProgress reports how far a model download has got
+type PullProgress struct {
+	Status    string // e.g. "pulling manifest" or "success"
+	Completed int64  // Bytes of the current layer downloaded so far
+	Total     int64  // Size of the current layer, or 0 if unknown
+}
+
+// ModelManager is implemented by providers that can describe their models in
+// detail and download new ones
+type ModelManager interface {
+	// ModelInfos returns the installed models
+	ModelInfos(ctx context.Context) ([]ModelInfo, error)
+	// PullModel downloads a model, calling onProgress as the download advances
+	PullModel(ctx context.Context, name string, onProgress func(PullProgress)) error
+}
+
+// ListModelInfos returns the models of a provider, with details if it can
+// report them and just the names otherwise
+func ListModelInfos(ctx context.Context, p ChatProvider) ([]ModelInfo, error) {
+	if mm, ok := p.(ModelManager); ok {
+		return mm.ModelInfos(ctx)
+	}
+
+	names, err := p.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]ModelInfo, len(names))
+	for i, name := range names {
+		infos[i] = ModelInfo{Name: name}
+	}
+	return infos, nil
+}
+
+// SameModel reports whether two model names refer to the same model,
+// ignoring case and Ollama's implied ":latest" tag, so "llama3.1" and
+// "llama3.1:latest" match
+func SameModel(a, b string) bool {
+	normalize := func(name string) string {
+		return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ":latest")
+	}
+	return normalize(a) == normalize(b)
+}
+
+// PullModel downloads a model through a provider that supports it
+func PullModel(ctx context.Context, p ChatProvider, name string, onProgress func(PullProgress)) error {
+	mm, ok := p.(ModelManager)
+	if !ok {
+		return fmt.Errorf("the %s backend can't download models", p.Name())
+	}
+	return mm.PullModel(ctx, name, onProgress)
+}
+
+// FormatSize renders a byte count with a binary unit, e.g. "4.3 GiB"
+func FormatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,24 @@
+package llm
+
+import "testing"
+
+func TestSameModel(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"llama3.1", "llama3.1", true},
+		{"llama3.1", "llama3.1:latest", true},
+		{"Llama3.1:Latest", "llama3.1", true},
+		{" gemma3:1b ", "gemma3:1b", true},
+		{"llama3", "llama3.1", false},
+		{"llama3.1", "llama3.1:8b", false},
+		{"gemma3:1b", "gemma3", false},
+		{"", "llama3.1", false},
+	}
+	for _, tt := range tests {
+		if got := SameModel(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameModel(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,377 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/VarunSharma3520/AskAI/internal/cassette"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/parakeet-nest/parakeet/enums/option"
+	pkllm "github.com/parakeet-nest/parakeet/llm"
+)
+
+// OllamaProvider talks to Ollama's native /api/chat endpoint
+type OllamaProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewOllamaProvider creates a provider for the Ollama server at baseURL.
+// A nil client uses cassette.Client, which is http.DefaultClient unless a
+// cassette is being recorded or replayed.
+func NewOllamaProvider(baseURL string, client *http.Client) *OllamaProvider {
+	if client == nil {
+		client = cassette.Client()
+	}
+	return &OllamaProvider{baseURL: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+// ollamaMessage is a chat message in Ollama's wire format
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Thinking  string           `json:"thinking,omitempty"` // Reasoning, when Ollama separates it
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+	Images    []string         `json:"images,omitempty"` // Base64-encoded images for vision models
+}
+
+// ollamaToolCall is a tool call in Ollama's wire format
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+// ollamaTool describes a tool the model may call
+type ollamaTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string          `json:"name"`
+		Description string          `json:"description"`
+		Parameters  json.RawMessage `json:"parameters"`
+	} `json:"function"`
+}
+
+// ollamaChatRequest is the body of a POST /api/chat
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Tools    []ollamaTool    `json:"tools,omitempty"`
+	Format   json.RawMessage `json:"format,omitempty"`
+	Options  pkllm.Options   `json:"options"`
+	Stream   bool            `json:"stream"`
+}
+
+// ollamaChatChunk is one line of a streamed /api/chat response
+type ollamaChatChunk struct {
+	Model   string        `json:"model"`
+	Message ollamaMessage `json:"message"`
+	Done    bool          `json:"done"`
+	Error   string        `json:"error"`
+
+	// Statistics, only present on the final chunk
+	PromptEvalCount int   `json:"prompt_eval_count"`
+	EvalCount       int   `json:"eval_count"`
+	EvalDuration    int64 `json:"eval_duration"` // Nanoseconds spent generating the answer
+}
+
+// Name identifies the provider
+func (p *OllamaProvider) Name() string {
+	return "ollama"
+}
+
+// ChatStream streams a chat completion from /api/chat, which answers with one
+// JSON object per line
+func (p *OllamaProvider) ChatStream(ctx context.Context, req ChatRequest, onToken func(string) error) (*ChatResponse, error) {
+	body := ollamaChatRequest{
+		Model:   req.Model,
+		Stream:  true,
+		Options: ollamaOptions(req.Options),
+		Format:  req.Format,
+	}
+	for _, msg := range req.Messages {
+		out, err := toOllamaMessage(msg, req.VaultPath)
+		if err != nil {
+			return nil, err
+		}
+		body.Messages = append(body.Messages, out)
+	}
+	for _, spec := range req.Tools {
+		tool := ollamaTool{Type: "function"}
+		tool.Function.Name = spec.Name
+		tool.Function.Description = spec.Description
+		tool.Function.Parameters = spec.Parameters
+		body.Tools = append(body.Tools, tool)
+	}
+
+	resp, err := p.post(ctx, "/api/chat", body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &ChatResponse{Model: req.Model}
+	var content strings.Builder
+	var tagger thinkingTagger
+	done := false
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk ollamaChatChunk
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode ollama response: %w", err)
+		}
+		if chunk.Error != "" {
+			return nil, fmt.Errorf("ollama: %s", chunk.Error)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+
+		for _, tc := range chunk.Message.ToolCalls {
+			result.ToolCalls = append(result.ToolCalls, fs.ToolCall{Name: tc.Function.Name, Arguments: tc.Function.Arguments})
+		}
+		var s string
+		if chunk.Message.Thinking != "" {
+			s = tagger.thinking(chunk.Message.Thinking)
+		}
+		if chunk.Message.Content != "" {
+			s += tagger.answer(chunk.Message.Content)
+		}
+		if s != "" {
+			content.WriteString(s)
+			if err := onToken(s); err != nil {
+				return nil, err
+			}
+		}
+		if chunk.Done {
+			result.Metrics.PromptTokens = chunk.PromptEvalCount
+			result.Metrics.CompletionTokens = chunk.EvalCount
+			if chunk.EvalDuration > 0 {
+				result.Metrics.TokensPerSecond = float64(chunk.EvalCount) / (float64(chunk.EvalDuration) / 1e9)
+			}
+			done = true
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ollama response: %w", err)
+	}
+	if !done {
+		// The connection dropped or the server died mid-answer
+		return nil, fmt.Errorf("ollama response ended before the done chunk: %w", io.ErrUnexpectedEOF)
+	}
+
+	result.Content = content.String()
+	return result, nil
+}
+
+// toOllamaMessage converts a message, with any tool calls and images from
+// the vault, to Ollama's wire format
+func toOllamaMessage(msg fs.Message, vaultPath string) (ollamaMessage, error) {
+	out := ollamaMessage{Role: msg.Role, Content: msg.Content, ToolName: msg.ToolName}
+	images, err := encodeImages(vaultPath, msg.Images)
+	if err != nil {
+		return out, err
+	}
+	out.Images = images
+	for _, call := range msg.ToolCalls {
+		var tc ollamaToolCall
+		tc.Function.Name = call.Name
+		tc.Function.Arguments = call.Arguments
+		if len(tc.Function.Arguments) == 0 {
+			tc.Function.Arguments = json.RawMessage("{}")
+		}
+		out.ToolCalls = append(out.ToolCalls, tc)
+	}
+	return out, nil
+}
+
+// ollamaOptions converts generation options to Ollama's option set. Options
+// left at zero keep Ollama's defaults.
+func ollamaOptions(o Options) pkllm.Options {
+	values := map[string]interface{}{
+		string(option.Temperature): o.Temperature,
+	}
+	if o.MaxTokens > 0 {
+		values[string(option.NumPredict)] = o.MaxTokens
+	}
+	if o.TopP > 0 {
+		values[string(option.TopP)] = o.TopP
+	}
+	if o.TopK > 0 {
+		values[string(option.TopK)] = o.TopK
+	}
+	if o.NumCtx > 0 {
+		values[string(option.NumCtx)] = o.NumCtx
+	}
+	if o.Seed > 0 {
+		values[string(option.Seed)] = o.Seed
+	}
+
+	opts := pkllm.SetOptions(values)
+	// SetOptions has no case for stop sequences
+	opts.Stop = o.Stop
+	return opts
+}
+
+// ListModels returns the models installed on the Ollama server
+func (p *OllamaProvider) ListModels(ctx context.Context) ([]string, error) {
+	infos, err := p.ModelInfos(ctx)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(infos))
+	for _, m := range infos {
+		names = append(names, m.Name)
+	}
+	return names, nil
+}
+
+// ModelInfos returns the models installed on the Ollama server with their
+// size and family, as listed by /api/tags
+func (p *OllamaProvider) ModelInfos(ctx context.Context) ([]ModelInfo, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ollama models: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, readStatusError("ollama", resp)
+	}
+
+	var tags struct {
+		Models []struct {
+			Name    string `json:"name"`
+			Size    int64  `json:"size"`
+			Details struct {
+				Family            string `json:"family"`
+				ParameterSize     string `json:"parameter_size"`
+				QuantizationLevel string `json:"quantization_level"`
+			} `json:"details"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("failed to decode ollama models: %w", err)
+	}
+
+	infos := make([]ModelInfo, 0, len(tags.Models))
+	for _, m := range tags.Models {
+		infos = append(infos, ModelInfo{
+			Name:          m.Name,
+			Size:          m.Size,
+			Family:        m.Details.Family,
+			ParameterSize: m.Details.ParameterSize,
+			Quantization:  m.Details.QuantizationLevel,
+		})
+	}
+	return infos, nil
+}
+
+// PullModel downloads a model with /api/pull, which reports its progress as
+// one JSON object per line
+func (p *OllamaProvider) PullModel(ctx context.Context, name string, onProgress func(PullProgress)) error {
+	resp, err := p.post(ctx, "/api/pull", map[string]interface{}{"model": name, "stream": true})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk struct {
+			Status    string `json:"status"`
+			Total     int64  `json:"total"`
+			Completed int64  `json:"completed"`
+			Error     string `json:"error"`
+		}
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return fmt.Errorf("failed to decode ollama pull progress: %w", err)
+		}
+		if chunk.Error != "" {
+			return fmt.Errorf("ollama: %s", chunk.Error)
+		}
+		if onProgress != nil {
+			onProgress(PullProgress{Status: chunk.Status, Completed: chunk.Completed, Total: chunk.Total})
+		}
+		if chunk.Status == "success" {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read ollama pull progress: %w", err)
+	}
+	return fmt.Errorf("ollama: pull of %s ended without success", name)
+}
+
+// post sends a JSON body to an Ollama endpoint and checks the status
+func (p *OllamaProvider) post(ctx context.Context, path string, body interface{}) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("ollama request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, readStatusError("ollama", resp)
+	}
+	return resp, nil
+}
+
+// readStatusError builds a StatusError from an unsuccessful response,
+// preferring the message of a JSON error body
+func readStatusError(provider string, resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	msg := strings.TrimSpace(string(data))
+
+	var body struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(data, &body) == nil && len(body.Error) > 0 {
+		var s string
+		var obj struct {
+			Message string `json:"message"`
+		}
+		switch {
+		case json.Unmarshal(body.Error, &s) == nil:
+			msg = s
+		case json.Unmarshal(body.Error, &obj) == nil && obj.Message != "":
+			msg = obj.Message
+		}
+	}
+	return &StatusError{Provider: provider, StatusCode: resp.StatusCode, Message: msg}
+}
@@ -0,0 +1,289 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/VarunSharma3520/AskAI/internal/cassette"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// OpenAIProvider talks to any server exposing the OpenAI-compatible
+// /v1/chat/completions endpoint, such as llama.cpp, vLLM or LM Studio
+type OpenAIProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewOpenAIProvider creates a provider for the server at baseURL, with or
+// without the trailing /v1. The API key may be empty for local servers.
+// A nil client uses cassette.Client.
+func NewOpenAIProvider(baseURL, apiKey string, client *http.Client) *OpenAIProvider {
+	if client == nil {
+		client = cassette.Client()
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if !strings.HasSuffix(baseURL, "/v1") {
+		baseURL += "/v1"
+	}
+	return &OpenAIProvider{baseURL: baseURL, apiKey: apiKey, client: client}
+}
+
+// openAIMessage is a chat message in the OpenAI wire format. Content is a
+// string, or a list of openAIContentParts for a message with images.
+type openAIMessage struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"`
+}
+
+// openAIContentPart is the text or an image of a message with images
+type openAIContentPart struct {
+	Type     string          `json:"type"` // "text" or "image_url"
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+// openAIImageURL points to an image; AskAI sends images inline as data URLs
+type openAIImageURL struct {
+	URL string `json:"url"`
+}
+
+// openAIChatRequest is the body of a POST /v1/chat/completions
+type openAIChatRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Temperature float64         `json:"temperature"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+	TopP        float64         `json:"top_p,omitempty"`
+	TopK        int             `json:"top_k,omitempty"` // Not part of the OpenAI API, but accepted by llama.cpp and vLLM
+	Seed        *int            `json:"seed,omitempty"`
+	Stop        []string        `json:"stop,omitempty"`
+	Stream      bool            `json:"stream"`
+	// ResponseFormat constrains the answer to a JSON Schema
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+	// StreamOptions asks for a final chunk carrying token usage
+	StreamOptions struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options"`
+}
+
+// openAIResponseFormat asks for an answer following a JSON Schema
+type openAIResponseFormat struct {
+	Type       string `json:"type"` // "json_schema"
+	JSONSchema struct {
+		Name   string          `json:"name"`
+		Schema json.RawMessage `json:"schema"`
+	} `json:"json_schema"`
+}
+
+// openAIChatChunk is the data of one server-sent event of a streamed completion
+type openAIChatChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+			// ReasoningContent is the thinking of reasoning models, on servers that separate it
+			ReasoningContent string `json:"reasoning_content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Name identifies the provider
+func (p *OpenAIProvider) Name() string {
+	return "openai"
+}
+
+// ChatStream streams a chat completion, which the server sends as
+// server-sent events terminated by "data: [DONE]"
+func (p *OpenAIProvider) ChatStream(ctx context.Context, req ChatRequest, onToken func(string) error) (*ChatResponse, error) {
+	if len(req.Tools) > 0 {
+		return nil, errors.New("tool calling is only supported with the ollama backend")
+	}
+
+	body := openAIChatRequest{
+		Model:       req.Model,
+		Temperature: req.Options.Temperature,
+		MaxTokens:   req.Options.MaxTokens,
+		TopP:        req.Options.TopP,
+		TopK:        req.Options.TopK,
+		Stop:        req.Options.Stop,
+		Stream:      true,
+	}
+	if req.Options.Seed > 0 {
+		body.Seed = &req.Options.Seed
+	}
+	body.StreamOptions.IncludeUsage = true
+	if len(req.Format) > 0 {
+		body.ResponseFormat = &openAIResponseFormat{Type: "json_schema"}
+		body.ResponseFormat.JSONSchema.Name = "response"
+		body.ResponseFormat.JSONSchema.Schema = req.Format
+	}
+	for _, msg := range req.Messages {
+		// Tool turns from a conversation held with another backend are left out
+		if msg.Role == fs.RoleTool || (len(msg.ToolCalls) > 0 && msg.Content == "") {
+			continue
+		}
+		out, err := toOpenAIMessage(msg, req.VaultPath)
+		if err != nil {
+			return nil, err
+		}
+		body.Messages = append(body.Messages, out)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+	p.authorize(httpReq)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("openai request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, readStatusError("openai", resp)
+	}
+
+	result := &ChatResponse{Model: req.Model}
+	var content strings.Builder
+	var tagger thinkingTagger
+	done := false
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Only data fields carry chunks; comments and other fields are ignored
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if payload == "[DONE]" {
+			done = true
+			break
+		}
+
+		var chunk openAIChatChunk
+		if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode openai response: %w", err)
+		}
+		if chunk.Error != nil {
+			return nil, fmt.Errorf("openai: %s", chunk.Error.Message)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			result.Metrics.PromptTokens = chunk.Usage.PromptTokens
+			result.Metrics.CompletionTokens = chunk.Usage.CompletionTokens
+		}
+
+		for _, choice := range chunk.Choices {
+			var s string
+			if choice.Delta.ReasoningContent != "" {
+				s = tagger.thinking(choice.Delta.ReasoningContent)
+			}
+			if choice.Delta.Content != "" {
+				s += tagger.answer(choice.Delta.Content)
+			}
+			if s != "" {
+				content.WriteString(s)
+				if err := onToken(s); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read openai response: %w", err)
+	}
+	if !done {
+		// The connection dropped or the server died mid-answer
+		return nil, fmt.Errorf("openai response ended before [DONE]: %w", io.ErrUnexpectedEOF)
+	}
+
+	result.Content = content.String()
+	return result, nil
+}
+
+// ListModels returns the models served at /v1/models
+func (p *OpenAIProvider) ListModels(ctx context.Context) ([]string, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	p.authorize(httpReq)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list openai models: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, readStatusError("openai", resp)
+	}
+
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to decode openai models: %w", err)
+	}
+
+	names := make([]string, 0, len(list.Data))
+	for _, m := range list.Data {
+		names = append(names, m.ID)
+	}
+	return names, nil
+}
+
+// authorize adds the bearer token if an API key is configured
+func (p *OpenAIProvider) authorize(req *http.Request) {
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+}
+
+// toOpenAIMessage converts a message to the OpenAI wire format, sending any
+// images from the vault as data URLs alongside the text
+func toOpenAIMessage(msg fs.Message, vaultPath string) (openAIMessage, error) {
+	if len(msg.Images) == 0 {
+		return openAIMessage{Role: msg.Role, Content: msg.Content}, nil
+	}
+	images, err := encodeImages(vaultPath, msg.Images)
+	if err != nil {
+		return openAIMessage{}, err
+	}
+	parts := []openAIContentPart{{Type: "text", Text: msg.Content}}
+	for i, data := range images {
+		url := "data:" + fs.AttachmentType(msg.Images[i]) + ";base64," + data
+		parts = append(parts, openAIContentPart{Type: "image_url", ImageURL: &openAIImageURL{URL: url}})
+	}
+	return openAIMessage{Role: msg.Role, Content: parts}, nil
+}
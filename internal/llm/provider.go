@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// Options are the generation settings sent with a chat request
+type Options struct {
+	Temperature float64 `json:"temperature"`
+	config.GenerationOptions
+}
+
+// ChatRequest is a provider-independent chat completion request
+type ChatRequest struct {
+	Model    string
+	Messages []fs.Message
+	Options  Options
+	Tools    []ToolSpec // Tools the model may call, if the backend supports it
+	// Format is a JSON Schema the answer must follow, or nil for free text
+	Format json.RawMessage
+	// VaultPath is the vault the messages' image references are stored in
+	VaultPath string
+}
+
+// ChatResponse is the result of a completed chat stream
+type ChatResponse struct {
+	Model   string     // The model that produced the answer, as reported by the server
+	Content string     // The full answer
+	Metrics fs.Metrics // Token usage and timing; providers fill in what the server reports
+	// Thinking is the model's reasoning, which Stream keeps out of Content
+	Thinking string
+	// Endpoint names the profile that answered, if the request went through a FailoverProvider
+	Endpoint string
+	// ToolCalls are the tools the model asked to run instead of, or before, answering
+	ToolCalls []fs.ToolCall
+	// ToolMessages are the tool calls and results exchanged before the answer,
+	// in order, when the request went through StreamToolEvents
+	ToolMessages []fs.Message
+}
+
+// ChatProvider is a chat backend that can stream completions and list its models.
+// Streams are cancelled through the context.
+type ChatProvider interface {
+	// Name identifies the kind of backend, e.g. "ollama"
+	Name() string
+	// ChatStream sends the request and calls onToken with every chunk of the
+	// answer as it arrives. Returning an error from onToken aborts the stream.
+	ChatStream(ctx context.Context, req ChatRequest, onToken func(string) error) (*ChatResponse, error)
+	// ListModels returns the names of the models the backend can serve
+	ListModels(ctx context.Context) ([]string, error)
+}
+
+// StatusError is returned when a backend answers with a non-success HTTP status
+type StatusError struct {
+	Provider   string
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned %d %s: %s", e.Provider, e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// NewProvider creates the chat backend described by a profile
+//
+// Parameters:
+//   - p: The profile naming the provider kind, base URL and credentials
+//
+// Returns:
+//   - ChatProvider: The backend
+//   - error: An error if the provider kind is unknown
+func NewProvider(p config.Profile) (ChatProvider, error) {
+	switch strings.ToLower(p.Provider) {
+	case "", config.ProviderOllama:
+		return NewOllamaProvider(p.APIURL, nil), nil
+	case config.ProviderOpenAI:
+		return NewOpenAIProvider(p.APIURL, p.Key(), nil), nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", p.Provider)
+	}
+}
@@ -0,0 +1,292 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// streamServer serves the given lines one at a time, flushing after each
+func streamServer(t *testing.T, contentType string, lines []string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// collect runs a chat stream and returns its response and the tokens it reported
+func collect(t *testing.T, p ChatProvider) (*ChatResponse, []string) {
+	t.Helper()
+	var tokens []string
+	req := ChatRequest{Model: "test", Messages: []fs.Message{{Role: fs.RoleUser, Content: "hi"}}}
+	resp, err := p.ChatStream(context.Background(), req, func(s string) error {
+		tokens = append(tokens, s)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+	return resp, tokens
+}
+
+func TestOllamaChatStream(t *testing.T) {
+	srv := streamServer(t, "application/x-ndjson", []string{
+		`{"model":"llama3.1","message":{"role":"assistant","content":"Hello"},"done":false}`,
+		``,
+		`{"model":"llama3.1","message":{"role":"assistant","content":", world"},"done":false}`,
+		`{"model":"llama3.1","message":{"role":"assistant","content":""},"done":true,"prompt_eval_count":12,"eval_count":4,"eval_duration":2000000000}`,
+		`{"model":"llama3.1","message":{"role":"assistant","content":"after done"},"done":false}`,
+	})
+
+	resp, tokens := collect(t, NewOllamaProvider(srv.URL, srv.Client()))
+	if resp.Content != "Hello, world" {
+		t.Errorf("Content = %q, want %q", resp.Content, "Hello, world")
+	}
+	if got := strings.Join(tokens, ""); got != resp.Content {
+		t.Errorf("tokens = %q, want %q", got, resp.Content)
+	}
+	if resp.Model != "llama3.1" {
+		t.Errorf("Model = %q, want llama3.1", resp.Model)
+	}
+	if resp.Metrics.PromptTokens != 12 || resp.Metrics.CompletionTokens != 4 || resp.Metrics.TokensPerSecond != 2 {
+		t.Errorf("Metrics = %+v, want 12 prompt, 4 completion, 2 tokens/s", resp.Metrics)
+	}
+}
+
+func TestOllamaChatStreamToolCalls(t *testing.T) {
+	srv := streamServer(t, "application/x-ndjson", []string{
+		`{"model":"llama3.1","message":{"role":"assistant","content":"","tool_calls":[{"function":{"name":"read_file","arguments":{"path":"go.mod"}}}]},"done":true}`,
+	})
+
+	resp, _ := collect(t, NewOllamaProvider(srv.URL, srv.Client()))
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Name != "read_file" || string(resp.ToolCalls[0].Arguments) != `{"path":"go.mod"}` {
+		t.Errorf("ToolCalls = %+v, want read_file(path=go.mod)", resp.ToolCalls)
+	}
+}
+
+func TestOllamaChatStreamErrorLine(t *testing.T) {
+	srv := streamServer(t, "application/x-ndjson", []string{
+		`{"model":"llama3.1","message":{"role":"assistant","content":"Hel"},"done":false}`,
+		`{"error":"model ran out of memory"}`,
+	})
+
+	_, err := NewOllamaProvider(srv.URL, srv.Client()).ChatStream(context.Background(), ChatRequest{Model: "test"}, func(string) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "model ran out of memory") {
+		t.Errorf("err = %v, want the error line's message", err)
+	}
+}
+
+func TestOpenAIChatStream(t *testing.T) {
+	srv := streamServer(t, "text/event-stream", []string{
+		`: keep-alive`,
+		`data: {"model":"gpt-4o-mini","choices":[{"delta":{"content":"Hello"}}]}`,
+		``,
+		`event: ignored`,
+		`data: {"model":"gpt-4o-mini","choices":[{"delta":{"content":", world"}}]}`,
+		``,
+		`data: {"model":"gpt-4o-mini","choices":[{"delta":{},"finish_reason":"stop"}]}`,
+		``,
+		`data: {"model":"gpt-4o-mini","choices":[],"usage":{"prompt_tokens":9,"completion_tokens":3}}`,
+		``,
+		`data: [DONE]`,
+		``,
+		`data: {"choices":[{"delta":{"content":"after done"}}]}`,
+	})
+
+	resp, tokens := collect(t, NewOpenAIProvider(srv.URL, "", srv.Client()))
+	if resp.Content != "Hello, world" {
+		t.Errorf("Content = %q, want %q", resp.Content, "Hello, world")
+	}
+	if got := strings.Join(tokens, ""); got != resp.Content {
+		t.Errorf("tokens = %q, want %q", got, resp.Content)
+	}
+	if resp.Model != "gpt-4o-mini" {
+		t.Errorf("Model = %q, want gpt-4o-mini", resp.Model)
+	}
+	if resp.Metrics.PromptTokens != 9 || resp.Metrics.CompletionTokens != 3 {
+		t.Errorf("Metrics = %+v, want 9 prompt and 3 completion tokens", resp.Metrics)
+	}
+}
+
+func TestOpenAIChatStreamSendsRequest(t *testing.T) {
+	var path, auth, accept string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, auth, accept = r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("Accept")
+		fmt.Fprintln(w, "data: [DONE]")
+	}))
+	defer srv.Close()
+
+	collect(t, NewOpenAIProvider(srv.URL+"/", "secret", srv.Client()))
+	if path != "/v1/chat/completions" {
+		t.Errorf("path = %q, want /v1/chat/completions", path)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want Bearer secret", auth)
+	}
+	if accept != "text/event-stream" {
+		t.Errorf("Accept = %q, want text/event-stream", accept)
+	}
+}
+
+func TestChatStreamStatusError(t *testing.T) {
+	providers := map[string]func(url string, c *http.Client) ChatProvider{
+		"ollama": func(url string, c *http.Client) ChatProvider { return NewOllamaProvider(url, c) },
+		"openai": func(url string, c *http.Client) ChatProvider { return NewOpenAIProvider(url, "", c) },
+	}
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		message string
+	}{
+		{"string error", http.StatusNotFound, `{"error":"model \"nope\" not found"}`, `model "nope" not found`},
+		{"object error", http.StatusUnauthorized, `{"error":{"message":"invalid api key","type":"auth"}}`, "invalid api key"},
+		{"plain text", http.StatusBadGateway, "upstream unavailable\n", "upstream unavailable"},
+	}
+
+	for kind, newProvider := range providers {
+		for _, tt := range tests {
+			t.Run(kind+"/"+tt.name, func(t *testing.T) {
+				srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(tt.status)
+					fmt.Fprint(w, tt.body)
+				}))
+				defer srv.Close()
+
+				_, err := newProvider(srv.URL, srv.Client()).ChatStream(context.Background(), ChatRequest{Model: "test"}, func(string) error { return nil })
+				var statusErr *StatusError
+				if !errors.As(err, &statusErr) {
+					t.Fatalf("err = %v, want a *StatusError", err)
+				}
+				if statusErr.Provider != kind || statusErr.StatusCode != tt.status || statusErr.Message != tt.message {
+					t.Errorf("StatusError = %+v, want %s %d %q", statusErr, kind, tt.status, tt.message)
+				}
+			})
+		}
+	}
+}
+
+func TestChatStreamEndsBeforeDone(t *testing.T) {
+	streams := map[string]struct {
+		contentType string
+		lines       []string
+		newProvider func(url string, c *http.Client) ChatProvider
+	}{
+		"ollama": {
+			"application/x-ndjson",
+			[]string{
+				`{"model":"llama3.1","message":{"role":"assistant","content":"Hel"},"done":false}`,
+				`{"model":"llama3.1","message":{"role":"assistant","content":"lo"},"done":false}`,
+			},
+			func(url string, c *http.Client) ChatProvider { return NewOllamaProvider(url, c) },
+		},
+		"openai": {
+			"text/event-stream",
+			[]string{
+				`data: {"choices":[{"delta":{"content":"Hel"}}]}`,
+				``,
+				`data: {"choices":[{"delta":{"content":"lo"},"finish_reason":"stop"}]}`,
+				``,
+			},
+			func(url string, c *http.Client) ChatProvider { return NewOpenAIProvider(url, "", c) },
+		},
+	}
+
+	for kind, s := range streams {
+		t.Run(kind, func(t *testing.T) {
+			srv := streamServer(t, s.contentType, s.lines)
+			var tokens []string
+			resp, err := s.newProvider(srv.URL, srv.Client()).ChatStream(context.Background(), ChatRequest{Model: "test"}, func(tok string) error {
+				tokens = append(tokens, tok)
+				return nil
+			})
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("err = %v, want io.ErrUnexpectedEOF", err)
+			}
+			if resp != nil {
+				t.Errorf("resp = %+v, want none for a cut-off answer", resp)
+			}
+			if strings.Join(tokens, "") != "Hello" {
+				t.Errorf("tokens = %q, want the ones sent before the stream ended", tokens)
+			}
+		})
+	}
+}
+
+func TestChatStreamCancelMidStream(t *testing.T) {
+	streams := map[string]struct {
+		contentType string
+		first       string
+		newProvider func(url string, c *http.Client) ChatProvider
+	}{
+		"ollama": {
+			"application/x-ndjson",
+			`{"model":"llama3.1","message":{"role":"assistant","content":"Hel"},"done":false}`,
+			func(url string, c *http.Client) ChatProvider { return NewOllamaProvider(url, c) },
+		},
+		"openai": {
+			"text/event-stream",
+			`data: {"choices":[{"delta":{"content":"Hel"}}]}` + "\n",
+			func(url string, c *http.Client) ChatProvider { return NewOpenAIProvider(url, "", c) },
+		},
+	}
+
+	for kind, s := range streams {
+		t.Run(kind, func(t *testing.T) {
+			aborted := make(chan struct{})
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", s.contentType)
+				fmt.Fprintln(w, s.first)
+				w.(http.Flusher).Flush()
+				// Hold the stream open until the client goes away
+				<-r.Context().Done()
+				close(aborted)
+			}))
+			defer srv.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			var tokens []string
+			done := make(chan error, 1)
+			go func() {
+				_, err := s.newProvider(srv.URL, srv.Client()).ChatStream(ctx, ChatRequest{Model: "test"}, func(tok string) error {
+					tokens = append(tokens, tok)
+					cancel()
+					return nil
+				})
+				done <- err
+			}()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("err = %v, want context.Canceled", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("ChatStream did not return after the context was cancelled")
+			}
+			if strings.Join(tokens, "") != "Hel" {
+				t.Errorf("tokens = %q, want the one sent before cancelling", tokens)
+			}
+			select {
+			case <-aborted:
+			case <-time.After(5 * time.Second):
+				t.Error("the server never saw the request aborted")
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// How a route was chosen
+const (
+	RouteOverride   = "override"   // The question started with @route or @model
+	RouteRule       = "rule"       // A route's keyword or pattern matched
+	RouteClassifier = "classifier" // The classifier model picked the route
+	RouteDefault    = "default"    // Nothing matched and the table has a default route
+)
+
+// RouteDecision is where a question is sent
+type RouteDecision struct {
+	Route   string // Name of the route taken, or "" for an @model override or no route
+	Model   string // Model to answer with, or "" to keep the current one
+	Persona string // Persona to answer as, or "" to keep the current one
+	Reason  string // One of the Route* reasons, or "" if no route applies
+	Detail  string // What decided it, e.g. the keyword that matched
+}
+
+// Label describes the decision for display, e.g.
+// `code → qwen2.5-coder (keyword "refactor")`. It is "" if no route applies.
+func (d RouteDecision) Label() string {
+	if d.Reason == "" {
+		return ""
+	}
+	var target []string
+	if d.Model != "" {
+		target = append(target, d.Model)
+	}
+	if d.Persona != "" {
+		target = append(target, "persona "+d.Persona)
+	}
+
+	label := d.Route
+	if len(target) > 0 {
+		if label != "" {
+			label += " → "
+		}
+		label += strings.Join(target, ", ")
+	}
+	if d.Detail != "" {
+		label += " (" + d.Detail + ")"
+	}
+	return label
+}
+
+// Router picks a route for each question from a routing table
+type Router struct {
+	// Models are further models @model overrides may name besides the
+	// routes' and the installed ones, e.g. the personas' models
+	Models []string
+
+	routing  config.Routing
+	patterns []*regexp.Regexp   // Each route's pattern, or nil
+	keywords [][]*regexp.Regexp // Each route's keywords, matching whole words
+}
+
+// NewRouter compiles a routing table. An empty table routes nothing but
+// still honours @model overrides.
+func NewRouter(routing config.Routing) (*Router, error) {
+	if err := routing.Validate(); err != nil {
+		return nil, err
+	}
+	r := &Router{
+		routing:  routing,
+		patterns: make([]*regexp.Regexp, len(routing.Routes)),
+		keywords: make([][]*regexp.Regexp, len(routing.Routes)),
+	}
+	for i, route := range routing.Routes {
+		if route.Pattern != "" {
+			r.patterns[i] = regexp.MustCompile(route.Pattern) // Validate compiled it already
+		}
+		for _, kw := range route.Keywords {
+			if kw = strings.TrimSpace(kw); kw != "" {
+				r.keywords[i] = append(r.keywords[i], regexp.MustCompile(`(?i)(^|\W)`+regexp.QuoteMeta(kw)+`($|\W)`))
+			}
+		}
+	}
+	return r, nil
+}
+
+// ParseOverride splits an inline override such as "@llama3.1 what is…" into
+// the name after the @ and the rest of the question. ok is false if the
+// question doesn't start with one. Whether the name is a route or model is
+// up to the caller; Route checks it.
+func ParseOverride(question string) (name, rest string, ok bool) {
+	trimmed := strings.TrimLeftFunc(question, unicode.IsSpace)
+	if !strings.HasPrefix(trimmed, "@") {
+		return "", question, false
+	}
+	end := strings.IndexFunc(trimmed, unicode.IsSpace)
+	if end < 0 {
+		return "", question, false
+	}
+	name, rest = trimmed[1:end], strings.TrimSpace(trimmed[end:])
+	if name == "" || rest == "" {
+		return "", question, false
+	}
+	return name, rest, true
+}
+
+// Route decides where a question goes: an @route or @model prefix wins, then
+// the first route whose keyword or pattern matches, then the classifier, then
+// the default route. An @ prefix naming neither a route nor a known model,
+// as in "@Override annotations in Java?", is part of the question.
+//
+// Parameters:
+//   - ctx: Cancels the classifier request
+//   - provider: The backend the classifier is asked on
+//   - req: The options to classify with; its model and messages are replaced
+//   - question: The question as typed
+//
+// Returns:
+//   - RouteDecision: Where the question goes
+//   - string: The question without an override prefix
+//   - error: An error if the classifier failed; the decision then falls back to the default route
+func (r *Router) Route(ctx context.Context, provider ChatProvider, req ChatRequest, question string) (RouteDecision, string, error) {
+	if name, rest, ok := ParseOverride(question); ok {
+		if route, found := r.routing.Find(name); found {
+			d := decision(route, RouteOverride)
+			d.Detail = "override"
+			return d, rest, nil
+		}
+		if r.knownModel(ctx, provider, req, name) {
+			return RouteDecision{Model: name, Reason: RouteOverride, Detail: "override"}, rest, nil
+		}
+	}
+
+	for i := range r.routing.Routes {
+		route := &r.routing.Routes[i]
+		for j, kw := range r.keywords[i] {
+			if kw.MatchString(question) {
+				d := decision(route, RouteRule)
+				d.Detail = fmt.Sprintf("keyword %q", strings.TrimSpace(route.Keywords[j]))
+				return d, question, nil
+			}
+		}
+		if r.patterns[i] != nil && r.patterns[i].MatchString(question) {
+			d := decision(route, RouteRule)
+			d.Detail = "pattern"
+			return d, question, nil
+		}
+	}
+
+	var err error
+	if r.routing.Classifier != "" {
+		var route *config.Route
+		if route, err = r.classify(ctx, provider, req, question); err == nil && route != nil {
+			d := decision(route, RouteClassifier)
+			d.Detail = "classified by " + r.routing.Classifier
+			return d, question, nil
+		}
+	}
+
+	if r.routing.Default != "" {
+		route, _ := r.routing.Find(r.routing.Default) // Validate checked it exists
+		d := decision(route, RouteDefault)
+		d.Detail = "default"
+		return d, question, err
+	}
+	return RouteDecision{}, question, err
+}
+
+// knownModel reports whether name is a model an @model override may pick:
+// one a route answers with, one of the router's Models, the request's model
+// or one the backend has installed. Ollama's implied ":latest" tag may be
+// left out. A backend that can't list its models knows only the first three.
+func (r *Router) knownModel(ctx context.Context, provider ChatProvider, req ChatRequest, name string) bool {
+	known := append([]string{req.Model}, r.Models...)
+	for _, route := range r.routing.Routes {
+		known = append(known, route.Model)
+	}
+	if installed, err := provider.ListModels(ctx); err == nil {
+		known = append(known, installed...)
+	}
+	for _, model := range known {
+		if model != "" && SameModel(model, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// decision returns the decision to take a route
+func decision(route *config.Route, reason string) RouteDecision {
+	return RouteDecision{Route: route.Name, Model: route.Model, Persona: route.Persona, Reason: reason}
+}
+
+// classifyPrompt asks the classifier which route fits a question
+const classifyPrompt = `Pick the route that should answer the question below, or "none" if no route fits.
+
+Routes:
+%s
+Question:
+%s`
+
+// classify asks the classifier model which route described in the table fits
+// the question. It returns nil if the model picked none.
+func (r *Router) classify(ctx context.Context, provider ChatProvider, req ChatRequest, question string) (*config.Route, error) {
+	var routes strings.Builder
+	names := []string{"none"}
+	for _, route := range r.routing.Routes {
+		if route.Description == "" {
+			continue
+		}
+		fmt.Fprintf(&routes, "- %s: %s\n", route.Name, route.Description)
+		names = append(names, route.Name)
+	}
+	if len(names) == 1 {
+		return nil, nil
+	}
+
+	enum, err := json.Marshal(names)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the route schema: %w", err)
+	}
+	schema, err := ParseSchema([]byte(fmt.Sprintf(`{"type": "object", "properties": {"route": {"enum": %s}}, "required": ["route"]}`, enum)))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Model = r.routing.Classifier
+	req.Messages = []fs.Message{{Role: fs.RoleUser, Content: fmt.Sprintf(classifyPrompt, routes.String(), question)}}
+	req.Tools = nil
+	req.Options.Temperature = 0
+	out, _, err := AskStructured(ctx, provider, req, schema, DefaultRepairs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to classify the question: %w", err)
+	}
+
+	var result struct {
+		Route string `json:"route"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse the route: %w", err)
+	}
+	route, ok := r.routing.Find(result.Route)
+	if !ok {
+		return nil, nil
+	}
+	return route, nil
+}
@@ -0,0 +1,196 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+)
+
+// installedProvider reports a fixed list of installed models
+type installedProvider struct {
+	fakeProvider
+	models []string
+}
+
+func (p *installedProvider) ListModels(ctx context.Context) ([]string, error) {
+	return p.models, nil
+}
+
+func TestRouteOverride(t *testing.T) {
+	router, err := NewRouter(config.Routing{
+		Routes:  []config.Route{{Name: "code", Keywords: []string{"java"}, Model: "qwen2.5-coder:14b"}},
+		Default: "code",
+	})
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	router.Models = []string{"mistral"}
+	provider := &installedProvider{models: []string{"llama3.1:latest", "gemma3:1b"}}
+	req := ChatRequest{Model: "phi3"}
+
+	tests := []struct {
+		question, route, model, reason, rest string
+	}{
+		{"@code write a parser", "code", "qwen2.5-coder:14b", RouteOverride, "write a parser"},
+		{"@gemma3:1b hi there", "", "gemma3:1b", RouteOverride, "hi there"},
+		{"@llama3.1 what's new?", "", "llama3.1", RouteOverride, "what's new?"},
+		{"@qwen2.5-coder:14b hi", "", "qwen2.5-coder:14b", RouteOverride, "hi"},
+		{"@mistral hi", "", "mistral", RouteOverride, "hi"},
+		{"@Mistral:latest hi", "", "Mistral:latest", RouteOverride, "hi"},
+		{"@phi3 hi", "", "phi3", RouteOverride, "hi"},
+		// Unknown names are part of the question
+		{"@Override annotations in Java?", "code", "qwen2.5-coder:14b", RouteRule, "@Override annotations in Java?"},
+		{"@someone said hi", "code", "qwen2.5-coder:14b", RouteDefault, "@someone said hi"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.question, func(t *testing.T) {
+			d, rest, err := router.Route(context.Background(), provider, req, tt.question)
+			if err != nil {
+				t.Fatalf("Route: %v", err)
+			}
+			if d.Route != tt.route || d.Model != tt.model || d.Reason != tt.reason || rest != tt.rest {
+				t.Errorf("Route = %+v, %q, want %s → %s (%s), %q", d, rest, tt.route, tt.model, tt.reason, tt.rest)
+			}
+		})
+	}
+}
+
+func TestRoutingRejectsNone(t *testing.T) {
+	_, err := NewRouter(config.Routing{Routes: []config.Route{{Name: "None", Model: "m"}}})
+	if err == nil {
+		t.Error("NewRouter accepted a route named none")
+	}
+}
+
+func TestRouteRules(t *testing.T) {
+	router, err := NewRouter(config.Routing{
+		Routes: []config.Route{
+			{Name: "code", Keywords: []string{"refactor", " unit test ", "c++"}, Model: "qwen2.5-coder"},
+			{Name: "sql", Pattern: `(?i)\bselect\b.*\bfrom\b`, Model: "sqlcoder"},
+			// Shadowed by code for questions both match
+			{Name: "tests", Keywords: []string{"refactor", "flaky"}, Persona: "tester"},
+		},
+		Default: "sql",
+	})
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+
+	tests := []struct {
+		question, route, reason, detail string
+	}{
+		{"Refactor this function", "code", RouteRule, `keyword "refactor"`},
+		{"how do I write a UNIT TEST?", "code", RouteRule, `keyword "unit test"`},
+		{"is c++ faster?", "code", RouteRule, `keyword "c++"`},
+		{"(refactor)", "code", RouteRule, `keyword "refactor"`},
+		// Keywords match whole words only
+		{"the refactoring is done", "sql", RouteDefault, "default"},
+		{"myunit test runner", "sql", RouteDefault, "default"},
+		{"SELECT name FROM users", "sql", RouteRule, "pattern"},
+		{"a flaky build", "tests", RouteRule, `keyword "flaky"`},
+		// The first matching route wins
+		{"refactor the flaky select from t", "code", RouteRule, `keyword "refactor"`},
+		{"select the flaky ones from the list", "sql", RouteRule, "pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.question, func(t *testing.T) {
+			d, rest, err := router.Route(context.Background(), &fakeProvider{}, ChatRequest{}, tt.question)
+			if err != nil {
+				t.Fatalf("Route: %v", err)
+			}
+			if d.Route != tt.route || d.Reason != tt.reason || d.Detail != tt.detail || rest != tt.question {
+				t.Errorf("Route = %+v, %q, want %s (%s, %s)", d, rest, tt.route, tt.reason, tt.detail)
+			}
+		})
+	}
+}
+
+func TestRouteClassifier(t *testing.T) {
+	routing := config.Routing{
+		Routes: []config.Route{
+			{Name: "code", Description: "programming questions", Model: "qwen2.5-coder"},
+			{Name: "chat", Model: "llama3.1"},
+			{Name: "math", Description: "arithmetic and proofs", Model: "qwen2-math"},
+		},
+		Classifier: "gemma3:1b",
+		Default:    "chat",
+	}
+	router, err := NewRouter(routing)
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		answers []string
+		route   string
+		reason  string
+		detail  string
+		wantErr bool
+	}{
+		{"picks a route", []string{`{"route": "math"}`}, "math", RouteClassifier, "classified by gemma3:1b", false},
+		{"none", []string{`{"route": "none"}`}, "chat", RouteDefault, "default", false},
+		{"repaired answer", []string{`{"route": "chat"}`, `{"route": "code"}`}, "code", RouteClassifier, "classified by gemma3:1b", false},
+		{"request fails", nil, "chat", RouteDefault, "default", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &scriptedProvider{answers: tt.answers}
+			req := ChatRequest{Model: "llama3.1", Options: Options{Temperature: 0.8}}
+			d, _, err := router.Route(context.Background(), provider, req, "what is 2+2?")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Route error = %v, want error %v", err, tt.wantErr)
+			}
+			if d.Route != tt.route || d.Reason != tt.reason || d.Detail != tt.detail {
+				t.Errorf("Route = %+v, want %s (%s, %s)", d, tt.route, tt.reason, tt.detail)
+			}
+
+			if len(provider.requests) == 0 {
+				t.Fatal("the classifier was not asked")
+			}
+			sent := provider.requests[0]
+			if sent.Model != "gemma3:1b" || sent.Options.Temperature != 0 {
+				t.Errorf("asked %q at temperature %v, want the classifier at 0", sent.Model, sent.Options.Temperature)
+			}
+			prompt := sent.Messages[len(sent.Messages)-1].Content
+			if !strings.Contains(prompt, "- code: programming questions") || !strings.Contains(prompt, "what is 2+2?") {
+				t.Errorf("prompt = %q, want the described routes and the question", prompt)
+			}
+			// Routes without a description can't be picked
+			if strings.Contains(prompt, "- chat") {
+				t.Errorf("prompt = %q, offers the undescribed chat route", prompt)
+			}
+		})
+	}
+
+	// Without a default route a failed classifier routes nothing
+	routing.Default = ""
+	router, err = NewRouter(routing)
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	d, _, err := router.Route(context.Background(), &scriptedProvider{}, ChatRequest{}, "hi")
+	if err == nil || d.Reason != "" {
+		t.Errorf("Route = %+v, %v, want no route and the error", d, err)
+	}
+}
+
+func TestRouteDecisionLabel(t *testing.T) {
+	tests := []struct {
+		d    RouteDecision
+		want string
+	}{
+		{RouteDecision{}, ""},
+		{RouteDecision{Route: "code", Model: "qwen2.5-coder", Reason: RouteRule, Detail: `keyword "refactor"`}, `code → qwen2.5-coder (keyword "refactor")`},
+		{RouteDecision{Route: "review", Model: "llama3.1", Persona: "Go reviewer", Reason: RouteDefault, Detail: "default"}, "review → llama3.1, persona Go reviewer (default)"},
+		{RouteDecision{Route: "plain", Reason: RouteClassifier, Detail: "classified by gemma3:1b"}, "plain (classified by gemma3:1b)"},
+		{RouteDecision{Model: "mistral", Reason: RouteOverride, Detail: "override"}, "mistral (override)"},
+	}
+	for _, tt := range tests {
+		if got := tt.d.Label(); got != tt.want {
+			t.Errorf("Label() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,340 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a JSON Schema for structured output. It validates the subset of
+// JSON Schema that structured output is normally described with: type,
+// properties, required, additionalProperties, items, enum, const, anyOf,
+// oneOf, minimum, maximum, minLength, maxLength, pattern, minItems and maxItems.
+// Other keywords are sent to the model but not checked.
+type Schema struct {
+	raw  json.RawMessage
+	root *schemaNode
+}
+
+// schemaNode is one (sub)schema
+type schemaNode struct {
+	Type                 typeList               `json:"type"`
+	Properties           map[string]*schemaNode `json:"properties"`
+	Required             []string               `json:"required"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties"`
+	Items                *schemaNode            `json:"items"`
+	Enum                 []json.RawMessage      `json:"enum"`
+	Const                json.RawMessage        `json:"const"`
+	AnyOf                []*schemaNode          `json:"anyOf"`
+	OneOf                []*schemaNode          `json:"oneOf"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	MinLength            *int                   `json:"minLength"`
+	MaxLength            *int                   `json:"maxLength"`
+	Pattern              string                 `json:"pattern"`
+	MinItems             *int                   `json:"minItems"`
+	MaxItems             *int                   `json:"maxItems"`
+
+	pattern    *regexp.Regexp
+	noExtra    bool        // additionalProperties is false
+	extraItems *schemaNode // additionalProperties is a schema
+}
+
+// typeList is the "type" keyword, which is a name or a list of names
+type typeList []string
+
+func (t *typeList) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = typeList{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings")
+	}
+	*t = many
+	return nil
+}
+
+// ParseSchema parses a JSON Schema
+//
+// Parameters:
+//   - data: The schema as JSON
+//
+// Returns:
+//   - *Schema: The parsed schema
+//   - error: An error if the schema is not valid JSON or uses a keyword wrongly
+func ParseSchema(data []byte) (*Schema, error) {
+	var root schemaNode
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	if err := root.compile("$"); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return &Schema{raw: compact.Bytes(), root: &root}, nil
+}
+
+// compile prepares patterns and additionalProperties throughout the schema
+func (n *schemaNode) compile(path string) error {
+	if n.Pattern != "" {
+		re, err := regexp.Compile(n.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern: %w", path, err)
+		}
+		n.pattern = re
+	}
+	switch extra := bytes.TrimSpace(n.AdditionalProperties); {
+	case len(extra) == 0, string(extra) == "true":
+	case string(extra) == "false":
+		n.noExtra = true
+	default:
+		n.extraItems = &schemaNode{}
+		if err := json.Unmarshal(extra, n.extraItems); err != nil {
+			return fmt.Errorf("%s: invalid additionalProperties: %w", path, err)
+		}
+	}
+
+	children := map[string]*schemaNode{}
+	for name, p := range n.Properties {
+		children[path+"."+name] = p
+	}
+	if n.Items != nil {
+		children[path+"[]"] = n.Items
+	}
+	if n.extraItems != nil {
+		children[path+".*"] = n.extraItems
+	}
+	for i, s := range n.AnyOf {
+		children[fmt.Sprintf("%s.anyOf[%d]", path, i)] = s
+	}
+	for i, s := range n.OneOf {
+		children[fmt.Sprintf("%s.oneOf[%d]", path, i)] = s
+	}
+	for p, child := range children {
+		if child == nil {
+			continue
+		}
+		if err := child.compile(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JSON returns the schema as compact JSON, as sent to the model
+func (s *Schema) JSON() json.RawMessage {
+	return s.raw
+}
+
+// ValidationError lists the ways a document doesn't match a schema
+type ValidationError struct {
+	Problems []string // One entry per mismatch, each starting with the JSON path
+}
+
+func (e *ValidationError) Error() string {
+	return "output does not match the schema: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks a JSON document against the schema
+//
+// Returns:
+//   - error: A *ValidationError if the document doesn't match, or another
+//     error if it isn't valid JSON
+func (s *Schema) Validate(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("output is not valid JSON: %w", err)
+	}
+	if dec.More() {
+		return fmt.Errorf("output is not valid JSON: unexpected data after the value")
+	}
+
+	var problems []string
+	s.root.validate("$", v, &problems)
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// validate appends the ways v doesn't match n to problems
+func (n *schemaNode) validate(path string, v interface{}, problems *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if len(n.Type) > 0 && !n.Type.matches(v) {
+		fail("expected %s, got %s", strings.Join(n.Type, " or "), jsonType(v))
+		return
+	}
+	if len(n.Enum) > 0 {
+		found := false
+		for _, e := range n.Enum {
+			if sameJSON(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("value is not one of the allowed values")
+		}
+	}
+	if len(n.Const) > 0 && !sameJSON(n.Const, v) {
+		fail("value must be %s", n.Const)
+	}
+	if len(n.AnyOf) > 0 && n.countMatches(path, n.AnyOf, v) == 0 {
+		fail("value matches none of the anyOf schemas")
+	}
+	if len(n.OneOf) > 0 {
+		if c := n.countMatches(path, n.OneOf, v); c != 1 {
+			fail("value matches %d of the oneOf schemas instead of exactly one", c)
+		}
+	}
+
+	switch val := v.(type) {
+	case string:
+		length := utf8.RuneCountInString(val)
+		if n.MinLength != nil && length < *n.MinLength {
+			fail("string is shorter than %d characters", *n.MinLength)
+		}
+		if n.MaxLength != nil && length > *n.MaxLength {
+			fail("string is longer than %d characters", *n.MaxLength)
+		}
+		if n.pattern != nil && !n.pattern.MatchString(val) {
+			fail("string does not match pattern %q", n.Pattern)
+		}
+
+	case json.Number:
+		f, _ := val.Float64()
+		if n.Minimum != nil && f < *n.Minimum {
+			fail("%s is less than the minimum %g", val, *n.Minimum)
+		}
+		if n.Maximum != nil && f > *n.Maximum {
+			fail("%s is greater than the maximum %g", val, *n.Maximum)
+		}
+
+	case []interface{}:
+		if n.MinItems != nil && len(val) < *n.MinItems {
+			fail("array has fewer than %d items", *n.MinItems)
+		}
+		if n.MaxItems != nil && len(val) > *n.MaxItems {
+			fail("array has more than %d items", *n.MaxItems)
+		}
+		if n.Items != nil {
+			for i, item := range val {
+				n.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, problems)
+			}
+		}
+
+	case map[string]interface{}:
+		for _, name := range n.Required {
+			if _, ok := val[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(val))
+		for name := range val {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if p, ok := n.Properties[name]; ok {
+				if p != nil {
+					p.validate(path+"."+name, val[name], problems)
+				}
+				continue
+			}
+			switch {
+			case n.noExtra:
+				fail("unexpected property %q", name)
+			case n.extraItems != nil:
+				n.extraItems.validate(path+"."+name, val[name], problems)
+			}
+		}
+	}
+}
+
+// countMatches returns how many of the schemas v matches
+func (n *schemaNode) countMatches(path string, schemas []*schemaNode, v interface{}) int {
+	count := 0
+	for _, s := range schemas {
+		var problems []string
+		if s != nil {
+			s.validate(path, v, &problems)
+		}
+		if len(problems) == 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// matches reports whether v has one of the types
+func (t typeList) matches(v interface{}) bool {
+	for _, name := range t {
+		switch name {
+		case "integer":
+			if num, ok := v.(json.Number); ok {
+				if f, err := num.Float64(); err == nil && f == math.Trunc(f) {
+					return true
+				}
+			}
+		case "number":
+			if _, ok := v.(json.Number); ok {
+				return true
+			}
+		default:
+			if jsonType(v) == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonType names the JSON type of a decoded value
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+// sameJSON reports whether a raw JSON value equals a decoded one
+func sameJSON(raw json.RawMessage, v interface{}) bool {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var want interface{}
+	if err := dec.Decode(&want); err != nil {
+		return false
+	}
+	a, errA := json.Marshal(want)
+	b, errB := json.Marshal(v)
+	return errA == nil && errB == nil && bytes.Equal(a, b)
+}
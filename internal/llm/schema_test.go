@@ -0,0 +1,131 @@
+package llm
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		doc     string
+		problem string // A substring of the expected problem, or "" if the document is valid
+	}{
+		{"type", `{"type": "string"}`, `"hi"`, ""},
+		{"wrong type", `{"type": "string"}`, `3`, "$: expected string, got number"},
+		{"type list", `{"type": ["string", "null"]}`, `null`, ""},
+		{"type list mismatch", `{"type": ["string", "null"]}`, `true`, "expected string or null, got boolean"},
+
+		{"integer", `{"type": "integer"}`, `42`, ""},
+		{"integer with zero fraction", `{"type": "integer"}`, `42.0`, ""},
+		{"integer with fraction", `{"type": "integer"}`, `4.2`, "expected integer, got number"},
+		{"number accepts fraction", `{"type": "number"}`, `4.2`, ""},
+		{"number accepts integer", `{"type": "number"}`, `4`, ""},
+		{"minimum", `{"type": "number", "minimum": 1}`, `0.5`, "less than the minimum 1"},
+		{"maximum", `{"type": "number", "maximum": 1}`, `2`, "greater than the maximum 1"},
+
+		{"enum", `{"enum": ["a", 1, null]}`, `1`, ""},
+		{"enum null", `{"enum": ["a", 1, null]}`, `null`, ""},
+		{"not in enum", `{"enum": ["a", 1, null]}`, `"b"`, "not one of the allowed values"},
+		{"enum compares numbers by value", `{"enum": [1]}`, `"1"`, "not one of the allowed values"},
+		{"const", `{"const": {"a": [1, 2]}}`, `{"a": [1, 2]}`, ""},
+		{"const mismatch", `{"const": {"a": [1, 2]}}`, `{"a": [2, 1]}`, `value must be {"a": [1, 2]}`},
+
+		{"anyOf one", `{"anyOf": [{"type": "string"}, {"type": "number"}]}`, `1`, ""},
+		{"anyOf both", `{"anyOf": [{"type": "number"}, {"type": "integer"}]}`, `1`, ""},
+		{"anyOf none", `{"anyOf": [{"type": "string"}, {"type": "number"}]}`, `true`, "matches none of the anyOf schemas"},
+		{"oneOf one", `{"oneOf": [{"type": "string"}, {"type": "number"}]}`, `"x"`, ""},
+		{"oneOf two", `{"oneOf": [{"type": "number"}, {"type": "integer"}]}`, `1`, "matches 2 of the oneOf schemas"},
+		{"oneOf none", `{"oneOf": [{"type": "string"}, {"type": "null"}]}`, `1`, "matches 0 of the oneOf schemas"},
+
+		{"required", `{"type": "object", "required": ["a"]}`, `{"a": 1}`, ""},
+		{"missing required", `{"type": "object", "required": ["a", "b"]}`, `{"a": 1}`, `missing required property "b"`},
+		{"extra properties allowed", `{"type": "object", "properties": {"a": {}}}`, `{"a": 1, "b": 2}`, ""},
+		{"extra properties forbidden", `{"type": "object", "properties": {"a": {}}, "additionalProperties": false}`, `{"a": 1, "b": 2}`, `unexpected property "b"`},
+		{"extra properties match schema", `{"type": "object", "additionalProperties": {"type": "integer"}}`, `{"a": 1, "b": 2}`, ""},
+		{"extra property against schema", `{"type": "object", "properties": {"a": {"type": "string"}}, "additionalProperties": {"type": "integer"}}`, `{"a": "x", "b": "y"}`, `$.b: expected integer, got string`},
+		{"nested property", `{"type": "object", "properties": {"a": {"type": "object", "properties": {"b": {"type": "string"}}}}}`, `{"a": {"b": 1}}`, `$.a.b: expected string`},
+
+		{"items", `{"type": "array", "items": {"type": "string"}}`, `["a", "b"]`, ""},
+		{"bad item", `{"type": "array", "items": {"type": "string"}}`, `["a", 2]`, `$[1]: expected string`},
+		{"minItems", `{"type": "array", "minItems": 2}`, `[1]`, "fewer than 2 items"},
+		{"maxItems", `{"type": "array", "maxItems": 1}`, `[1, 2]`, "more than 1 items"},
+
+		{"minLength counts runes", `{"type": "string", "minLength": 2}`, `"é"`, "shorter than 2 characters"},
+		{"maxLength counts runes", `{"type": "string", "maxLength": 2}`, `"éé"`, ""},
+		{"pattern", `{"type": "string", "pattern": "^[a-z]+$"}`, `"abc"`, ""},
+		{"pattern mismatch", `{"type": "string", "pattern": "^[a-z]+$"}`, `"ABC"`, "does not match pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := ParseSchema([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("ParseSchema: %v", err)
+			}
+			err = schema.Validate([]byte(tt.doc))
+			if tt.problem == "" {
+				if err != nil {
+					t.Errorf("Validate(%s) = %v, want nil", tt.doc, err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate(%s) = %v, want a *ValidationError", tt.doc, err)
+			}
+			if !strings.Contains(verr.Error(), tt.problem) {
+				t.Errorf("Validate(%s) = %q, want it to mention %q", tt.doc, verr.Error(), tt.problem)
+			}
+		})
+	}
+}
+
+func TestSchemaValidateListsEveryProblem(t *testing.T) {
+	schema, err := ParseSchema([]byte(`{"type": "object", "properties": {"a": {"type": "string"}, "b": {"type": "integer"}}, "required": ["c"]}`))
+	if err != nil {
+		t.Fatalf("ParseSchema: %v", err)
+	}
+	var verr *ValidationError
+	if !errors.As(schema.Validate([]byte(`{"a": 1, "b": "x"}`)), &verr) {
+		t.Fatal("want a *ValidationError")
+	}
+	want := []string{
+		`$: missing required property "c"`,
+		`$.a: expected string, got number`,
+		`$.b: expected integer, got string`,
+	}
+	if strings.Join(verr.Problems, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Problems = %q, want %q", verr.Problems, want)
+	}
+}
+
+func TestSchemaValidateInvalidJSON(t *testing.T) {
+	schema, err := ParseSchema([]byte(`{"type": "object"}`))
+	if err != nil {
+		t.Fatalf("ParseSchema: %v", err)
+	}
+	for _, doc := range []string{`{"a": 1} {"b": 2}`, `{"a": 1} trailing`, `{"a": `, ``} {
+		err := schema.Validate([]byte(doc))
+		var verr *ValidationError
+		if err == nil || errors.As(err, &verr) {
+			t.Errorf("Validate(%q) = %v, want a JSON error", doc, err)
+		}
+	}
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	for _, schema := range []string{
+		`{"type": 3}`,
+		`{"type": "string", "pattern": "("}`,
+		`{"properties": {"a": {"pattern": "["}}}`,
+		`{"additionalProperties": 3}`,
+		`not json`,
+	} {
+		if _, err := ParseSchema([]byte(schema)); err == nil {
+			t.Errorf("ParseSchema(%s) succeeded, want an error", schema)
+		}
+	}
+}
@@ -2,209 +2,207 @@ package llm
 
 import (
 	"context"
-	"errors"
 	"fmt"
-	"io"
-	"strings"
-	"sync"
 
-	tea "github.com/charmbracelet/bubbletea"
-	"github.com/parakeet-nest/parakeet/completion"
-	"github.com/parakeet-nest/parakeet/enums/option"
-	pkllm "github.com/parakeet-nest/parakeet/llm"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
 
-	"github.com/VarunSharma3520/AskAI/internal/types"
+// EventKind identifies what a stream Event carries
+type EventKind int
+
+const (
+	EventStart      EventKind = iota // The request is being sent; Model is the requested model
+	EventToken                       // Text is the next chunk of the answer
+	EventThinking                    // Text is the next chunk of the model's reasoning
+	EventStats                       // Metrics are the token usage and timing of the answer
+	EventDone                        // Response is the full answer; the last event of a successful stream
+	EventError                       // Err is why the stream failed; the last event of a failed stream
+	EventToolCall                    // ToolCall asks to run a tool; the stream waits for Approve
+	EventToolResult                  // Text is the result of ToolCall
 )
 
-// StartStreamCmd launches a Parakeet ChatStream and emits ui messages.
-// It also collects the full response and calls the provided callback with it.
-func StartStreamCmd(apiURL, modelName, prompt string, temp float64,
-	out chan<- string, errCh chan<- error, stopCh <-chan struct{},
-) tea.Cmd {
-	return startStreamCmdWithCallback(apiURL, modelName, prompt, temp, out, errCh, stopCh, nil)
+// String returns the name of the event kind
+func (k EventKind) String() string {
+	switch k {
+	case EventStart:
+		return "start"
+	case EventToken:
+		return "token"
+	case EventThinking:
+		return "thinking"
+	case EventStats:
+		return "stats"
+	case EventDone:
+		return "done"
+	case EventError:
+		return "error"
+	case EventToolCall:
+		return "tool call"
+	case EventToolResult:
+		return "tool result"
+	default:
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
 }
 
-// StartStreamCmdWithCallback launches a Parakeet ChatStream and calls the provided callback with the full response.
-func StartStreamCmdWithCallback(apiURL, modelName, prompt string, temp float64,
-	out chan<- string, errCh chan<- error, stopCh <-chan struct{}, responseCh chan<- string,
-) tea.Cmd {
-	return startStreamCmdWithCallback(apiURL, modelName, prompt, temp, out, errCh, stopCh, responseCh)
+// Event is one step of a streamed answer
+type Event struct {
+	Kind     EventKind
+	Text     string
+	Model    string
+	Metrics  fs.Metrics
+	Response *ChatResponse
+	Err      error
+	ToolCall *fs.ToolCall
+
+	reply chan bool // Receives the answer to an EventToolCall
 }
 
-func startStreamCmdWithCallback(apiURL, modelName, prompt string, temp float64,
-	out chan<- string, errCh chan<- error, stopCh <-chan struct{}, responseCh chan<- string,
-) tea.Cmd {
-	// log.Printf("Starting stream with model: %s, temperature: %.2f, prompt length: %d", modelName, temp, len(prompt))
-
-	// Create a context that will be canceled when the stream is stopped
-	ctx, cancel := context.WithCancel(context.Background())
-
-	opts := pkllm.SetOptions(map[string]interface{}{
-		string(option.Temperature): temp,
-	})
-
-	return func() tea.Msg {
-		go func() {
-			var fullResponse strings.Builder
-
-			// Handle panics and cleanup
-			var responseSent bool
-			var responseMutex sync.Mutex
-
-			defer func() {
-				cancel() // Cancel the context when we're done
-
-				if r := recover(); r != nil {
-					// errMsg := fmt.Sprintf("Stream panic: %v", r)
-					// log.Printf("ERROR: %s", errMsg)
-					// If we have an error channel, send the error if we haven't already
-					responseMutex.Lock()
-					defer responseMutex.Unlock()
-
-					if errCh != nil && !responseSent {
-						responseSent = true
-						select {
-						case errCh <- fmt.Errorf("stream panic: %v", r):
-							// log.Println("Sent panic to error channel")
-						default: // Don't block if channel is full
-							// log.Println("Error channel blocked, could not send panic")
-						}
-					}
-				} else {
-					// log.Println("Stream completed successfully")
-				}
+// Approve answers an EventToolCall: the tool runs if ok is true, and the
+// model is told the user declined otherwise. It must be called exactly once
+// for every tool call event, or the stream waits until it is cancelled.
+func (e Event) Approve(ok bool) {
+	if e.reply != nil {
+		e.reply <- ok
+	}
+}
 
-				// Close response channel if it exists and we haven't closed it yet
-				if responseCh != nil {
-					responseMutex.Lock()
-					defer responseMutex.Unlock()
-					if !responseSent {
-						close(responseCh)
-						responseSent = true
-					}
-				}
-			}()
-
-			q := pkllm.Query{
-				Model: modelName,
-				Messages: []pkllm.Message{
-					{Role: "user", Content: prompt},
-				},
-				Options: opts,
-				Stream:  true,
-			}
+// eventBuffer is how far the stream may run ahead of its consumer
+const eventBuffer = 64
+
+// StreamEvents streams a chat completion as an ordered channel of events:
+// start, then tokens, then stats and done, or error if the request fails.
+// Sending blocks while the buffer is full, so a slow consumer slows the
+// stream down instead of losing tokens.
+//
+// Cancelling ctx aborts the HTTP request. The channel is closed when the
+// stream ends; a channel closed without a done or error event means the
+// stream was cancelled.
+//
+// Parameters:
+//   - ctx: Cancels the request
+//   - provider: The backend to stream from
+//   - req: The chat request
+//
+// Returns:
+//   - <-chan Event: The events of the stream, closed when it ends
+func StreamEvents(ctx context.Context, provider ChatProvider, req ChatRequest) <-chan Event {
+	return StreamToolEvents(ctx, provider, req, nil)
+}
 
-			// log.Println("Starting ChatStream...")
-			// Create a safe send function to prevent sending on closed channels
-			safeSendString := func(ch chan<- string, data string) bool {
-				if ch == nil {
-					return false
-				}
-				select {
-				case ch <- data:
-					return true
-				case <-ctx.Done():
-					// log.Println("Context done, skipping send")
-					return false
-				default:
-					// log.Println("Channel blocked, skipping send")
-					return false
-				}
-			}
+// StreamToolEvents streams a chat completion like StreamEvents, offering the
+// model the tools in the toolbox. Each tool call is sent as an EventToolCall
+// and runs only once the consumer approves it; its result follows as an
+// EventToolResult and is sent back to the model, which then continues. The
+// tool calls and results exchanged are returned in the response's
+// ToolMessages. A nil toolbox offers no tools.
+//
+// Parameters:
+//   - ctx: Cancels the request
+//   - provider: The backend to stream from
+//   - req: The chat request
+//   - tools: The tools the model may call
+//
+// Returns:
+//   - <-chan Event: The events of the stream, closed when it ends
+func StreamToolEvents(ctx context.Context, provider ChatProvider, req ChatRequest, tools *Toolbox) <-chan Event {
+	events := make(chan Event, eventBuffer)
+
+	send := func(ev Event) error {
+		select {
+		case events <- ev:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
 
-			// log.Println("Starting ChatStream...")
-			_, err := completion.ChatStream(apiURL, q, func(ans pkllm.Answer) error {
-				// Check if context is done
-				select {
-				case <-ctx.Done():
-					// log.Println("Stream canceled by context")
-					return errors.New("stream canceled")
-				default:
-				}
+	// approve asks the consumer whether a tool call may run
+	approve := func(call fs.ToolCall) (bool, error) {
+		reply := make(chan bool, 1)
+		if err := send(Event{Kind: EventToolCall, ToolCall: &call, reply: reply}); err != nil {
+			return false, err
+		}
+		select {
+		case ok := <-reply:
+			return ok, nil
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+	}
 
-				if s := ans.Message.Content; s != "" {
-					// log.Printf("Received chunk, length: %d", len(s))
+	go func() {
+		defer close(events)
+		defer func() {
+			if r := recover(); r != nil {
+				send(Event{Kind: EventError, Err: fmt.Errorf("stream panic: %v", r)})
+			}
+		}()
 
-					// Add to full response
-					fullResponse.WriteString(s)
+		if send(Event{Kind: EventStart, Model: req.Model}) != nil {
+			return
+		}
 
-					// Send to output channels if they're not nil
-					if out != nil {
-						if !safeSendString(out, s) {
-							// log.Println("Failed to send chunk to output channel")
-						}
-					}
-				}
-				return nil
+		if tools != nil {
+			req.Tools = tools.Specs()
+		}
+		messages := req.Messages
+		var exchange []fs.Message
+		var usage fs.Metrics
+		for round := 0; ; round++ {
+			req.Messages = messages
+			resp, err := StreamThinking(ctx, provider, req, func(s string) error {
+				return send(Event{Kind: EventToken, Text: s})
+			}, func(s string) error {
+				return send(Event{Kind: EventThinking, Text: s})
 			})
-
-			// Handle any errors that occurred during streaming
+			if ctx.Err() != nil {
+				return
+			}
 			if err != nil {
-				// errMsg := fmt.Sprintf("Error in ChatStream: %v", err)
-				// log.Printf("ERROR: %s", errMsg)
-				if errCh != nil {
-					select {
-					case errCh <- err:
-						// log.Println("Sent error to error channel")
-					default:
-						// log.Println("Error channel blocked, could not send error")
-					}
-				}
+				send(Event{Kind: EventError, Err: err})
 				return
 			}
-
-			// Send the full response if we have a response channel
-			if responseCh != nil {
-				fullResp := fullResponse.String()
-				if fullResp != "" {
-					if sendFullResponse(ctx, responseCh, fullResp) {
-						// log.Printf("Sent full response to channel, length: %d", len(fullResp))
-					} else {
-						// log.Println("Failed to deliver full response to response channel")
-					}
+			usage.PromptTokens += resp.Metrics.PromptTokens
+			usage.CompletionTokens += resp.Metrics.CompletionTokens
+
+			if len(resp.ToolCalls) == 0 || tools == nil {
+				resp.ToolMessages = exchange
+				resp.Metrics.PromptTokens = usage.PromptTokens
+				resp.Metrics.CompletionTokens = usage.CompletionTokens
+				if send(Event{Kind: EventStats, Metrics: resp.Metrics}) != nil {
+					return
 				}
+				send(Event{Kind: EventDone, Response: resp})
+				return
+			}
+			if round == maxToolRounds {
+				send(Event{Kind: EventError, Err: fmt.Errorf("gave up after %d rounds of tool calls", maxToolRounds)})
+				return
 			}
-		}()
-
-		return nil
-	}
-}
-
-// sendFullResponse delivers the aggregated response and respects context cancellation
-func sendFullResponse(ctx context.Context, ch chan<- string, response string) bool {
-	if ch == nil || response == "" {
-		return false
-	}
 
-	select {
-	case ch <- response:
-		return true
-	case <-ctx.Done():
-		return false
-	}
-}
+			call := fs.Message{Role: fs.RoleAssistant, Content: resp.Content, ToolCalls: resp.ToolCalls}
+			messages = append(messages[:len(messages):len(messages)], call)
+			exchange = append(exchange, call)
+			for _, tc := range resp.ToolCalls {
+				ok, err := approve(tc)
+				if err != nil {
+					return
+				}
+				result := "The user declined to run this tool."
+				if ok {
+					result = tools.Run(ctx, tc)
+				}
+				if send(Event{Kind: EventToolResult, ToolCall: &tc, Text: result}) != nil {
+					return
+				}
 
-// NextTokenCmd waits for the next token or error/end signal.
-func NextTokenCmd(ch <-chan string, errCh <-chan error) tea.Cmd {
-	return func() tea.Msg {
-		select {
-		case err := <-errCh:
-			if err != nil {
-				// log.Printf("Received error in NextTokenCmd: %v", err)
-				return types.StreamErrMsg{Err: err}
-			}
-			return io.EOF
-		case token, ok := <-ch:
-			if !ok {
-				// log.Println("Token channel closed")
-				return types.StreamEndMsg{}
+				msg := fs.Message{Role: fs.RoleTool, ToolName: tc.Name, Content: result}
+				messages = append(messages, msg)
+				exchange = append(exchange, msg)
 			}
-			if token == "" {
-				// log.Println("Received empty token")
-				return types.StreamEndMsg{}
-			}
-			// log.Printf("Received token, length: %d", len(token))
-			return types.TokenMsg(token)
 		}
-	}
+	}()
+
+	return events
 }
@@ -0,0 +1,124 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// fakeProvider streams a fixed list of tokens. With block set it waits for
+// the context to be cancelled after sending them instead of finishing.
+type fakeProvider struct {
+	tokens []string
+	block  bool
+}
+
+func (p *fakeProvider) Name() string { return "fake" }
+
+func (p *fakeProvider) ListModels(ctx context.Context) ([]string, error) { return nil, nil }
+
+func (p *fakeProvider) ChatStream(ctx context.Context, req ChatRequest, onToken func(string) error) (*ChatResponse, error) {
+	for _, tok := range p.tokens {
+		if err := onToken(tok); err != nil {
+			return nil, err
+		}
+	}
+	if p.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &ChatResponse{
+		Model:   req.Model,
+		Content: strings.Join(p.tokens, ""),
+		Metrics: fs.Metrics{PromptTokens: 3, CompletionTokens: len(p.tokens)},
+	}, nil
+}
+
+// manyTokens returns n distinct tokens
+func manyTokens(n int) []string {
+	tokens := make([]string, n)
+	for i := range tokens {
+		tokens[i] = fmt.Sprintf("t%d ", i)
+	}
+	return tokens
+}
+
+func TestStreamEventsSlowConsumer(t *testing.T) {
+	provider := &fakeProvider{tokens: manyTokens(eventBuffer * 4)}
+	events := StreamEvents(context.Background(), provider, ChatRequest{Model: "fake"})
+
+	var kinds []EventKind
+	var text strings.Builder
+	var resp *ChatResponse
+	for ev := range events {
+		// Fall behind so the stream has to wait for the buffer to drain
+		time.Sleep(100 * time.Microsecond)
+		kinds = append(kinds, ev.Kind)
+		switch ev.Kind {
+		case EventToken:
+			text.WriteString(ev.Text)
+		case EventDone:
+			resp = ev.Response
+		case EventError:
+			t.Fatalf("unexpected error event: %v", ev.Err)
+		}
+	}
+
+	if resp == nil {
+		t.Fatal("stream ended without a done event")
+	}
+	if text.String() != resp.Content {
+		t.Errorf("tokens add up to %d bytes, want the %d of resp.Content", text.Len(), len(resp.Content))
+	}
+	if want := strings.Join(provider.tokens, ""); resp.Content != want {
+		t.Errorf("resp.Content has %d bytes, want %d", len(resp.Content), len(want))
+	}
+
+	n := len(kinds)
+	if n != len(provider.tokens)+3 {
+		t.Fatalf("got %d events, want start, %d tokens, stats and done", n, len(provider.tokens))
+	}
+	if kinds[0] != EventStart {
+		t.Errorf("first event is %v, want start", kinds[0])
+	}
+	for i, k := range kinds[1 : n-2] {
+		if k != EventToken {
+			t.Fatalf("event %d is %v, want token", i+1, k)
+		}
+	}
+	if kinds[n-2] != EventStats || kinds[n-1] != EventDone {
+		t.Errorf("last events are %v, %v, want stats, done", kinds[n-2], kinds[n-1])
+	}
+}
+
+func TestStreamEventsCancel(t *testing.T) {
+	provider := &fakeProvider{tokens: manyTokens(eventBuffer * 2), block: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := StreamEvents(ctx, provider, ChatRequest{Model: "fake"})
+
+	// Read a few tokens, then stop; the stream is blocked on the full buffer
+	for i := 0; i < 5; i++ {
+		<-events
+	}
+	cancel()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if ev.Kind == EventDone || ev.Kind == EventError || ev.Kind == EventStats {
+				t.Fatalf("got a %v event after cancelling", ev.Kind)
+			}
+		case <-timeout:
+			t.Fatal("channel was not closed after cancelling")
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// DefaultRepairs is how often AskStructured asks the model to fix an invalid answer
+const DefaultRepairs = 2
+
+// SchemaError is returned when the model's answer still doesn't match the
+// schema after all repair attempts
+type SchemaError struct {
+	Output string // The last answer
+	Err    error  // Why it was rejected
+}
+
+func (e *SchemaError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SchemaError) Unwrap() error {
+	return e.Err
+}
+
+// AskStructured asks for an answer following a JSON Schema. The schema is
+// sent as the request's format, so backends that support structured output
+// constrain the answer to it, and is also described to the model in a
+// system message for backends that don't. The answer is validated; if it
+// doesn't match, the model is shown the problems and asked to repair it, up
+// to repairs more times.
+//
+// Example:
+//
+//	schema, _ := llm.ParseSchema([]byte(`{"type": "object", "properties": {"answer": {"type": "string"}}, "required": ["answer"]}`))
+//	out, _, err := llm.AskStructured(ctx, provider, llm.ChatRequest{Model: "llama3.1", Messages: msgs}, schema, llm.DefaultRepairs)
+//
+// Parameters:
+//   - ctx: Cancels the request
+//   - provider: The backend to ask
+//   - req: The chat request; its Format is replaced by the schema
+//   - schema: The schema the answer must follow
+//   - repairs: How many repair attempts to make after the first answer
+//
+// Returns:
+//   - json.RawMessage: The validated answer
+//   - *ChatResponse: The response of the final attempt, with its metrics
+//   - error: A *SchemaError if no answer matched, or the request's error
+func AskStructured(ctx context.Context, provider ChatProvider, req ChatRequest, schema *Schema, repairs int) (json.RawMessage, *ChatResponse, error) {
+	req.Format = schema.JSON()
+	instruction := fs.Message{
+		Role:    fs.RoleSystem,
+		Content: "Respond only with a JSON value that matches this JSON Schema, without any other text:\n" + string(schema.JSON()),
+	}
+	messages := append([]fs.Message{instruction}, req.Messages...)
+
+	for attempt := 0; ; attempt++ {
+		req.Messages = messages
+		resp, err := Stream(ctx, provider, req, func(string) error { return nil })
+		if err != nil {
+			return nil, nil, err
+		}
+
+		output := extractJSON(resp.Content)
+		err = schema.Validate([]byte(output))
+		if err == nil {
+			return json.RawMessage(output), resp, nil
+		}
+		if attempt >= repairs {
+			return nil, resp, &SchemaError{Output: resp.Content, Err: err}
+		}
+
+		messages = append(messages[:len(messages):len(messages)],
+			fs.Message{Role: fs.RoleAssistant, Content: resp.Content},
+			fs.Message{Role: fs.RoleUser, Content: repairPrompt(err)},
+		)
+	}
+}
+
+// repairPrompt asks the model to fix an answer that was rejected
+func repairPrompt(err error) string {
+	var sb strings.Builder
+	sb.WriteString("Your answer does not match the JSON Schema:\n")
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		for _, p := range verr.Problems {
+			fmt.Fprintf(&sb, "- %s\n", p)
+		}
+	} else {
+		fmt.Fprintf(&sb, "- %v\n", err)
+	}
+	sb.WriteString("Reply again with only the corrected JSON.")
+	return sb.String()
+}
+
+// extractJSON strips whitespace and a surrounding Markdown code fence, which
+// models often add even when asked for JSON only
+func extractJSON(content string) string {
+	s := strings.TrimSpace(content)
+	if strings.HasPrefix(s, "```") && strings.HasSuffix(s, "```") && len(s) > 6 {
+		s = strings.TrimSuffix(strings.TrimPrefix(s, "```"), "```")
+		// Drop the language tag on the opening fence
+		if i := strings.IndexByte(s, '\n'); i >= 0 && !strings.ContainsAny(s[:i], "{[\"") {
+			s = s[i+1:]
+		}
+		s = strings.TrimSpace(s)
+	}
+	return s
+}
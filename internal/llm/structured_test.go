@@ -0,0 +1,167 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// scriptedProvider answers each request with the next of its answers and
+// records the requests it was sent
+type scriptedProvider struct {
+	answers  []string
+	requests []ChatRequest
+}
+
+func (p *scriptedProvider) Name() string { return "scripted" }
+
+func (p *scriptedProvider) ListModels(ctx context.Context) ([]string, error) { return nil, nil }
+
+func (p *scriptedProvider) ChatStream(ctx context.Context, req ChatRequest, onToken func(string) error) (*ChatResponse, error) {
+	p.requests = append(p.requests, req)
+	if len(p.requests) > len(p.answers) {
+		return nil, errors.New("no more answers")
+	}
+	answer := p.answers[len(p.requests)-1]
+	if err := onToken(answer); err != nil {
+		return nil, err
+	}
+	return &ChatResponse{Content: answer}, nil
+}
+
+const personSchema = `{"type": "object", "properties": {"name": {"type": "string"}, "age": {"type": "integer"}}, "required": ["name", "age"], "additionalProperties": false}`
+
+func TestAskStructuredValidFirstTime(t *testing.T) {
+	schema, err := ParseSchema([]byte(personSchema))
+	if err != nil {
+		t.Fatalf("ParseSchema: %v", err)
+	}
+	provider := &scriptedProvider{answers: []string{"```json\n{\"name\": \"Ada\", \"age\": 36}\n```"}}
+	req := ChatRequest{Model: "m", Messages: []fs.Message{{Role: fs.RoleUser, Content: "Who?"}}}
+
+	out, resp, err := AskStructured(context.Background(), provider, req, schema, DefaultRepairs)
+	if err != nil {
+		t.Fatalf("AskStructured: %v", err)
+	}
+	if string(out) != `{"name": "Ada", "age": 36}` {
+		t.Errorf("out = %s, want the JSON without its fence", out)
+	}
+	if resp == nil || len(provider.requests) != 1 {
+		t.Fatalf("sent %d requests, want 1", len(provider.requests))
+	}
+
+	sent := provider.requests[0]
+	if string(sent.Format) != string(schema.JSON()) {
+		t.Errorf("Format = %s, want the schema", sent.Format)
+	}
+	if len(sent.Messages) != 2 || sent.Messages[0].Role != fs.RoleSystem || !strings.Contains(sent.Messages[0].Content, string(schema.JSON())) {
+		t.Errorf("Messages = %+v, want the schema instruction followed by the question", sent.Messages)
+	}
+}
+
+func TestAskStructuredRepairs(t *testing.T) {
+	schema, err := ParseSchema([]byte(personSchema))
+	if err != nil {
+		t.Fatalf("ParseSchema: %v", err)
+	}
+	provider := &scriptedProvider{answers: []string{
+		`{"name": "Ada", "age": "36"}`,
+		`{"name": "Ada", "age": 36, "born": 1815}`,
+		`{"name": "Ada", "age": 36}`,
+	}}
+	req := ChatRequest{Model: "m", Messages: []fs.Message{{Role: fs.RoleUser, Content: "Who?"}}}
+
+	out, _, err := AskStructured(context.Background(), provider, req, schema, 2)
+	if err != nil {
+		t.Fatalf("AskStructured: %v", err)
+	}
+	if string(out) != `{"name": "Ada", "age": 36}` {
+		t.Errorf("out = %s, want the third answer", out)
+	}
+	if len(provider.requests) != 3 {
+		t.Fatalf("sent %d requests, want 3", len(provider.requests))
+	}
+
+	// Each repair shows the model its answer and what was wrong with it
+	second := provider.requests[1].Messages
+	if len(second) != 4 {
+		t.Fatalf("repair request has %d messages, want 4", len(second))
+	}
+	if second[2].Role != fs.RoleAssistant || second[2].Content != provider.answers[0] {
+		t.Errorf("repair request repeats %+v, want the first answer", second[2])
+	}
+	if second[3].Role != fs.RoleUser || !strings.Contains(second[3].Content, "$.age: expected integer, got string") {
+		t.Errorf("repair prompt = %q, want it to list the problem", second[3].Content)
+	}
+	third := provider.requests[2].Messages
+	if len(third) != 6 || !strings.Contains(third[5].Content, `unexpected property "born"`) {
+		t.Errorf("second repair = %+v, want the history plus the new problem", third)
+	}
+}
+
+func TestAskStructuredGivesUp(t *testing.T) {
+	schema, err := ParseSchema([]byte(personSchema))
+	if err != nil {
+		t.Fatalf("ParseSchema: %v", err)
+	}
+	provider := &scriptedProvider{answers: []string{"not json", `{"name": "Ada"}`}}
+
+	_, resp, err := AskStructured(context.Background(), provider, ChatRequest{Model: "m"}, schema, 1)
+	var serr *SchemaError
+	if !errors.As(err, &serr) {
+		t.Fatalf("err = %v, want a *SchemaError", err)
+	}
+	if serr.Output != `{"name": "Ada"}` || resp == nil {
+		t.Errorf("SchemaError.Output = %q, want the last answer", serr.Output)
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Errorf("err = %v, want it to wrap the *ValidationError", err)
+	}
+	if len(provider.requests) != 2 {
+		t.Errorf("sent %d requests, want 2", len(provider.requests))
+	}
+	// Invalid JSON is reported to the model like any other problem
+	if repair := provider.requests[1].Messages; !strings.Contains(repair[len(repair)-1].Content, "not valid JSON") {
+		t.Errorf("repair prompt = %q, want it to say the answer wasn't JSON", repair[len(repair)-1].Content)
+	}
+}
+
+func TestAskStructuredRequestError(t *testing.T) {
+	schema, err := ParseSchema([]byte(personSchema))
+	if err != nil {
+		t.Fatalf("ParseSchema: %v", err)
+	}
+	_, _, err = AskStructured(context.Background(), &scriptedProvider{}, ChatRequest{Model: "m"}, schema, DefaultRepairs)
+	var serr *SchemaError
+	if err == nil || errors.As(err, &serr) {
+		t.Errorf("err = %v, want the request's error", err)
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"bare", `{"a": 1}`, `{"a": 1}`},
+		{"whitespace", "\n  {\"a\": 1}  \n", `{"a": 1}`},
+		{"fence", "```\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"fence with language", "```json\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"fence on one line", "```{\"a\": 1}```", `{"a": 1}`},
+		{"array in fence", "```json\n[1, 2]\n```", `[1, 2]`},
+		{"string in fence", "```\n\"text\"\n```", `"text"`},
+		{"unclosed fence", "```json\n{\"a\": 1}", "```json\n{\"a\": 1}"},
+		{"only fences", "``````", "``````"},
+		{"text around", "Here you go: {\"a\": 1}", "Here you go: {\"a\": 1}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractJSON(tt.in); got != tt.want {
+				t.Errorf("extractJSON(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
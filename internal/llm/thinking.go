@@ -0,0 +1,133 @@
+package llm
+
+import (
+	"strings"
+)
+
+// Tags reasoning models wrap their thinking in. Providers whose backend
+// returns thinking separately pass it on wrapped in the same tags, so it is
+// split from the answer in one place.
+const (
+	thinkOpen  = "<think>"
+	thinkClose = "</think>"
+)
+
+// thinkSplitter separates <think>…</think> blocks from an answer as it
+// streams in, coping with tags split across chunks
+type thinkSplitter struct {
+	inThink  bool
+	sawThink bool   // A thinking block has been seen
+	started  bool   // Answer text has been returned after a thinking block
+	pending  string // The end of the last chunk, which may be the start of a tag
+}
+
+// feed splits the next chunk into answer and thinking text
+func (t *thinkSplitter) feed(s string) (answer, thinking string) {
+	buf := t.pending + s
+	t.pending = ""
+
+	var a, th strings.Builder
+	emit := func(text string) {
+		if t.inThink {
+			th.WriteString(text)
+		} else {
+			a.WriteString(text)
+		}
+	}
+	for buf != "" {
+		tag := thinkOpen
+		if t.inThink {
+			tag = thinkClose
+		}
+		if i := strings.Index(buf, tag); i >= 0 {
+			emit(buf[:i])
+			buf = buf[i+len(tag):]
+			t.inThink = !t.inThink
+			t.sawThink = true
+			continue
+		}
+		// Hold back what could be the beginning of the tag
+		keep := partialSuffix(buf, tag)
+		emit(buf[:len(buf)-keep])
+		t.pending = buf[len(buf)-keep:]
+		break
+	}
+	return t.trimAnswer(a.String()), th.String()
+}
+
+// flush returns text held back at the end of the stream
+func (t *thinkSplitter) flush() (answer, thinking string) {
+	rest := t.pending
+	t.pending = ""
+	if t.inThink {
+		return "", rest
+	}
+	return t.trimAnswer(rest), ""
+}
+
+// trimAnswer drops the blank lines models put between their thinking and
+// the answer
+func (t *thinkSplitter) trimAnswer(s string) string {
+	if !t.sawThink || t.started {
+		return s
+	}
+	s = strings.TrimLeft(s, " \t\r\n")
+	t.started = s != ""
+	return s
+}
+
+// partialSuffix returns the length of the longest end of s that is the
+// start of tag
+func partialSuffix(s, tag string) int {
+	n := len(tag) - 1
+	if n > len(s) {
+		n = len(s)
+	}
+	for ; n > 0; n-- {
+		if strings.HasSuffix(s, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// SplitThinking separates the thinking blocks of a complete answer from the
+// answer itself
+//
+// Parameters:
+//   - content: The answer as the model wrote it
+//
+// Returns:
+//   - string: The answer without thinking
+//   - string: The thinking, or "" if there was none
+func SplitThinking(content string) (string, string) {
+	var t thinkSplitter
+	answer, thinking := t.feed(content)
+	a, th := t.flush()
+	return answer + a, strings.TrimSpace(thinking + th)
+}
+
+// thinkingTagger wraps thinking that a backend returns separately from the
+// answer in think tags, so it can be passed on with the answer's tokens
+type thinkingTagger struct {
+	inThink bool
+}
+
+// thinking returns a chunk of thinking ready to pass on
+func (t *thinkingTagger) thinking(s string) string {
+	if t.inThink {
+		return s
+	}
+	t.inThink = true
+	return thinkOpen + s
+}
+
+// answer returns a chunk of the answer ready to pass on, closing the
+// thinking block before it
+func (t *thinkingTagger) answer(s string) string {
+	if !t.inThink {
+		return s
+	}
+	t.inThink = false
+	return thinkClose + s
+}
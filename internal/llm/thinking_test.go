@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+// feedAll runs chunks through a splitter and flushes it
+func feedAll(chunks []string) (answer, thinking string) {
+	var t thinkSplitter
+	var a, th strings.Builder
+	for _, c := range chunks {
+		ans, thk := t.feed(c)
+		a.WriteString(ans)
+		th.WriteString(thk)
+	}
+	ans, thk := t.flush()
+	a.WriteString(ans)
+	th.WriteString(thk)
+	return a.String(), th.String()
+}
+
+func TestThinkSplitterChunks(t *testing.T) {
+	tests := []struct {
+		name     string
+		chunks   []string
+		answer   string
+		thinking string
+	}{
+		{"no thinking", []string{"Hello", " world"}, "Hello world", ""},
+		{"whole tags", []string{"<think>hmm</think>", "Hi"}, "Hi", "hmm"},
+		{"open tag split", []string{"<th", "ink>hmm</think>Hi"}, "Hi", "hmm"},
+		{"close tag split", []string{"<think>hmm</", "think>Hi"}, "Hi", "hmm"},
+		{"close tag split twice", []string{"<think>hmm<", "/thi", "nk>Hi"}, "Hi", "hmm"},
+		{"split after the tag", []string{"<think>", "hmm", "</think>", "Hi"}, "Hi", "hmm"},
+		{"one byte at a time", strings.Split("<think>a<b</think>\n\nc<d", ""), "c<d", "a<b"},
+		{"blank lines after thinking", []string{"<think>hmm</think>", "\n", "\n", "Hi\n\nthere"}, "Hi\n\nthere", "hmm"},
+		{"leading blank lines kept without thinking", []string{"\n", "Hi"}, "\nHi", ""},
+		{"less-than that is no tag", []string{"a <", "b"}, "a <b", ""},
+		{"tag start held at the end", []string{"a <thi"}, "a <thi", ""},
+		{"unclosed thinking", []string{"<think>still going</th"}, "", "still going</th"},
+		{"several blocks", []string{"<think>a</think>x<thi", "nk>b</think>y"}, "xy", "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			answer, thinking := feedAll(tt.chunks)
+			if answer != tt.answer || thinking != tt.thinking {
+				t.Errorf("got %q thinking %q, want %q thinking %q", answer, thinking, tt.answer, tt.thinking)
+			}
+		})
+	}
+}
+
+func TestThinkSplitterHoldsBackTagStart(t *testing.T) {
+	var s thinkSplitter
+	if answer, _ := s.feed("Hi <thi"); answer != "Hi " {
+		t.Errorf("feed returned %q, want the text before the possible tag", answer)
+	}
+	if answer, thinking := s.feed("nk>x"); answer != "" || thinking != "x" {
+		t.Errorf("feed returned %q thinking %q, want only thinking", answer, thinking)
+	}
+}
+
+func TestPartialSuffix(t *testing.T) {
+	tests := []struct {
+		s, tag string
+		want   int
+	}{
+		{"abc", thinkOpen, 0},
+		{"abc<", thinkOpen, 1},
+		{"abc<thin", thinkOpen, 5},
+		{"<think", thinkOpen, 6},
+		// A whole tag is found by the caller, never held back
+		{"<think>", thinkOpen, 0},
+		{"<", thinkClose, 1},
+		{"x</think", thinkClose, 7},
+		{"x</tx", thinkClose, 0},
+		{"", thinkOpen, 0},
+	}
+	for _, tt := range tests {
+		if got := partialSuffix(tt.s, tt.tag); got != tt.want {
+			t.Errorf("partialSuffix(%q, %q) = %d, want %d", tt.s, tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestSplitThinking(t *testing.T) {
+	answer, thinking := SplitThinking("<think>\n  step one\n</think>\n\nThe answer")
+	if answer != "The answer" || thinking != "step one" {
+		t.Errorf("SplitThinking = %q, %q, want the answer and the trimmed thinking", answer, thinking)
+	}
+}
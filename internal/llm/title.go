@@ -0,0 +1,79 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// ConversationTitle is a generated title and synopsis of a conversation
+type ConversationTitle struct {
+	Title    string `json:"title"`
+	Synopsis string `json:"summary"`
+}
+
+// titleSchema constrains what TitleConversation asks for
+var titleSchema = mustParseSchema(`{
+	"type": "object",
+	"properties": {
+		"title": {"type": "string", "minLength": 1},
+		"summary": {"type": "string", "minLength": 1}
+	},
+	"required": ["title", "summary"]
+}`)
+
+// titlePrompt asks the model to title and summarize the conversation above
+const titlePrompt = `The conversation above has ended. Give it a short, specific title of at most 8 words that someone browsing their history would recognize, and summarize it in one paragraph of at most 80 words: what the user wanted and what was concluded. Write in the third person ("The user asked…") and don't use Markdown.`
+
+// maxTitleLength caps the length of a generated title
+const maxTitleLength = 80
+
+// TitleConversation asks the model for a short title and a one-paragraph
+// synopsis of a conversation. Long conversations are read from their
+// context summary onwards, so a small model can title them.
+//
+// Parameters:
+//   - ctx: Cancels the request
+//   - provider: The backend to ask
+//   - req: The model and options to ask with; its messages are replaced
+//   - previous: The summary of the messages before these, or ""
+//   - messages: The messages to title
+//
+// Returns:
+//   - ConversationTitle: The title, on one line, and the synopsis
+//   - error: An error if the request fails or the answer can't be parsed
+func TitleConversation(ctx context.Context, provider ChatProvider, req ChatRequest, previous string, messages []fs.Message) (ConversationTitle, error) {
+	var transcript []fs.Message
+	if previous != "" {
+		transcript = append(transcript, fs.Message{Role: fs.RoleSystem, Content: fs.SummaryPrefix + previous})
+	}
+	for _, msg := range messages {
+		// System prompts and tool exchanges say little about what was discussed
+		if msg.Role == fs.RoleSystem || msg.Role == fs.RoleTool || len(msg.ToolCalls) > 0 {
+			continue
+		}
+		transcript = append(transcript, fs.Message{Role: msg.Role, Content: msg.Content})
+	}
+	transcript = append(transcript, fs.Message{Role: fs.RoleUser, Content: titlePrompt})
+
+	req.Messages = transcript
+	req.Tools = nil
+	out, _, err := AskStructured(ctx, provider, req, titleSchema, DefaultRepairs)
+	if err != nil {
+		return ConversationTitle{}, fmt.Errorf("failed to title the conversation: %w", err)
+	}
+
+	var title ConversationTitle
+	if err := json.Unmarshal(out, &title); err != nil {
+		return ConversationTitle{}, fmt.Errorf("failed to parse the title: %w", err)
+	}
+	title.Title = strings.Trim(strings.Join(strings.Fields(title.Title), " "), `"'.`)
+	if r := []rune(title.Title); len(r) > maxTitleLength {
+		title.Title = strings.TrimSpace(string(r[:maxTitleLength])) + "…"
+	}
+	title.Synopsis = strings.TrimSpace(title.Synopsis)
+	return title, nil
+}
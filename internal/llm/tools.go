@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// maxToolRounds limits how often the model may call tools before answering
+const maxToolRounds = 8
+
+// ToolSpec describes a tool to the model
+type ToolSpec struct {
+	Name        string
+	Description string
+	Parameters  json.RawMessage // JSON Schema of the arguments object
+}
+
+// Tool is a function the model may ask to run
+type Tool struct {
+	ToolSpec
+	// Run executes the tool with the arguments the model passed and returns
+	// the text sent back to the model
+	Run func(ctx context.Context, args json.RawMessage) (string, error)
+}
+
+// Toolbox is the set of tools offered to the model
+type Toolbox struct {
+	tools []Tool
+}
+
+// NewToolbox creates a toolbox offering the given tools
+func NewToolbox(tools ...Tool) *Toolbox {
+	return &Toolbox{tools: tools}
+}
+
+// Specs returns the descriptions of the tools, for the request
+func (t *Toolbox) Specs() []ToolSpec {
+	specs := make([]ToolSpec, len(t.tools))
+	for i, tool := range t.tools {
+		specs[i] = tool.ToolSpec
+	}
+	return specs
+}
+
+// Run executes a tool call. Failures are returned as text so the model can
+// see what went wrong and try something else.
+func (t *Toolbox) Run(ctx context.Context, call fs.ToolCall) string {
+	for _, tool := range t.tools {
+		if tool.Name != call.Name {
+			continue
+		}
+		args := call.Arguments
+		if len(args) == 0 {
+			args = json.RawMessage("{}")
+		}
+		result, err := tool.Run(ctx, args)
+		if err != nil {
+			return fmt.Sprintf("error: %v", err)
+		}
+		return result
+	}
+	return fmt.Sprintf("error: there is no tool named %q", call.Name)
+}
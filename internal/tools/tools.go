@@ -0,0 +1,341 @@
+// Package tools provides the built-in tools the assistant can ask to run:
+// reading files, listing directories, searching files and searching the vault.
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/VarunSharma3520/AskAI/internal/app"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
+)
+
+// Limits that keep tool results small enough for the model's context
+const (
+	maxReadBytes   = 64 * 1024
+	maxListEntries = 500
+	maxGrepMatches = 100
+	maxGrepFile    = 1 << 20
+	maxVaultHits   = 20
+)
+
+// skipDirs are not searched by grep
+var skipDirs = map[string]bool{".git": true, "node_modules": true, "vendor": true}
+
+// Builtin returns the built-in tools. File tools only see files below root;
+// search_vault searches the knowledge base kb.
+//
+// Parameters:
+//   - root: The directory the file tools are confined to
+//   - a: The application, used to search the vault; may be nil
+//   - kb: The knowledge base to search
+//
+// Returns:
+//   - *llm.Toolbox: The tools
+func Builtin(root string, a *app.App, kb string) *llm.Toolbox {
+	// Compare real paths, so a root reached through a symlink still contains its files
+	if real, err := filepath.EvalSymlinks(root); err == nil {
+		root = real
+	}
+	files := fileTools{root: root}
+	return llm.NewToolbox(
+		llm.Tool{
+			ToolSpec: llm.ToolSpec{
+				Name:        "read_file",
+				Description: "Read a text file. Paths are relative to the working directory.",
+				Parameters:  schema(`{"path": {"type": "string", "description": "Path of the file"}}`, "path"),
+			},
+			Run: files.readFile,
+		},
+		llm.Tool{
+			ToolSpec: llm.ToolSpec{
+				Name:        "list_directory",
+				Description: "List the files and directories in a directory. Directories end with a slash.",
+				Parameters:  schema(`{"path": {"type": "string", "description": "Path of the directory, default \".\""}}`),
+			},
+			Run: files.listDirectory,
+		},
+		llm.Tool{
+			ToolSpec: llm.ToolSpec{
+				Name:        "grep",
+				Description: "Search files below a directory for lines matching a regular expression. Returns file:line: text for each match.",
+				Parameters: schema(`{
+					"pattern": {"type": "string", "description": "Go regular expression"},
+					"path": {"type": "string", "description": "Directory or file to search, default \".\""},
+					"glob": {"type": "string", "description": "Only search files whose name matches this glob, e.g. \"*.go\""}
+				}`, "pattern"),
+			},
+			Run: files.grep,
+		},
+		llm.Tool{
+			ToolSpec: llm.ToolSpec{
+				Name:        "search_vault",
+				Description: "Search the user's saved questions and answers for ones similar to a query.",
+				Parameters: schema(`{
+					"query": {"type": "string", "description": "What to search for"},
+					"limit": {"type": "integer", "description": "Maximum number of results, default 5"}
+				}`, "query"),
+			},
+			Run: func(ctx context.Context, args json.RawMessage) (string, error) {
+				return searchVault(a, kb, args)
+			},
+		},
+	)
+}
+
+// schema builds the JSON Schema of an arguments object
+func schema(properties string, required ...string) json.RawMessage {
+	if required == nil {
+		required = []string{}
+	}
+	req, _ := json.Marshal(required)
+	return json.RawMessage(fmt.Sprintf(`{"type": "object", "properties": %s, "required": %s}`, properties, req))
+}
+
+// fileTools implements the tools working on files below root
+type fileTools struct {
+	root string
+}
+
+// resolve turns a path from the model into a real path below root. The path
+// is checked as written and again with its symlinks followed, so a link
+// inside root can't lead outside it.
+func (t fileTools) resolve(path string) (string, error) {
+	if path == "" {
+		path = "."
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(t.root, path)
+	}
+	path = filepath.Clean(path)
+	if !t.contains(path) {
+		return "", fmt.Errorf("%s is outside the working directory", path)
+	}
+
+	real, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	if !t.contains(real) {
+		return "", fmt.Errorf("%s links outside the working directory", t.display(path))
+	}
+	return real, nil
+}
+
+// contains reports whether a clean absolute path is root or below it
+func (t fileTools) contains(path string) bool {
+	rel, err := filepath.Rel(t.root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// display shows a path relative to root
+func (t fileTools) display(path string) string {
+	if rel, err := filepath.Rel(t.root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// readFile implements read_file
+func (t fileTools) readFile(ctx context.Context, args json.RawMessage) (string, error) {
+	var a struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(args, &a); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	path, err := t.resolve(a.Path)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxReadBytes+1))
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 0 {
+		return "(empty file)", nil
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return "", fmt.Errorf("%s is a binary file", t.display(path))
+	}
+	if len(data) > maxReadBytes {
+		return string(data[:maxReadBytes]) + fmt.Sprintf("\n[truncated after %d bytes]", maxReadBytes), nil
+	}
+	return string(data), nil
+}
+
+// listDirectory implements list_directory
+func (t fileTools) listDirectory(ctx context.Context, args json.RawMessage) (string, error) {
+	var a struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(args, &a); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	path, err := t.resolve(a.Path)
+	if err != nil {
+		return "", err
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() {
+			name += "/"
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		return "(empty directory)", nil
+	}
+	if len(names) > maxListEntries {
+		return strings.Join(names[:maxListEntries], "\n") + fmt.Sprintf("\n[%d more entries]", len(names)-maxListEntries), nil
+	}
+	return strings.Join(names, "\n"), nil
+}
+
+// grep implements grep
+func (t fileTools) grep(ctx context.Context, args json.RawMessage) (string, error) {
+	var a struct {
+		Pattern string `json:"pattern"`
+		Path    string `json:"path"`
+		Glob    string `json:"glob"`
+	}
+	if err := json.Unmarshal(args, &a); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	re, err := regexp.Compile(a.Pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern: %w", err)
+	}
+	if a.Glob != "" {
+		if _, err := filepath.Match(a.Glob, ""); err != nil {
+			return "", fmt.Errorf("invalid glob: %w", err)
+		}
+	}
+	root, err := t.resolve(a.Path)
+	if err != nil {
+		return "", err
+	}
+
+	var matches []string
+	errLimit := errors.New("match limit reached")
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil // Skip what can't be read
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			if path != root && skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if a.Glob != "" {
+			if ok, _ := filepath.Match(a.Glob, d.Name()); !ok {
+				return nil
+			}
+		}
+		file := path
+		if d.Type()&fs.ModeSymlink != 0 {
+			// Only search links to files inside root
+			if file, err = t.resolve(path); err != nil {
+				return nil
+			}
+		}
+		if info, err := os.Stat(file); err != nil || !info.Mode().IsRegular() || info.Size() > maxGrepFile {
+			return nil
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil || bytes.IndexByte(data, 0) >= 0 {
+			return nil
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 64*1024), maxGrepFile)
+		for line := 1; scanner.Scan(); line++ {
+			if re.Match(scanner.Bytes()) {
+				matches = append(matches, fmt.Sprintf("%s:%d: %s", t.display(path), line, strings.TrimSpace(scanner.Text())))
+				if len(matches) == maxGrepMatches {
+					return errLimit
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errLimit) {
+		return "", err
+	}
+
+	if len(matches) == 0 {
+		return "no matches", nil
+	}
+	result := strings.Join(matches, "\n")
+	if errors.Is(err, errLimit) {
+		result += fmt.Sprintf("\n[stopped after %d matches]", maxGrepMatches)
+	}
+	return result, nil
+}
+
+// searchVault implements search_vault
+func searchVault(a *app.App, kb string, args json.RawMessage) (string, error) {
+	var q struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit"`
+	}
+	if err := json.Unmarshal(args, &q); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	if a == nil {
+		return "", errors.New("the vault is not available")
+	}
+	if q.Limit <= 0 {
+		q.Limit = 5
+	}
+	if q.Limit > maxVaultHits {
+		q.Limit = maxVaultHits
+	}
+
+	hits, err := a.SearchKnowledgeBases([]string{kb}, q.Query, q.Limit)
+	if err != nil {
+		return "", err
+	}
+	if len(hits) == 0 {
+		return "no similar questions found", nil
+	}
+
+	var sb strings.Builder
+	for i, hit := range hits {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "Q: %s\nA: %s", hit.Question, hit.Answer)
+	}
+	return sb.String(), nil
+}
@@ -7,16 +7,16 @@ type ScreenMode string
 const (
 	ModeChat    ScreenMode = "chat"
 	ModeOptions ScreenMode = "options"
+	ModeDedupe  ScreenMode = "dedupe"
+	ModeHistory ScreenMode = "history"
+	// ModeTemplate is the form filling in a prompt template's placeholders
+	ModeTemplate ScreenMode = "template"
+	// ModeCompare shows the answers of several models side by side
+	ModeCompare ScreenMode = "compare"
+	// ModeMemories lists the long-term memories for review
+	ModeMemories ScreenMode = "memories"
 )
 
-type TokenMsg string
-
-type StreamEndMsg struct{}
-
-type StreamErrMsg struct{ Err error }
-
-func (e StreamErrMsg) Error() string { return e.Err.Error() }
-
 // StatusMsg represents a status message to be displayed in the UI
 type StatusMsg struct {
 	Message  string
@@ -0,0 +1,59 @@
+// Package ui provides the terminal user interface components for the AskAI application.
+// This file handles images attached to the next question.
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+)
+
+// imageCommand reports whether the chat input attaches an image, as in
+// "/image shot.png" or "/i shot.png", and returns the path
+func imageCommand(input string) (string, bool) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 || (fields[0] != "/i" && fields[0] != "/image") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(input), fields[0])), true
+}
+
+// attachImage copies an image into the vault and attaches it to the next
+// question. "/image clear" drops the attached images.
+func (m *Model) attachImage(file string) {
+	switch file {
+	case "":
+		m.setStatus("Usage: /image <path>, or /image clear", 3*time.Second)
+		return
+	case "clear":
+		m.Attachments = nil
+		m.TextInput.SetValue("")
+		m.setStatus("Attachments cleared", 2*time.Second)
+		return
+	}
+
+	ref, err := fs.SaveAttachment(m.VaultPath, file)
+	if err != nil {
+		m.setStatus(fmt.Sprintf("Failed to attach image: %v", err), 5*time.Second)
+		return
+	}
+	m.Attachments = append(m.Attachments, ref)
+	m.TextInput.SetValue("")
+	m.setStatus(fmt.Sprintf("📎 Attached %s", filepath.Base(file)), 2*time.Second)
+}
+
+// takeAttachments returns the attached images and clears them, as they go
+// with a single question
+func (m *Model) takeAttachments() []string {
+	images := m.Attachments
+	m.Attachments = nil
+	return images
+}
+
+// renderAttachments renders the images attached to the next question
+func (m Model) renderAttachments() string {
+	return helpStyle.Render(fmt.Sprintf("📎 %d image(s) attached to the next question · /image clear to drop them", len(m.Attachments)))
+}
@@ -0,0 +1,405 @@
+// Package ui provides the terminal user interface components for the AskAI application.
+// This file handles compare mode: asking several models the same question side by side.
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
+	"github.com/VarunSharma3520/AskAI/internal/types"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Layout of the compare panes
+const (
+	comparePanesPerRow = 3
+	compareRowWidth    = 120
+	comparePaneText    = 1500 // Runes of an answer shown while comparing
+)
+
+// comparePane is one model's answer in compare mode
+type comparePane struct {
+	model    string
+	text     string
+	thinking bool // The model is reasoning before its answer
+	started  time.Time
+	first    time.Time
+	chunks   int
+	resp     *llm.ChatResponse // The completed answer, or nil
+	err      error
+	done     bool // The stream ended, completed or not
+	stored   bool
+}
+
+// comparison is a question being answered by several models at once
+type comparison struct {
+	id       int
+	question string
+	images   []string // Images attached to the question, as paths inside the vault
+	persona  string
+	options  llm.Options
+	panes    []*comparePane
+	sel      int
+	cancel   context.CancelFunc
+}
+
+// running reports whether any model is still answering
+func (c *comparison) running() bool {
+	for _, p := range c.panes {
+		if !p.done {
+			return true
+		}
+	}
+	return false
+}
+
+// compareMsg carries the next event of one pane's stream
+type compareMsg struct {
+	id     int
+	pane   int
+	events <-chan llm.Event
+	event  llm.Event
+	closed bool // The stream ended without a done or error event
+}
+
+// nextCompareMsg waits for the next event of a pane's stream. Each pane has
+// one outstanding, so every pane's events are handled in order.
+func nextCompareMsg(id, pane int, events <-chan llm.Event) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := <-events
+		return compareMsg{id: id, pane: pane, events: events, event: ev, closed: !ok}
+	}
+}
+
+// compareStoredMsg is sent once compared answers have been stored
+type compareStoredMsg struct {
+	id    int
+	panes []int // The panes whose answers were stored
+	err   error
+}
+
+// compareCommand reports whether the chat input asks to compare models, as
+// in "/compare question" or "/c question", and returns the question
+func compareCommand(input string) (string, bool) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 || (fields[0] != "/c" && fields[0] != "/compare") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(input), fields[0])), true
+}
+
+// startCompare sends question to every compare model at once and switches to
+// the compare screen. The question is asked on its own, without the
+// conversation, using the persona and generation options of the chat.
+func (m *Model) startCompare(question string) (tea.Model, tea.Cmd) {
+	if question == "" {
+		m.setStatus("Usage: /compare <question>", 3*time.Second)
+		return m, nil
+	}
+	if len(m.CompareModels) < 2 {
+		m.setStatus("Set at least two models under Compare models in the options (Ctrl+O)", 5*time.Second)
+		return m, nil
+	}
+	m.stopCompare()
+
+	var messages []fs.Message
+	if m.Persona != nil && m.Persona.SystemPrompt != "" {
+		messages = append(messages, fs.Message{Role: fs.RoleSystem, Content: m.Persona.SystemPrompt, Time: time.Now()})
+	}
+	images := m.takeAttachments()
+	messages = append(messages, fs.Message{Role: fs.RoleUser, Content: question, Images: images, Time: time.Now()})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.compareID++
+	c := &comparison{
+		id:       m.compareID,
+		question: question,
+		images:   images,
+		persona:  m.personaName(),
+		options:  llm.Options{Temperature: m.Temperature, GenerationOptions: m.GenOptions},
+		cancel:   cancel,
+	}
+
+	cmds := []tea.Cmd{metricsTick()}
+	for i, model := range m.CompareModels {
+		c.panes = append(c.panes, &comparePane{model: model, started: time.Now()})
+		events := llm.StreamEvents(ctx, m.Provider, llm.ChatRequest{
+			Model:     model,
+			Messages:  messages,
+			Options:   c.options,
+			VaultPath: m.VaultPath,
+		})
+		cmds = append(cmds, nextCompareMsg(c.id, i, events))
+	}
+
+	m.Compare = c
+	m.LastQuestion = question
+	m.ScreenMode = types.ModeCompare
+	return m, tea.Batch(cmds...)
+}
+
+// handleCompareMsg handles the next event of a pane's stream
+func (m *Model) handleCompareMsg(msg compareMsg) (tea.Model, tea.Cmd) {
+	c := m.Compare
+	if c == nil || msg.id != c.id {
+		return m, nil
+	}
+	p := c.panes[msg.pane]
+	if msg.closed {
+		p.done = true
+		return m, nil
+	}
+
+	switch ev := msg.event; ev.Kind {
+	case llm.EventToken, llm.EventThinking:
+		if ev.Kind == llm.EventToken {
+			p.text += ev.Text
+		}
+		p.thinking = ev.Kind == llm.EventThinking
+		if p.first.IsZero() {
+			p.first = time.Now()
+		}
+		p.chunks++
+
+	case llm.EventDone:
+		p.resp = ev.Response
+		p.done = true
+		return m, nil
+
+	case llm.EventError:
+		p.err = ev.Err
+		p.done = true
+		return m, nil
+	}
+	return m, nextCompareMsg(msg.id, msg.pane, msg.events)
+}
+
+// handleCompareKeyPress handles input on the compare screen.
+// ←/→ select an answer, Enter stores it, a stores all, Esc stops or leaves.
+func (m *Model) handleCompareKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	c := m.Compare
+	switch msg.Type {
+	case tea.KeyCtrlW:
+		m.stopCompare()
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		if c.running() {
+			m.stopCompare()
+			m.setStatus("Stopped comparing", 2*time.Second)
+			return m, nil
+		}
+		m.Compare = nil
+		m.ScreenMode = types.ModeChat
+		return m, nil
+
+	case tea.KeyLeft, tea.KeyShiftTab:
+		c.sel = (c.sel - 1 + len(c.panes)) % len(c.panes)
+
+	case tea.KeyRight, tea.KeyTab:
+		c.sel = (c.sel + 1) % len(c.panes)
+
+	case tea.KeyEnter:
+		return m, m.storeCompared([]int{c.sel})
+
+	case tea.KeyRunes:
+		if string(msg.Runes) == "a" {
+			all := make([]int, len(c.panes))
+			for i := range all {
+				all[i] = i
+			}
+			return m, m.storeCompared(all)
+		}
+	}
+	return m, nil
+}
+
+// stopCompare cancels the streams of the current comparison, if any
+func (m *Model) stopCompare() {
+	if m.Compare == nil {
+		return
+	}
+	m.Compare.cancel()
+	for _, p := range m.Compare.panes {
+		p.done = true
+	}
+}
+
+// storeCompared stores the completed answers of the given panes, each
+// labelled with the model that wrote it
+func (m *Model) storeCompared(panes []int) tea.Cmd {
+	c := m.Compare
+	var entries []fs.QA
+	var stored []int
+	for _, i := range panes {
+		p := c.panes[i]
+		if p.resp == nil || p.stored || strings.TrimSpace(p.text) == "" {
+			continue
+		}
+		metrics := p.resp.Metrics
+		entry := fs.QA{
+			Question: c.question,
+			Answer:   p.text,
+			Persona:  c.persona,
+			Model:    p.model,
+			Images:   c.images,
+			Endpoint: p.resp.Endpoint,
+			Metrics:  &metrics,
+		}
+		if m.KeepThinking {
+			entry.Thinking = p.resp.Thinking
+		}
+		if data, err := json.Marshal(c.options); err == nil {
+			entry.Options = data
+		}
+		entries = append(entries, entry)
+		stored = append(stored, i)
+	}
+	if len(entries) == 0 {
+		m.setStatus("No finished answer to store", 2*time.Second)
+		return nil
+	}
+
+	id := c.id
+	return func() tea.Msg {
+		for _, entry := range entries {
+			if err := m.StoreQA(entry); err != nil {
+				return compareStoredMsg{id: id, err: err}
+			}
+		}
+		return compareStoredMsg{id: id, panes: stored}
+	}
+}
+
+// handleCompareStored marks stored answers and reports the result
+func (m *Model) handleCompareStored(msg compareStoredMsg) {
+	if msg.err != nil {
+		m.setStatus(fmt.Sprintf("Failed to store answer: %v", msg.err), 5*time.Second)
+		return
+	}
+	if m.Compare != nil && m.Compare.id == msg.id {
+		for _, i := range msg.panes {
+			m.Compare.panes[i].stored = true
+		}
+	}
+	m.setStatus(fmt.Sprintf("✅ Stored %d answer(s)", len(msg.panes)), 3*time.Second)
+}
+
+// startCompareEdit opens the input for the list of compare models
+func (m *Model) startCompareEdit() (tea.Model, tea.Cmd) {
+	m.EditingCompare = true
+	m.CompareInput.SetValue(strings.Join(m.CompareModels, ", "))
+	m.CompareInput.Focus()
+	return m, textinput.Blink
+}
+
+// handleCompareInput handles input when editing the compare models
+func (m *Model) handleCompareInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.EditingCompare = false
+		m.CompareInput.Blur()
+		return m, nil
+
+	case tea.KeyEnter:
+		models := config.ParseModelList(m.CompareInput.Value())
+		if err := config.SaveCompareModels(models); err != nil {
+			m.setStatus(fmt.Sprintf("Failed to save compare models: %v", err), 3*time.Second)
+			return m, nil
+		}
+		m.CompareModels = models
+		m.Options[compareOption] = compareLabel(models)
+		m.EditingCompare = false
+		m.CompareInput.Blur()
+		m.setStatus("Compare models saved", 2*time.Second)
+		return m, nil
+
+	default:
+		var cmd tea.Cmd
+		m.CompareInput, cmd = m.CompareInput.Update(msg)
+		return m, cmd
+	}
+}
+
+// compareLabel returns the options menu entry for the compare models
+func compareLabel(models []string) string {
+	if len(models) == 0 {
+		return "Compare models: none"
+	}
+	return "Compare models: " + strings.Join(models, ", ")
+}
+
+// renderCompare renders the answers side by side, with their stats
+func (m Model) renderCompare() string {
+	c := m.Compare
+	var sb strings.Builder
+	sb.WriteString(helpStyle.Render("Q: " + truncateText(c.question, 100)))
+	sb.WriteString("\n\n")
+
+	perRow := comparePanesPerRow
+	if len(c.panes) < perRow {
+		perRow = len(c.panes)
+	}
+	width := compareRowWidth/perRow - 4
+
+	for start := 0; start < len(c.panes); start += perRow {
+		end := start + perRow
+		if end > len(c.panes) {
+			end = len(c.panes)
+		}
+		var row []string
+		for i := start; i < end; i++ {
+			row = append(row, m.renderComparePane(c.panes[i], i == c.sel, width))
+		}
+		sb.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, row...))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// renderComparePane renders one model's answer with its stats
+func (m Model) renderComparePane(p *comparePane, selected bool, width int) string {
+	color := lipgloss.Color("62")
+	if selected {
+		color = lipgloss.Color(config.MainColorForeground)
+	}
+	style := lipgloss.NewStyle().
+		Padding(0, 1).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color).
+		Width(width)
+
+	text := p.text
+	if text == "" && p.thinking && !p.done {
+		text = helpStyle.Render("💭 thinking…")
+	}
+	if r := []rune(text); len(r) > comparePaneText {
+		text = "…" + string(r[len(r)-comparePaneText:])
+	}
+
+	var status string
+	switch {
+	case p.err != nil:
+		status = "Error: " + p.err.Error()
+	case p.resp != nil:
+		status = llm.FormatMetrics(p.resp.Metrics)
+		if p.stored {
+			status = "✅ stored · " + status
+		}
+	case p.done:
+		status = "⏹ stopped"
+	default:
+		status = formatLiveMetrics(p.started, p.first, p.chunks)
+	}
+
+	title := optionStyle.UnsetMarginBottom().Render(p.model)
+	return style.Render(fmt.Sprintf("%s\n\n%s\n\n%s", title, text, helpStyle.Render(status)))
+}
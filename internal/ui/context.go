@@ -0,0 +1,28 @@
+// Package ui provides the terminal user interface components for the AskAI application.
+// This file renders the debug view of how a prompt was fitted into the context window.
+package ui
+
+import (
+	"fmt"
+	"strings"
+)
+
+// renderContextPlan shows the token budget of the last prompt and what was
+// trimmed or summarized to fit it
+func (m Model) renderContextPlan() string {
+	p := m.ContextPlan
+	if p == nil {
+		return helpStyle.Render("Context: nothing sent yet")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Context: ~%d of %d tokens, %d kept for the answer\n", p.Total(), p.Budget.Window, p.Budget.Reserve)
+	fmt.Fprintf(&sb, "  system %d · tools %d · history %d · question %d", p.System, p.Context, p.History, p.Question)
+	if c := m.Conversation; c.Summary != "" {
+		fmt.Fprintf(&sb, "\n  messages before #%d are summarized", c.Summarized+1)
+	}
+	for _, d := range p.Decisions {
+		sb.WriteString("\n  • " + d)
+	}
+	return helpStyle.Render(sb.String())
+}
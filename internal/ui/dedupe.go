@@ -0,0 +1,219 @@
+// Package ui provides the terminal user interface components for the AskAI application.
+// This file implements the near-duplicate review screen.
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/app"
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/types"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// dedupePaneWidth is the width of each entry pane in the side-by-side view
+const dedupePaneWidth = 38
+
+// duplicatesMsg carries the result of a near-duplicate scan
+type duplicatesMsg struct {
+	clusters []app.Cluster
+	err      error
+}
+
+// dedupePaneStyle is the style for an entry pane in a duplicate cluster
+var dedupePaneStyle = lipgloss.NewStyle().
+	Padding(0, 1).
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(lipgloss.Color(config.MainColorBackgroundMute)).
+	Width(dedupePaneWidth)
+
+// dedupeSelectedPaneStyle highlights the currently selected entry pane
+var dedupeSelectedPaneStyle = dedupePaneStyle.
+	BorderForeground(lipgloss.Color("63"))
+
+// findDuplicatesCmd scans the vector store for near-duplicate clusters
+func (m *Model) findDuplicatesCmd() tea.Cmd {
+	return func() tea.Msg {
+		clusters, err := m.App.FindDuplicates(app.DefaultDuplicateThreshold)
+		return duplicatesMsg{clusters: clusters, err: err}
+	}
+}
+
+// handleDuplicates stores the scan result and resets the selection
+func (m *Model) handleDuplicates(msg duplicatesMsg) {
+	m.LoadingDupes = false
+	if msg.err != nil {
+		m.ScreenMode = types.ModeOptions
+		m.setStatus(fmt.Sprintf("Failed to find duplicates: %v", msg.err), 5*time.Second)
+		return
+	}
+	m.Clusters = msg.clusters
+	m.ClusterIdx = 0
+	m.ClusterSel = 0
+}
+
+// handleDedupeKeyPress handles key presses on the near-duplicate review screen
+func (m *Model) handleDedupeKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		m.ScreenMode = types.ModeOptions
+		m.Clusters = nil
+		return m, nil
+
+	case tea.KeyCtrlW:
+		return m, tea.Quit
+	}
+
+	if m.LoadingDupes || m.ClusterIdx >= len(m.Clusters) {
+		return m, nil
+	}
+	cluster := m.Clusters[m.ClusterIdx]
+
+	switch msg.Type {
+	case tea.KeyLeft:
+		m.ClusterSel = (m.ClusterSel - 1 + len(cluster.Entries)) % len(cluster.Entries)
+		return m, nil
+
+	case tea.KeyRight, tea.KeyTab:
+		m.ClusterSel = (m.ClusterSel + 1) % len(cluster.Entries)
+		return m, nil
+
+	case tea.KeyRunes:
+		if m.DedupeBusy {
+			return m, nil
+		}
+		switch action := string(msg.Runes); action {
+		case "k", "m", "d":
+			m.DedupeBusy = true
+			return m, m.dedupeActionCmd(action, m.ClusterIdx, m.ClusterSel, cluster)
+
+		case "n":
+			m.nextCluster()
+		}
+	}
+
+	return m, nil
+}
+
+// dedupeActionMsg carries the result of keeping, merging or deleting entries of a cluster
+type dedupeActionMsg struct {
+	action  string // "k", "m" or "d"
+	cluster int    // Index of the cluster acted on
+	sel     int    // The selected entry
+	err     error
+}
+
+// dedupeActionCmd keeps, merges or deletes entries of a cluster in both
+// stores without blocking the UI
+func (m *Model) dedupeActionCmd(action string, idx, sel int, cluster app.Cluster) tea.Cmd {
+	a := m.App
+	return func() tea.Msg {
+		var err error
+		switch action {
+		case "k":
+			err = a.KeepOne(cluster, sel)
+		case "m":
+			err = a.MergeCluster(cluster, sel)
+		case "d":
+			err = a.DeleteEntries(cluster.Entries[sel])
+		}
+		return dedupeActionMsg{action: action, cluster: idx, sel: sel, err: err}
+	}
+}
+
+// handleDedupeAction reports the result of a cluster action and moves on
+func (m *Model) handleDedupeAction(msg dedupeActionMsg) {
+	m.DedupeBusy = false
+	if msg.err != nil {
+		verb := map[string]string{"k": "keep entry", "m": "merge cluster", "d": "delete entry"}[msg.action]
+		m.setStatus(fmt.Sprintf("Failed to %s: %v", verb, msg.err), 5*time.Second)
+		return
+	}
+
+	switch msg.action {
+	case "k":
+		m.setStatus("Kept selected entry, deleted the rest", 3*time.Second)
+	case "m":
+		m.setStatus("Merged answers into selected entry", 3*time.Second)
+	case "d":
+		m.setStatus("Deleted selected entry", 3*time.Second)
+	}
+	// The screen may have been left while the action ran
+	if m.ScreenMode != types.ModeDedupe || msg.cluster != m.ClusterIdx || msg.cluster >= len(m.Clusters) {
+		return
+	}
+
+	if msg.action != "d" {
+		m.nextCluster()
+		return
+	}
+	cluster := m.Clusters[msg.cluster]
+	cluster.Entries = append(cluster.Entries[:msg.sel:msg.sel], cluster.Entries[msg.sel+1:]...)
+	m.Clusters[msg.cluster] = cluster
+	if len(cluster.Entries) < 2 {
+		m.nextCluster()
+	} else if m.ClusterSel >= len(cluster.Entries) {
+		m.ClusterSel = len(cluster.Entries) - 1
+	}
+}
+
+// nextCluster advances to the next cluster, leaving the selection at its first entry
+func (m *Model) nextCluster() {
+	m.ClusterIdx++
+	m.ClusterSel = 0
+}
+
+// renderDedupe renders the current duplicate cluster with its entries side by side
+func (m Model) renderDedupe() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Near-duplicates"))
+	sb.WriteString("\n\n")
+
+	switch {
+	case m.LoadingDupes:
+		sb.WriteString("Scanning stored Q&As…")
+		return sb.String()
+	case m.DedupeBusy:
+		sb.WriteString("Updating the vault and Qdrant…")
+		return sb.String()
+	case len(m.Clusters) == 0:
+		sb.WriteString("No near-duplicates found.")
+		return sb.String()
+	case m.ClusterIdx >= len(m.Clusters):
+		sb.WriteString("All clusters reviewed.")
+		return sb.String()
+	}
+
+	cluster := m.Clusters[m.ClusterIdx]
+	sb.WriteString(optionStyle.Render(fmt.Sprintf("Cluster %d of %d (%d entries)",
+		m.ClusterIdx+1, len(m.Clusters), len(cluster.Entries))))
+	sb.WriteString("\n")
+
+	panes := make([]string, 0, len(cluster.Entries))
+	for i, e := range cluster.Entries {
+		style := dedupePaneStyle
+		if i == m.ClusterSel {
+			style = dedupeSelectedPaneStyle
+		}
+		body := fmt.Sprintf("Q: %s\n\nA: %s\n\n%s",
+			truncateText(e.Question, 200),
+			truncateText(e.Answer, 600),
+			helpStyle.Render(e.StoredAt))
+		panes = append(panes, style.Render(body))
+	}
+	sb.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, panes...))
+
+	return sb.String()
+}
+
+// truncateText shortens s to at most n runes, marking the cut with an ellipsis
+func truncateText(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Indices of the generation option entries in the options menu
+const (
+	maxTokensOption = iota + personaOption + 1
+	topPOption
+	topKOption
+	numCtxOption
+	seedOption
+	stopOption
+)
+
+// genOptionLabels returns the options menu entries for the generation options
+func genOptionLabels(o config.GenerationOptions) []string {
+	orDefault := func(v string, zero bool) string {
+		if zero {
+			return "default"
+		}
+		return v
+	}
+	return []string{
+		"Max tokens: " + orDefault(strconv.Itoa(o.MaxTokens), o.MaxTokens == 0),
+		"Top P: " + orDefault(strconv.FormatFloat(o.TopP, 'g', -1, 64), o.TopP == 0),
+		"Top K: " + orDefault(strconv.Itoa(o.TopK), o.TopK == 0),
+		"Context size: " + orDefault(strconv.Itoa(o.NumCtx), o.NumCtx == 0),
+		"Seed: " + orDefault(strconv.Itoa(o.Seed), o.Seed == 0),
+		"Stop sequences: " + orDefault(config.FormatStop(o.Stop), len(o.Stop) == 0),
+	}
+}
+
+// refreshGenOptionLabels updates the options menu after the generation options changed
+func (m *Model) refreshGenOptionLabels() {
+	copy(m.Options[maxTokensOption:], genOptionLabels(m.GenOptions))
+}
+
+// isGenOption reports whether a menu index is one of the generation options
+func isGenOption(i int) bool {
+	return i >= maxTokensOption && i <= stopOption
+}
+
+// startGenOptionEdit opens the input field for a generation option
+func (m *Model) startGenOptionEdit(i int) (tea.Model, tea.Cmd) {
+	o := m.GenOptions
+	value := ""
+	switch i {
+	case maxTokensOption:
+		value = intValue(o.MaxTokens)
+	case topPOption:
+		if o.TopP != 0 {
+			value = strconv.FormatFloat(o.TopP, 'g', -1, 64)
+		}
+	case topKOption:
+		value = intValue(o.TopK)
+	case numCtxOption:
+		value = intValue(o.NumCtx)
+	case seedOption:
+		value = intValue(o.Seed)
+	case stopOption:
+		value = config.FormatStop(o.Stop)
+	}
+
+	m.EditingGenOption = true
+	m.GenOptionIdx = i
+	m.GenOptionInput.SetValue(value)
+	m.GenOptionInput.Focus()
+	return m, textinput.Blink
+}
+
+// handleGenOptionInput handles input when editing a generation option
+func (m *Model) handleGenOptionInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.EditingGenOption = false
+		m.GenOptionInput.Blur()
+		return m, nil
+
+	case tea.KeyEnter:
+		opts, err := applyGenOption(m.GenOptions, m.GenOptionIdx, strings.TrimSpace(m.GenOptionInput.Value()))
+		if err == nil {
+			err = opts.Validate()
+		}
+		if err != nil {
+			m.setStatus(fmt.Sprintf("Invalid value: %v", err), 3*time.Second)
+			return m, nil
+		}
+
+		m.GenOptions = opts
+		if m.saved != nil {
+			// An edit made under a persona changes the user's own options too
+			m.saved.genOptions, _ = applyGenOption(m.saved.genOptions, m.GenOptionIdx, strings.TrimSpace(m.GenOptionInput.Value()))
+		}
+		m.refreshGenOptionLabels()
+		m.EditingGenOption = false
+		m.GenOptionInput.Blur()
+		m.setStatus("Option updated (Save Settings to keep it)", 2*time.Second)
+		return m, nil
+
+	default:
+		var cmd tea.Cmd
+		m.GenOptionInput, cmd = m.GenOptionInput.Update(msg)
+		return m, cmd
+	}
+}
+
+// applyGenOption parses value into the option at menu index i. An empty
+// value resets the option to the server default.
+func applyGenOption(o config.GenerationOptions, i int, value string) (config.GenerationOptions, error) {
+	if i == stopOption {
+		o.Stop = config.ParseStop(value)
+		return o, nil
+	}
+	if i == topPOption {
+		if value == "" {
+			o.TopP = 0
+			return o, nil
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return o, fmt.Errorf("%q is not a number", value)
+		}
+		o.TopP = f
+		return o, nil
+	}
+
+	n := 0
+	if value != "" {
+		var err error
+		if n, err = strconv.Atoi(value); err != nil {
+			return o, fmt.Errorf("%q is not a whole number", value)
+		}
+	}
+	switch i {
+	case maxTokensOption:
+		o.MaxTokens = n
+	case topKOption:
+		o.TopK = n
+	case numCtxOption:
+		o.NumCtx = n
+	case seedOption:
+		o.Seed = n
+	}
+	return o, nil
+}
+
+// intValue formats n for editing, leaving zero (the default) empty
+func intValue(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
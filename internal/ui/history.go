@@ -0,0 +1,273 @@
+// Package ui provides the terminal user interface components for the AskAI application.
+// This file implements the history screen for browsing, editing and deleting stored Q&As.
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/types"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// historyPageSize is the number of entries shown at once on the history screen
+const historyPageSize = 12
+
+// openHistory loads the stored Q&As and switches to the history screen
+func (m *Model) openHistory() {
+	qas, err := m.App.ListQAs()
+	if err != nil {
+		m.setStatus(fmt.Sprintf("Failed to load history: %v", err), 5*time.Second)
+		return
+	}
+	m.History = qas
+	// Titles are a help, not a requirement; without them entries show no conversation
+	m.ConversationTitles, _ = m.App.ConversationTitles()
+	m.HistorySel = 0
+	m.ConfirmDelete = false
+	m.ScreenMode = types.ModeHistory
+}
+
+// handleHistoryKeyPress handles key presses on the history screen
+func (m *Model) handleHistoryKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.EditingEntry {
+		return m.handleEntryEdit(msg)
+	}
+
+	switch msg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		if m.ConfirmDelete {
+			m.ConfirmDelete = false
+			return m, nil
+		}
+		m.ScreenMode = types.ModeOptions
+		m.History = nil
+		m.ConversationTitles = nil
+		return m, nil
+
+	case tea.KeyCtrlW:
+		return m, tea.Quit
+
+	case tea.KeyUp:
+		if m.HistorySel > 0 {
+			m.HistorySel--
+		}
+		m.ConfirmDelete = false
+
+	case tea.KeyDown:
+		if m.HistorySel < len(m.History)-1 {
+			m.HistorySel++
+		}
+		m.ConfirmDelete = false
+
+	case tea.KeyRunes:
+		if len(m.History) == 0 || m.HistoryBusy {
+			return m, nil
+		}
+		switch string(msg.Runes) {
+		case "d":
+			m.ConfirmDelete = true
+
+		case "y":
+			if !m.ConfirmDelete {
+				return m, nil
+			}
+			m.ConfirmDelete = false
+			m.HistoryBusy = true
+			return m, m.historyDeleteCmd(m.History[m.HistorySel].Key())
+
+		case "e":
+			return m, m.startEntryEdit()
+		}
+	}
+
+	return m, nil
+}
+
+// startEntryEdit opens the editor for the selected entry
+func (m *Model) startEntryEdit() tea.Cmd {
+	qa := m.History[m.HistorySel]
+
+	m.EditQuestion = textinput.New()
+	m.EditQuestion.CharLimit = 2000
+	m.EditQuestion.Width = 76
+	m.EditQuestion.Prompt = "Q> "
+	m.EditQuestion.SetValue(qa.Question)
+	m.EditQuestion.Focus()
+
+	m.EditAnswer = textarea.New()
+	m.EditAnswer.CharLimit = 0
+	m.EditAnswer.SetWidth(80)
+	m.EditAnswer.SetHeight(10)
+	m.EditAnswer.SetValue(qa.Answer)
+	m.EditAnswer.Blur()
+
+	m.EditingEntry = true
+	return textinput.Blink
+}
+
+// handleEntryEdit handles input while editing an entry.
+// Tab switches between question and answer, Ctrl+S saves, Esc cancels.
+func (m *Model) handleEntryEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		if m.HistoryBusy {
+			return m, nil
+		}
+		m.EditingEntry = false
+		m.setStatus("Edit cancelled", 2*time.Second)
+		return m, nil
+
+	case tea.KeyTab:
+		if m.EditQuestion.Focused() {
+			m.EditQuestion.Blur()
+			return m, m.EditAnswer.Focus()
+		}
+		m.EditAnswer.Blur()
+		return m, m.EditQuestion.Focus()
+
+	case tea.KeyCtrlS:
+		question := strings.TrimSpace(m.EditQuestion.Value())
+		answer := strings.TrimSpace(m.EditAnswer.Value())
+		if question == "" || answer == "" {
+			m.setStatus("Question and answer must not be empty", 3*time.Second)
+			return m, nil
+		}
+
+		if m.HistoryBusy {
+			return m, nil
+		}
+		m.HistoryBusy = true
+		return m, m.historyEditCmd(m.History[m.HistorySel].Key(), question, answer)
+	}
+
+	var cmd tea.Cmd
+	if m.EditQuestion.Focused() {
+		m.EditQuestion, cmd = m.EditQuestion.Update(msg)
+	} else {
+		m.EditAnswer, cmd = m.EditAnswer.Update(msg)
+	}
+	return m, cmd
+}
+
+// historyMsg carries the result of deleting or editing a stored Q&A
+type historyMsg struct {
+	key     string // Key of the entry acted on
+	deleted bool   // The entry was deleted rather than edited
+	qa      fs.QA  // The edited entry
+	err     error
+}
+
+// historyDeleteCmd deletes a Q&A from both stores without blocking the UI
+func (m *Model) historyDeleteCmd(key string) tea.Cmd {
+	a := m.App
+	return func() tea.Msg {
+		return historyMsg{key: key, deleted: true, err: a.DeleteQA(key)}
+	}
+}
+
+// historyEditCmd saves an edited Q&A to both stores without blocking the UI
+func (m *Model) historyEditCmd(key, question, answer string) tea.Cmd {
+	a := m.App
+	return func() tea.Msg {
+		qa, err := a.EditQA(key, question, answer)
+		return historyMsg{key: key, qa: qa, err: err}
+	}
+}
+
+// handleHistory reports the result of a delete or edit and updates the list
+func (m *Model) handleHistory(msg historyMsg) {
+	m.HistoryBusy = false
+	if msg.err != nil {
+		if msg.deleted {
+			m.setStatus(fmt.Sprintf("Failed to delete Q&A: %v", msg.err), 5*time.Second)
+		} else {
+			m.setStatus(fmt.Sprintf("Failed to save Q&A: %v", msg.err), 5*time.Second)
+		}
+		return
+	}
+
+	if msg.deleted {
+		m.setStatus("Q&A deleted", 2*time.Second)
+	} else {
+		m.EditingEntry = false
+		m.setStatus("Q&A updated", 2*time.Second)
+	}
+
+	// The screen may have been left while the change was saved
+	i := slices.IndexFunc(m.History, func(qa fs.QA) bool { return qa.Key() == msg.key })
+	if m.ScreenMode != types.ModeHistory || i < 0 {
+		return
+	}
+	if !msg.deleted {
+		m.History[i] = msg.qa
+		return
+	}
+	m.History = append(m.History[:i], m.History[i+1:]...)
+	if m.HistorySel >= len(m.History) && m.HistorySel > 0 {
+		m.HistorySel--
+	}
+}
+
+// renderHistory renders the list of stored Q&As or the entry editor
+func (m Model) renderHistory() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("History"))
+	sb.WriteString("\n\n")
+
+	if m.HistoryBusy && !m.EditingEntry {
+		sb.WriteString("Updating the vault and Qdrant…")
+		return sb.String()
+	}
+
+	if m.EditingEntry {
+		sb.WriteString("Question:\n")
+		sb.WriteString(m.EditQuestion.View())
+		sb.WriteString("\n\nAnswer:\n")
+		sb.WriteString(m.EditAnswer.View())
+		return sb.String()
+	}
+
+	if len(m.History) == 0 {
+		sb.WriteString("No stored Q&As.")
+		return sb.String()
+	}
+
+	// Keep the selection visible by scrolling in whole pages
+	start := (m.HistorySel / historyPageSize) * historyPageSize
+	end := start + historyPageSize
+	if end > len(m.History) {
+		end = len(m.History)
+	}
+
+	for i := start; i < end; i++ {
+		qa := m.History[i]
+		line := fmt.Sprintf("%s  %s  %s", fs.ShortID(qa.Key()), qa.Time.Format("2006-01-02 15:04"), truncateText(qa.Question, 60))
+		if i == m.HistorySel {
+			sb.WriteString(optionStyle.Render("➜ " + line))
+		} else {
+			sb.WriteString("  " + line)
+		}
+		sb.WriteString("\n")
+	}
+
+	selected := m.History[m.HistorySel]
+	sb.WriteString("\n")
+	if title := m.ConversationTitles[selected.Key()]; title != "" {
+		sb.WriteString(helpStyle.Render("From conversation: " + title))
+		sb.WriteString("\n")
+	}
+	sb.WriteString(messageStyle.Render(truncateText(selected.Answer, 800)))
+
+	if m.ConfirmDelete {
+		sb.WriteString("\n\n")
+		sb.WriteString(optionStyle.Render("Delete this Q&A from the vault and Qdrant? (y/Esc)"))
+	}
+
+	return sb.String()
+}
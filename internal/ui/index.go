@@ -0,0 +1,91 @@
+// Package ui provides the terminal user interface components for the AskAI application.
+// This file indexes the vault's Q&As into Qdrant in the background.
+package ui
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// indexProgress is how far an indexing run has got
+type indexProgress struct {
+	done, total int
+}
+
+// indexResult is what an indexing run returned
+type indexResult struct {
+	indexed int
+	err     error
+}
+
+// indexMsg carries the next progress update of an indexing run, or its result
+type indexMsg struct {
+	updates  <-chan indexProgress
+	result   <-chan indexResult
+	progress indexProgress
+	done     bool
+	indexed  int
+	err      error
+}
+
+// indexCmd indexes every Q&A in the vault that has no point in Qdrant yet.
+// Entries already present are skipped, and the vault file is never appended
+// to. Only one run goes at a time.
+func (m *Model) indexCmd() tea.Cmd {
+	if m.indexing {
+		m.setStatus("Already indexing Q&A pairs...", 2*time.Second)
+		return nil
+	}
+	if _, err := os.Stat(fs.QAFilePath(m.VaultPath)); os.IsNotExist(err) {
+		m.setStatus("No Q&A file found to index", 3*time.Second)
+		return nil
+	}
+	m.indexing = true
+	m.setStatus("Starting to index Q&A pairs...", 0)
+
+	updates := make(chan indexProgress)
+	result := make(chan indexResult, 1)
+	a := m.App
+	go func() {
+		defer close(updates)
+		indexed, err := a.IndexVault(func(done, total int) {
+			updates <- indexProgress{done: done, total: total}
+		})
+		result <- indexResult{indexed: indexed, err: err}
+	}()
+	return nextIndexMsg(updates, result)
+}
+
+// nextIndexMsg waits for the next progress update of an indexing run
+func nextIndexMsg(updates <-chan indexProgress, result <-chan indexResult) tea.Cmd {
+	return func() tea.Msg {
+		p, ok := <-updates
+		if !ok {
+			r := <-result
+			return indexMsg{done: true, indexed: r.indexed, err: r.err}
+		}
+		return indexMsg{updates: updates, result: result, progress: p}
+	}
+}
+
+// handleIndex shows the progress of an indexing run and reports its result
+func (m *Model) handleIndex(msg indexMsg) tea.Cmd {
+	if !msg.done {
+		p := msg.progress
+		percent := float64(p.done) / float64(p.total) * 100
+		m.setStatus(fmt.Sprintf("Indexing %d/%d (%.1f%%)...", p.done, p.total, percent), 0)
+		return nextIndexMsg(msg.updates, msg.result)
+	}
+
+	m.indexing = false
+	if msg.err != nil {
+		m.setStatus(fmt.Sprintf("❌ Indexing failed after %d Q&A pairs: %v", msg.indexed, msg.err), 5*time.Second)
+		return nil
+	}
+	m.setStatus(fmt.Sprintf("✅ Indexed %d new Q&A pairs", msg.indexed), 10*time.Second)
+	return nil
+}
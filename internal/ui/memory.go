@@ -0,0 +1,247 @@
+// Package ui provides the terminal user interface components for the AskAI application.
+// This file handles long-term memory: recalling it into prompts, distilling it in the background and reviewing it.
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/app"
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
+	"github.com/VarunSharma3520/AskAI/internal/types"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// memoryInterval is how often new conversation turns are distilled into memories
+const memoryInterval = 10 * time.Minute
+
+// recallMsg carries the memories recalled for a question about to be sent
+type recallMsg struct {
+	id       int
+	turn     *pendingTurn
+	memories []string
+	err      error
+}
+
+// recallCmd looks up the memories relevant to the turn's question
+func (m *Model) recallCmd(id int, turn *pendingTurn) tea.Cmd {
+	a := m.App
+	return func() tea.Msg {
+		memories, err := a.RecallMemories(turn.question, app.DefaultMemoryRecall)
+		return recallMsg{id: id, turn: turn, memories: memories, err: err}
+	}
+}
+
+// handleRecall sends the question with the recalled memories. If recalling
+// failed, the question is sent without them.
+func (m *Model) handleRecall(msg recallMsg) (tea.Model, tea.Cmd) {
+	if msg.id != m.StreamID || !m.Streaming {
+		return m, nil
+	}
+	if msg.err != nil {
+		log.Printf("Failed to recall memories: %v", msg.err)
+		m.setStatus("Failed to recall memories; asking without them", 3*time.Second)
+	}
+	turn := *msg.turn
+	turn.memories = msg.memories
+	turn.recalled = true
+	return m.startTurn(&turn, m.prefix)
+}
+
+// memoryTickMsg starts a background distillation
+type memoryTickMsg struct{}
+
+// memoryTick schedules the next background distillation
+func memoryTick() tea.Cmd {
+	return tea.Tick(memoryInterval, func(time.Time) tea.Msg { return memoryTickMsg{} })
+}
+
+// memoryDistilledMsg is sent once a background distillation has finished
+type memoryDistilledMsg struct {
+	report app.DistillReport
+	err    error
+}
+
+// handleMemoryTick distills the turns added since the last run, unless
+// memory was turned off or a run is still going
+func (m *Model) handleMemoryTick() tea.Cmd {
+	if !m.MemoryOn {
+		m.memoryTicking = false
+		return nil
+	}
+	if m.distilling {
+		return memoryTick()
+	}
+	m.distilling = true
+
+	a, provider := m.App, m.Provider
+	req := llm.ChatRequest{Model: m.ModelName, Options: llm.Options{Temperature: m.Temperature, GenerationOptions: m.GenOptions}}
+	distill := func() tea.Msg {
+		report, err := a.DistillMemories(context.Background(), provider, req, time.Now().Add(-app.MemoryWindow))
+		return memoryDistilledMsg{report: report, err: err}
+	}
+	return tea.Batch(distill, memoryTick())
+}
+
+// handleMemoryDistilled reports the memories a background run stored
+func (m *Model) handleMemoryDistilled(msg memoryDistilledMsg) {
+	m.distilling = false
+	if msg.err != nil {
+		log.Printf("Failed to distill memories: %v", msg.err)
+	}
+	if n := len(msg.report.Stored); n > 0 {
+		m.setStatus(fmt.Sprintf("🧠 Remembered %d new fact(s) · Review memories in the options", n), 5*time.Second)
+	}
+}
+
+// toggleMemory turns long-term memory on or off and saves the setting
+func (m *Model) toggleMemory() tea.Cmd {
+	on := !m.MemoryOn
+	if err := config.SaveMemory(on); err != nil {
+		m.setStatus(fmt.Sprintf("Failed to save setting: %v", err), 3*time.Second)
+		return nil
+	}
+	m.MemoryOn = on
+	m.Options[memoryOption] = memoryLabel(on)
+	if on && !m.memoryTicking {
+		m.memoryTicking = true
+		return memoryTick()
+	}
+	return nil
+}
+
+// memoryLabel returns the options menu entry for long-term memory
+func memoryLabel(on bool) string {
+	if on {
+		return "Long-term memory: on (distilled every 10 min, recalled into prompts)"
+	}
+	return "Long-term memory: off"
+}
+
+// openMemories loads the memories and switches to the review screen
+func (m *Model) openMemories() {
+	memories, err := m.App.ListMemories()
+	if err != nil {
+		m.setStatus(fmt.Sprintf("Failed to load memories: %v", err), 5*time.Second)
+		return
+	}
+	m.Memories = memories
+	m.MemorySel = 0
+	m.ConfirmDelete = false
+	m.ScreenMode = types.ModeMemories
+}
+
+// handleMemoriesKeyPress handles key presses on the memory review screen
+func (m *Model) handleMemoriesKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC, tea.KeyEsc:
+		if m.ConfirmDelete {
+			m.ConfirmDelete = false
+			return m, nil
+		}
+		m.ScreenMode = types.ModeOptions
+		m.Memories = nil
+		return m, nil
+
+	case tea.KeyCtrlW:
+		return m, tea.Quit
+
+	case tea.KeyUp:
+		if m.MemorySel > 0 {
+			m.MemorySel--
+		}
+		m.ConfirmDelete = false
+
+	case tea.KeyDown:
+		if m.MemorySel < len(m.Memories)-1 {
+			m.MemorySel++
+		}
+		m.ConfirmDelete = false
+
+	case tea.KeyRunes:
+		if len(m.Memories) == 0 {
+			return m, nil
+		}
+		switch string(msg.Runes) {
+		case "d":
+			m.ConfirmDelete = true
+
+		case "y":
+			if !m.ConfirmDelete {
+				return m, nil
+			}
+			m.ConfirmDelete = false
+			if _, err := m.App.DeleteMemory(m.Memories[m.MemorySel].ID); err != nil {
+				m.setStatus(fmt.Sprintf("Failed to delete memory: %v", err), 5*time.Second)
+				return m, nil
+			}
+			m.Memories = append(m.Memories[:m.MemorySel], m.Memories[m.MemorySel+1:]...)
+			if m.MemorySel >= len(m.Memories) && m.MemorySel > 0 {
+				m.MemorySel--
+			}
+			m.setStatus("Memory deleted", 2*time.Second)
+		}
+	}
+
+	return m, nil
+}
+
+// renderMemories renders the list of memories and where the selected one came from
+func (m Model) renderMemories() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Memories"))
+	sb.WriteString("\n\n")
+
+	if len(m.Memories) == 0 {
+		sb.WriteString("No memories yet.")
+		if !m.MemoryOn {
+			sb.WriteString(" Turn on Long-term memory in the options to distill them from your conversations.")
+		}
+		return sb.String()
+	}
+
+	// Keep the selection visible by scrolling in whole pages
+	start := (m.MemorySel / historyPageSize) * historyPageSize
+	end := start + historyPageSize
+	if end > len(m.Memories) {
+		end = len(m.Memories)
+	}
+
+	for i := start; i < end; i++ {
+		mem := m.Memories[i]
+		line := fmt.Sprintf("%-10s  %s", mem.Kind, truncateText(mem.Text, 64))
+		if i == m.MemorySel {
+			sb.WriteString(optionStyle.Render("➜ " + line))
+		} else {
+			sb.WriteString("  " + line)
+		}
+		sb.WriteString("\n")
+	}
+
+	selected := m.Memories[m.MemorySel]
+	details := fmt.Sprintf("%s\n\nRemembered %s", selected.Text, selected.Created.Format("2006-01-02 15:04"))
+	if selected.Conversation != "" {
+		details += " from conversation " + fs.ShortID(selected.Conversation)
+	}
+	if len(selected.Sources) > 0 {
+		ids := make([]string, len(selected.Sources))
+		for i, id := range selected.Sources {
+			ids[i] = fs.ShortID(id)
+		}
+		details += "\nQ&As: " + strings.Join(ids, ", ")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(messageStyle.Render(details))
+
+	if m.ConfirmDelete {
+		sb.WriteString("\n\n")
+		sb.WriteString(optionStyle.Render("Delete this memory from the vault and Qdrant? (y/Esc)"))
+	}
+
+	return sb.String()
+}
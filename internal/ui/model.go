@@ -3,17 +3,20 @@
 package ui
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"log"
-	"os"
-	"path/filepath"
 	"time"
 
+	"github.com/VarunSharma3520/AskAI/internal/app"
 	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
 	"github.com/VarunSharma3520/AskAI/internal/types"
 	"github.com/VarunSharma3520/AskAI/internal/vector"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 // Model represents the main application state and business logic.
@@ -27,23 +30,132 @@ type Model struct {
 	LastQuestion string
 	VectorStore  *vector.VectorStore
 	VaultPath    string
+	App          *app.App
+	// KnowledgeBase is the name of the knowledge base the vector store belongs to
+	KnowledgeBase string
+	// Conversation holds the turns sent to the model with every question
+	Conversation *fs.Conversation
+	// Provider is the chat backend questions are sent to
+	Provider llm.ChatProvider
+	// Profile is the name of the backend profile Provider was created from
+	Profile string
+	// Persona supplies the system prompt, or nil for none
+	Persona  *config.Persona
+	Personas []config.Persona
+	// saved holds the user's own model, temperature and options while a
+	// persona replaces them, or nil when no persona is active
+	saved *userSettings
 
 	// Options
 	Options     []string
 	SelectedOpt int
 	ModelName   string
 	Temperature float64
-	MaxTokens   int
+	// GenOptions are the generation options sent with every request
+	GenOptions       config.GenerationOptions
+	EditingGenOption bool
+	GenOptionIdx     int
+	GenOptionInput   textinput.Model
 
 	// Stream handling
-	StreamCh      chan string
-	ErrCh         chan error
-	StopCh        chan struct{}
-	Streaming     bool
+	// Events is the current stream; cancelStream aborts it
+	Events       <-chan llm.Event
+	cancelStream context.CancelFunc
+	Streaming    bool
+	// StreamID identifies the current stream so messages from a stopped one are ignored
+	StreamID int
+	// Stopped is set when the user stopped an answer, which is kept as a partial answer
+	Stopped bool
+	// turn is the question being answered, kept so a stopped answer can be stored or continued
+	turn          *pendingTurn
+	prefix        string // The part of the answer kept from before a continue
 	StatusMsg     string
 	StatusTimer   *time.Timer
 	EditingModel  bool
 	EditingAPIURL bool
+
+	// Context window management
+	Estimator   *llm.TokenEstimator // Learns each model's tokens per character
+	ContextPlan *llm.ContextPlan    // How the last prompt was fitted into the window
+	ShowContext bool                // Show the context plan below the answer
+
+	// Attachments are images attached to the next question, as paths inside the vault
+	Attachments []string
+
+	// Reasoning of thinking models
+	Thinking     string // The reasoning of the current answer
+	ShowThinking bool   // Show the reasoning instead of a summary line
+	KeepThinking bool   // Store the reasoning with each Q&A
+
+	// Long-term memory
+	MemoryOn      bool        // Distill memories in the background and recall them into prompts
+	Memories      []fs.Memory // The memories on the review screen
+	MemorySel     int
+	memoryTicking bool // A background distillation is scheduled
+	distilling    bool // A background distillation is running
+
+	// Router picks a model or persona for each question, or is nil if the
+	// routing table couldn't be loaded
+	Router *llm.Router
+
+	// Conversation titles
+	TitleModel string // Model that titles ended conversations; the chat model if empty
+	titling    bool   // A background titling run is going
+
+	// Indexing the vault into Qdrant
+	indexing bool // An indexing run is going
+
+	// Live metrics of the answer being streamed
+	StreamStarted time.Time
+	FirstTokenAt  time.Time
+	StreamChunks  int
+
+	// Tools the model may call, or nil when tool use is off
+	Tools *llm.Toolbox
+	// PendingTool is a tool call waiting for the user's confirmation
+	PendingTool *llm.Event
+	// ToolLog shows the tool calls and results of the current answer
+	ToolLog []string
+
+	// Prompt template form
+	Template       *config.PromptTemplate // The template being filled in
+	TemplateFields []textarea.Model       // One input per placeholder
+	TemplateField  int                    // The focused input
+
+	// Compare mode
+	CompareModels  []string        // Models a question is compared across
+	CompareInput   textinput.Model // For editing the compare models
+	EditingCompare bool
+	Compare        *comparison // The comparison on screen, or nil
+	compareID      int
+
+	// Model picker and downloads
+	Models        []llm.ModelInfo // Models the backend has installed
+	ModelsErr     error           // Why the models couldn't be listed, if they couldn't
+	LoadingModels bool
+	ModelSel      int              // Index into the models matching the filter
+	ModelMoved    bool             // The selection was moved since the filter last changed
+	Pulling       string           // Model being downloaded, or ""
+	PullProgress  llm.PullProgress // Progress of the download
+	cancelPull    context.CancelFunc
+
+	// Near-duplicate review
+	Clusters     []app.Cluster
+	ClusterIdx   int
+	ClusterSel   int
+	LoadingDupes bool
+	DedupeBusy   bool // A keep, merge or delete is running
+
+	// History browsing and editing
+	History       []fs.QA
+	HistorySel    int
+	ConfirmDelete bool
+	EditingEntry  bool
+	HistoryBusy   bool // A delete or edit is being saved
+	EditQuestion  textinput.Model
+	EditAnswer    textarea.Model
+	// ConversationTitles maps Q&A IDs to the title of the conversation they were asked in
+	ConversationTitles map[string]string
 }
 
 // InitialModel creates and initializes a new Model instance with the provided vector store and vault path.
@@ -83,221 +195,283 @@ func InitialModel(vectorStore *vector.VectorStore, vaultPath string) *Model {
 	apiURLInput.Width = 50
 	apiURLInput.Prompt = "> "
 
+	// Initialize generation option input
+	genOptionInput := textinput.New()
+	genOptionInput.Placeholder = "Leave empty for the server default"
+	genOptionInput.CharLimit = 100
+	genOptionInput.Width = 40
+	genOptionInput.Prompt = "> "
+
 	// Load saved settings or use defaults
 	modelName := config.Model()
 	temperature := config.Temperature()
+	var genOptions config.GenerationOptions
+	var compareModels []string
+	var keepThinking, memoryOn bool
+	var titleModel string
+	var routing config.Routing
+	if cfg, err := config.Load(); err == nil {
+		genOptions = cfg.Options
+		compareModels = cfg.CompareModels
+		keepThinking = cfg.KeepThinking
+		memoryOn = cfg.Memory
+		titleModel = cfg.TitleModel
+		if cfg.Routing != nil {
+			routing = *cfg.Routing
+		}
+	}
+	// Without a routing table the router still honours @model overrides
+	router, err := llm.NewRouter(routing)
+	if err != nil {
+		log.Printf("Routing disabled: %v", err)
+	}
+
+	// Initialize compare models input
+	compareInput := textinput.New()
+	compareInput.Placeholder = "Comma-separated, e.g. gemma3:1b, llama3.1"
+	compareInput.CharLimit = 200
+	compareInput.Width = 50
+	compareInput.Prompt = "> "
 
 	// Initialize options
 	options := []string{
-		"Change Model: " + modelName,
-		fmt.Sprintf("Temperature: %.1f (use +/-", temperature),
+		modelLabel(modelName),
+		temperatureLabel(temperature),
 		"Set API URL: " + config.APIURL(),
 		"Save Settings",
 		"Back to Chat",
 		"Update Qdrant index",
+		"Review near-duplicates",
+		"Browse history",
+		"New conversation",
+		"Persona: none",
 	}
+	options = append(options, genOptionLabels(genOptions)...)
+	options = append(options, "Tools: off", compareLabel(compareModels), keepThinkingLabel(keepThinking),
+		memoryLabel(memoryOn), "Review memories")
 
-	return &Model{
-		TextInput:     ti,
-		ModelInput:    modelInput,
-		APIURLInput:   apiURLInput,
-		VectorStore:   vectorStore,
-		VaultPath:     vaultPath,
-		ScreenMode:    types.ModeChat,
-		Options:       options,
-		SelectedOpt:   0,
-		ModelName:     modelName,
-		Temperature:   temperature,
-		EditingModel:  false,
-		EditingAPIURL: false,
-		StatusTimer:   time.NewTimer(0), // Will be reset when used
+	// Personas are optional; fall back to none if the file is unreadable
+	personas, err := config.LoadPersonas()
+	if err != nil {
+		personas = nil
+	}
+	if router != nil {
+		router.Models = config.PersonaModels(personas)
 	}
-}
-
-// QA represents a single question-answer pair with metadata.
-// It's used for both in-memory representation and JSON serialization.
-type QA struct {
-	Question string    `json:"question"` // The user's question
-	Answer   string    `json:"answer"`   // The AI's response
-	Time     time.Time `json:"time"`     // When the Q&A was created
-}
 
-// QAFile represents the structure of the saved Q&A data file.
-// It's used to marshal and unmarshal Q&A pairs to/from JSON.
-type QAFile struct {
-	QAs []QA `json:"qas"` // Collection of Q&A pairs
+	return &Model{
+		TextInput:      ti,
+		ModelInput:     modelInput,
+		APIURLInput:    apiURLInput,
+		VectorStore:    vectorStore,
+		VaultPath:      vaultPath,
+		App:            app.New(vectorStore, vaultPath),
+		Conversation:   fs.NewConversation(),
+		Estimator:      llm.NewTokenEstimator(),
+		Personas:       personas,
+		Provider:       llm.NewOllamaProvider(config.APIURL(), nil),
+		Profile:        config.DefaultProfile,
+		ScreenMode:     types.ModeChat,
+		Options:        options,
+		SelectedOpt:    0,
+		ModelName:      modelName,
+		Temperature:    temperature,
+		GenOptions:     genOptions,
+		GenOptionInput: genOptionInput,
+		CompareModels:  compareModels,
+		CompareInput:   compareInput,
+		KeepThinking:   keepThinking,
+		MemoryOn:       memoryOn,
+		memoryTicking:  memoryOn,
+		Router:         router,
+		TitleModel:     titleModel,
+		EditingModel:   false,
+		EditingAPIURL:  false,
+		StatusTimer:    time.NewTimer(0), // Will be reset when used
+	}
 }
 
 // StoreQA saves a question-answer pair to the vault and creates vector embeddings for both.
 // It performs the following operations:
-// 1. Checks if the Q&A pair already exists in the vector store
-// 2. Creates vector embeddings for both question and answer
-// 3. Stores the Q&A in the local vault as JSON
-// 4. Updates the vector store with the new embeddings
+// 1. Creates vector embeddings for both question and answer
+// 2. Upserts the Q&A into the vector store under its stable ID
+// 3. Appends the Q&A to the local vault JSON unless it is already there
 //
 // Parameters:
-//   - question: The user's question
-//   - answer: The AI's response to the question
+//   - entry: The question, answer and metadata such as the persona used
 //
 // Returns:
 //   - error: An error if any step fails, or nil on success
 //
 // Example:
 //
-//	err := model.StoreQA("What is AI?", "AI stands for Artificial Intelligence.")
+//	err := model.StoreQA(fs.QA{Question: "What is AI?", Answer: "AI stands for Artificial Intelligence."})
 //	if err != nil {
 //	    log.Printf("Failed to store Q&A: %v", err)
 //	}
-func (m *Model) StoreQA(question, answer string) error {
-	if m.VectorStore == nil {
-		return fmt.Errorf("vector store is not initialized")
-	}
+func (m *Model) StoreQA(entry fs.QA) error {
+	_, err := m.App.StoreQA(entry)
+	return err
+}
+
+// NewConversation starts a fresh conversation. The previous one is already
+// saved in the vault after each completed turn; the returned command titles it.
+func (m *Model) NewConversation() tea.Cmd {
+	m.Conversation = fs.NewConversation()
+	m.Msg = ""
+	m.Stopped = false
+	m.turn = nil
+	m.LastQuestion = ""
+	m.setStatus("Started a new conversation", 2*time.Second)
+	return m.titleCmd()
+}
 
-	// First check if Q&A already exists in Qdrant
-	exists, err := m.VectorStore.QAExists(question, answer)
+// SetProfile switches to the chat backend described by a profile. The
+// profile's model, if set, replaces the current one.
+func (m *Model) SetProfile(name string, p config.Profile) error {
+	provider, err := llm.NewProfileProvider(name, p)
 	if err != nil {
-		// log.Printf("Error checking for existing Q&A in Qdrant: %v", err)
-		// Continue with storage attempt even if check fails
-	} else if exists {
-		// log.Printf("Skipping duplicate Q&A pair in Qdrant: %s", question)
-		return nil
+		return err
 	}
-
-	// Create vault directory if it doesn't exist
-	if err := os.MkdirAll(m.VaultPath, 0755); err != nil {
-		return fmt.Errorf("failed to create vault directory: %w", err)
+	m.Provider = provider
+	m.Profile = name
+	if p.Model != "" {
+		m.setModel(p.Model)
 	}
+	return nil
+}
 
-	// Define the filename for the JSON storage
-	filename := filepath.Join(m.VaultPath, "que_ans.json")
-	var qas QAFile
+// SetPersona switches to a persona, or to none if p is nil. The persona's
+// model, temperature and options replace the user's own, which come back when
+// the persona is cleared. A conversation already in progress is replaced by a
+// new one so its system prompt stays consistent.
+func (m *Model) SetPersona(p *config.Persona) {
+	if m.saved != nil {
+		m.ModelName, m.Temperature, m.GenOptions = m.saved.model, m.saved.temperature, m.saved.genOptions
+		m.saved = nil
+	}
 
-	// Read existing Q&As if file exists
-	if _, err := os.Stat(filename); err == nil {
-		data, err := os.ReadFile(filename)
-		if err != nil {
-			return fmt.Errorf("failed to read existing Q&A file: %w", err)
+	m.Persona = p
+	name := "none"
+	if p != nil {
+		name = p.Name
+		m.saved = &userSettings{model: m.ModelName, temperature: m.Temperature, genOptions: m.GenOptions}
+		if p.Model != "" {
+			m.ModelName = p.Model
 		}
-		if err := json.Unmarshal(data, &qas); err != nil {
-			return fmt.Errorf("failed to parse existing Q&A file: %w", err)
+		if p.Temperature != nil {
+			m.Temperature = *p.Temperature
+		}
+		if p.Options != nil {
+			m.GenOptions = m.GenOptions.Merge(*p.Options)
 		}
 	}
-
-	// Generate embeddings for the question and answer
-	questionEmbedding, err := m.VectorStore.Embed(question)
-	if err != nil {
-		// log.Printf("Error embedding question: %v", err)
-		return fmt.Errorf("failed to embed question: %w", err)
-	}
-
-	answerEmbedding, err := m.VectorStore.Embed(answer)
-	if err != nil {
-		log.Printf("Error embedding answer: %v", err)
-		return fmt.Errorf("failed to embed answer: %w", err)
-	}
-
-	// Store both question and answer in Qdrant
-	if err := m.VectorStore.StoreQA(question, answer, questionEmbedding, answerEmbedding); err != nil {
-		// log.Printf("Error storing Q&A in Qdrant: %v", err)
-		return fmt.Errorf("failed to store Q&A in vector database: %w", err)
+	m.Options[modelOption] = modelLabel(m.ModelName)
+	m.Options[temperatureOption] = temperatureLabel(m.Temperature)
+	m.refreshGenOptionLabels()
+	m.Options[personaOption] = "Persona: " + name
+
+	if len(m.Conversation.Messages) > 0 {
+		m.Conversation = fs.NewConversation()
+		m.Msg = ""
 	}
+}
 
-	// log.Printf("Successfully stored Q&A pair in Qdrant")
-
-	// Add new QA to the JSON file
-	newQA := QA{
-		Question: question,
-		Answer:   answer,
-		Time:     time.Now(),
+// nextPersona cycles through the personas, with "none" after the last one
+func (m *Model) nextPersona() {
+	if len(m.Personas) == 0 {
+		m.setStatus("No personas defined", 2*time.Second)
+		return
 	}
-	qas.QAs = append(qas.QAs, newQA)
 
-	// Convert to JSON
-	data, err := json.MarshalIndent(qas, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal Q&As: %w", err)
+	next := 0
+	if m.Persona != nil {
+		for i := range m.Personas {
+			if m.Personas[i].Name == m.Persona.Name {
+				next = i + 1
+				break
+			}
+		}
 	}
-
-	// Write to file
-	if err := os.WriteFile(filename, data, 0644); err != nil {
-		return fmt.Errorf("failed to write Q&As to file: %w", err)
+	if next >= len(m.Personas) {
+		m.SetPersona(nil)
+		return
 	}
-
-	return nil
+	m.SetPersona(&m.Personas[next])
 }
 
-// StoreCurrentQuestion indexes all Q&A pairs from the vault/que_ans.json file into Qdrant
-func (m *Model) StoreCurrentQuestion() {
-	// Define the filename for the JSON storage
-	filename := filepath.Join(m.VaultPath, "que_ans.json")
+// userSettings are the model, temperature and generation options the user chose
+type userSettings struct {
+	model       string
+	temperature float64
+	genOptions  config.GenerationOptions
+}
 
-	// Check if the file exists
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		m.setStatus("No Q&A file found to index", 3*time.Second)
-		// log.Printf("Q&A file not found at: %s", filename)
-		return
+// ownSettings returns the user's own settings, which are the current ones
+// unless a persona replaced them
+func (m *Model) ownSettings() userSettings {
+	if m.saved != nil {
+		return *m.saved
 	}
+	return userSettings{model: m.ModelName, temperature: m.Temperature, genOptions: m.GenOptions}
+}
 
-	// Read the Q&A file
-	data, err := os.ReadFile(filename)
-	if err != nil {
-		errMsg := fmt.Sprintf("Failed to read Q&A file: %v", err)
-		// log.Println(errMsg)
-		m.setStatus(errMsg, 5*time.Second)
-		return
+// setModel switches to a model the user chose. With a persona active it also
+// becomes the model to return to when the persona is cleared.
+func (m *Model) setModel(name string) {
+	m.ModelName = name
+	if m.saved != nil {
+		m.saved.model = name
 	}
+	m.Options[modelOption] = modelLabel(name)
+}
 
-	// Parse the Q&A data
-	var qaFile QAFile
-	if err := json.Unmarshal(data, &qaFile); err != nil {
-		errMsg := fmt.Sprintf("Failed to parse Q&A file: %v", err)
-		// log.Println(errMsg)
-		m.setStatus(errMsg, 5*time.Second)
-		return
+// setTemperature changes the temperature the way setModel changes the model
+func (m *Model) setTemperature(t float64) {
+	m.Temperature = t
+	if m.saved != nil {
+		m.saved.temperature = t
 	}
+	m.Options[temperatureOption] = temperatureLabel(t)
+}
 
-	if len(qaFile.QAs) == 0 {
-		m.setStatus("No Q&A pairs found to index", 3*time.Second)
-		// log.Println("No Q&A pairs found in the file")
-		return
-	}
+// modelLabel returns the options menu entry for the model
+func modelLabel(name string) string {
+	return "Change Model: " + name
+}
 
-	// Index each Q&A pair
-	successCount := 0
-	totalQAs := len(qaFile.QAs)
-	m.setStatus(fmt.Sprintf("Starting to index %d Q&A pairs...", totalQAs), 0)
+// temperatureLabel returns the options menu entry for the temperature
+func temperatureLabel(t float64) string {
+	return fmt.Sprintf("Temperature: %.1f (use ↑/↓)", t)
+}
 
-	for i, qa := range qaFile.QAs {
-		if qa.Question == "" || qa.Answer == "" {
-			// log.Printf("Skipping empty Q&A at index %d", i)
-			continue
-		}
+// personaName returns the name of the active persona, or "" for none
+func (m *Model) personaName() string {
+	if m.Persona == nil {
+		return ""
+	}
+	return m.Persona.Name
+}
 
-		// Update status
-		progress := float64(i+1) / float64(totalQAs) * 100
-		m.setStatus(fmt.Sprintf("Indexing %d/%d (%.1f%%)...", i+1, totalQAs, progress), 0)
+// liveMetrics summarizes the answer being streamed
+func (m *Model) liveMetrics() string {
+	return formatLiveMetrics(m.StreamStarted, m.FirstTokenAt, m.StreamChunks)
+}
 
-		// Store the question and answer in the vector database
-		if err := m.StoreQA(qa.Question, qa.Answer); err != nil {
-			errMsg := fmt.Sprintf("Failed to index Q&A at index %d: %v", i, err)
-			// log.Println(errMsg)
-			m.setStatus(errMsg, 3*time.Second)
-			continue
-		}
-		successCount++
-		// log.Printf("Successfully indexed Q&A %d/%d", i+1, totalQAs)
+// formatLiveMetrics summarizes an answer still streaming: time to first token
+// and an estimate of the throughput, counting each chunk as one token
+func formatLiveMetrics(started, first time.Time, chunks int) string {
+	elapsed := time.Since(started)
+	if first.IsZero() {
+		return fmt.Sprintf("waiting for first token · %.1fs", elapsed.Seconds())
 	}
 
-	// Final status update
-	if successCount > 0 {
-		msg := fmt.Sprintf("✅ Successfully indexed %d/%d Q&A pairs", successCount, totalQAs)
-		// log.Println(msg)
-		m.setStatus(msg, 10*time.Second)
-	} else {
-		errMsg := "❌ No valid Q&A pairs were indexed"
-		// log.Println(errMsg)
-		m.setStatus(errMsg, 5*time.Second)
+	ttft := first.Sub(started)
+	rate := 0.0
+	if generating := elapsed - ttft; generating > 0 {
+		rate = float64(chunks) / generating.Seconds()
 	}
+	return fmt.Sprintf("~%.1f tok/s · first token %.2fs · %.1fs", rate, ttft.Seconds(), elapsed.Seconds())
 }
 
 // setStatus sets a status message that will be shown temporarily
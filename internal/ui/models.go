@@ -0,0 +1,339 @@
+// Package ui provides the terminal user interface components for the AskAI application.
+// This file implements the model picker and model downloads.
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pickerRows is how many models the picker shows at once
+const pickerRows = 10
+
+// modelsMsg carries the models listed by the backend
+type modelsMsg struct {
+	models []llm.ModelInfo
+	err    error
+}
+
+// pullMsg carries the next progress update of a model download, or its result
+type pullMsg struct {
+	name     string
+	updates  <-chan llm.PullProgress
+	result   <-chan error
+	progress llm.PullProgress
+	done     bool
+	err      error
+}
+
+// loadModels lists the backend's models in the background
+func loadModels(provider llm.ChatProvider) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		models, err := llm.ListModelInfos(ctx, provider)
+		return modelsMsg{models: models, err: err}
+	}
+}
+
+// openModelPicker shows the installed models, filtered by what is typed
+func (m *Model) openModelPicker() (tea.Model, tea.Cmd) {
+	m.EditingModel = true
+	m.ModelInput.Reset()
+	m.ModelInput.Focus()
+	m.ModelSel = 0
+	m.ModelMoved = false
+	m.LoadingModels = true
+	return m, tea.Batch(textinput.Blink, loadModels(m.Provider))
+}
+
+// closeModelPicker leaves the model picker
+func (m *Model) closeModelPicker() {
+	m.EditingModel = false
+	m.ModelInput.Blur()
+	m.ModelInput.Reset()
+}
+
+// handleModels shows the listed models, selecting the current one
+func (m *Model) handleModels(msg modelsMsg) {
+	m.LoadingModels = false
+	m.Models, m.ModelsErr = msg.models, msg.err
+	for i, model := range m.filteredModels() {
+		if model.Name == m.ModelName {
+			m.ModelSel = i
+		}
+	}
+}
+
+// filteredModels returns the models whose name contains the typed filter
+func (m *Model) filteredModels() []llm.ModelInfo {
+	filter := strings.ToLower(strings.TrimSpace(m.ModelInput.Value()))
+	if filter == "" {
+		return m.Models
+	}
+	var matches []llm.ModelInfo
+	for _, model := range m.Models {
+		if strings.Contains(strings.ToLower(model.Name), filter) {
+			matches = append(matches, model)
+		}
+	}
+	return matches
+}
+
+// installed returns the name of the installed model a name refers to.
+// Ollama's implied ":latest" tag may be left out, but a partial name such as
+// "llama3" for "llama3.1" is not installed.
+func (m *Model) installed(name string) (string, bool) {
+	for _, model := range m.Models {
+		if llm.SameModel(model.Name, name) {
+			return model.Name, true
+		}
+	}
+	return "", false
+}
+
+// handleModelInput handles input while the model picker is open.
+func (m *Model) handleModelInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC: // Handle both Esc and Ctrl+C to cancel
+		if m.Pulling != "" {
+			m.cancelPull()
+			m.setStatus(fmt.Sprintf("Download of %s cancelled", m.Pulling), 2*time.Second)
+			m.Pulling = ""
+		} else {
+			m.setStatus("Model change cancelled", 2*time.Second)
+		}
+		m.closeModelPicker()
+		return m, nil
+
+	case tea.KeyUp:
+		if m.ModelSel > 0 {
+			m.ModelSel--
+			m.ModelMoved = true
+		}
+		return m, nil
+
+	case tea.KeyDown:
+		if m.ModelSel < len(m.filteredModels())-1 {
+			m.ModelSel++
+			m.ModelMoved = true
+		}
+		return m, nil
+
+	case tea.KeyEnter:
+		if m.Pulling != "" {
+			return m, nil
+		}
+		// A typed name that merely matches part of an installed one, such as
+		// "llama3" for "llama3.1", is downloaded unless a match was picked
+		name := strings.TrimSpace(m.ModelInput.Value())
+		matches := m.filteredModels()
+		installed, ok := m.installed(name)
+		switch {
+		case ok && !m.ModelMoved:
+			m.selectModel(installed)
+			m.closeModelPicker()
+			return m, nil
+		case len(matches) > 0 && (name == "" || m.ModelMoved):
+			m.selectModel(matches[m.ModelSel].Name)
+			m.closeModelPicker()
+			return m, nil
+		case name == "" || m.LoadingModels:
+			return m, nil
+		case m.ModelsErr != nil:
+			// Without a model list there is nothing to check the name against
+			m.selectModel(name)
+			m.closeModelPicker()
+			return m, nil
+		default:
+			return m, m.startPull(name)
+		}
+
+	default:
+		if m.Pulling != "" {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.ModelInput, cmd = m.ModelInput.Update(msg)
+		m.ModelSel = 0
+		m.ModelMoved = false
+		return m, cmd
+	}
+}
+
+// selectModel switches to a model and saves it to the config
+func (m *Model) selectModel(name string) {
+	if err := config.SaveConfig(name, m.ownSettings().temperature, config.APIURL()); err != nil {
+		m.setStatus(fmt.Sprintf("Failed to save model: %v", err), 3*time.Second)
+		return
+	}
+	m.setModel(name)
+	m.setStatus(fmt.Sprintf("Model set to %s", name), 2*time.Second)
+}
+
+// startPull downloads a model in the background, streaming its progress
+func (m *Model) startPull(name string) tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelPull = cancel
+	m.Pulling = name
+	m.PullProgress = llm.PullProgress{Status: "starting"}
+
+	updates := make(chan llm.PullProgress)
+	result := make(chan error, 1)
+	provider := m.Provider
+	go func() {
+		defer close(updates)
+		result <- llm.PullModel(ctx, provider, name, func(p llm.PullProgress) {
+			select {
+			case updates <- p:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return nextPullMsg(name, updates, result)
+}
+
+// nextPullMsg waits for the next progress update of a download
+func nextPullMsg(name string, updates <-chan llm.PullProgress, result <-chan error) tea.Cmd {
+	return func() tea.Msg {
+		p, ok := <-updates
+		if !ok {
+			return pullMsg{name: name, done: true, err: <-result}
+		}
+		return pullMsg{name: name, updates: updates, result: result, progress: p}
+	}
+}
+
+// handlePull shows the progress of a download and selects the model once it
+// has finished
+func (m *Model) handlePull(msg pullMsg) (tea.Model, tea.Cmd) {
+	// Ignore a download that was cancelled
+	if msg.name != m.Pulling {
+		return m, nil
+	}
+	if !msg.done {
+		m.PullProgress = msg.progress
+		return m, nextPullMsg(msg.name, msg.updates, msg.result)
+	}
+
+	m.Pulling = ""
+	m.cancelPull()
+	if msg.err != nil {
+		if !errors.Is(msg.err, context.Canceled) {
+			m.setStatus(fmt.Sprintf("Failed to download %s: %v", msg.name, msg.err), 5*time.Second)
+		}
+		return m, nil
+	}
+
+	m.closeModelPicker()
+	m.selectModel(msg.name)
+	m.setStatus(fmt.Sprintf("Downloaded %s and switched to it", msg.name), 3*time.Second)
+	return m, nil
+}
+
+// renderModelPicker renders the model list, or the download in progress
+func (m Model) renderModelPicker() string {
+	var sb strings.Builder
+
+	if m.Pulling != "" {
+		sb.WriteString(fmt.Sprintf("Downloading %s (Esc to cancel)\n\n", m.Pulling))
+		sb.WriteString(m.PullProgress.Status)
+		if p := m.PullProgress; p.Total > 0 {
+			sb.WriteString(fmt.Sprintf("\n%s %3.0f%% %s / %s",
+				progressBar(float64(p.Completed)/float64(p.Total), 40),
+				float64(p.Completed)/float64(p.Total)*100,
+				llm.FormatSize(p.Completed), llm.FormatSize(p.Total)))
+		}
+		return sb.String()
+	}
+
+	sb.WriteString("Choose a model (type to filter, ↑/↓ to select, Enter to use, Esc to cancel):\n")
+	sb.WriteString(m.ModelInput.View())
+	sb.WriteString("\n\n")
+
+	switch {
+	case m.LoadingModels:
+		sb.WriteString(helpStyle.Render("Loading models…"))
+		return sb.String()
+	case m.ModelsErr != nil:
+		sb.WriteString(helpStyle.Render(fmt.Sprintf("Can't list models: %v\nEnter uses the name as typed.", m.ModelsErr)))
+		return sb.String()
+	}
+
+	matches := m.filteredModels()
+	if len(matches) == 0 {
+		if name := strings.TrimSpace(m.ModelInput.Value()); name != "" {
+			sb.WriteString(helpStyle.Render(fmt.Sprintf("%s is not installed. Press Enter to download it.", name)))
+		} else {
+			sb.WriteString(helpStyle.Render("No models installed. Type a name to download one."))
+		}
+		return sb.String()
+	}
+
+	// Scroll the list so the selection stays visible
+	start := 0
+	if m.ModelSel >= pickerRows {
+		start = m.ModelSel - pickerRows + 1
+	}
+	end := start + pickerRows
+	if end > len(matches) {
+		end = len(matches)
+	}
+	// Until a match is picked, Enter takes the typed name as it is
+	name := strings.TrimSpace(m.ModelInput.Value())
+	picking := name == "" || m.ModelMoved
+	for i := start; i < end; i++ {
+		model := matches[i]
+		cursor := "  "
+		if (picking && i == m.ModelSel) || (!picking && llm.SameModel(model.Name, name)) {
+			cursor = "➜ "
+		}
+		line := fmt.Sprintf("%s%-36s %10s  %s", cursor, model.Name, modelSize(model), modelDetails(model))
+		if model.Name == m.ModelName {
+			line += " (current)"
+		}
+		sb.WriteString(optionStyle.Render(line))
+		sb.WriteString("\n")
+	}
+	if len(matches) > pickerRows {
+		sb.WriteString(helpStyle.Render(fmt.Sprintf("%d of %d models", len(matches), len(m.Models))))
+		sb.WriteString("\n")
+	}
+	if _, ok := m.installed(name); !picking && !ok {
+		sb.WriteString(helpStyle.Render(fmt.Sprintf("%s is not installed. Press Enter to download it, or ↑/↓ to pick a match.", name)))
+	}
+	return sb.String()
+}
+
+// modelSize formats a model's size, or "" if unknown
+func modelSize(model llm.ModelInfo) string {
+	if model.Size == 0 {
+		return ""
+	}
+	return llm.FormatSize(model.Size)
+}
+
+// modelDetails formats a model's family, parameter count and quantization
+func modelDetails(model llm.ModelInfo) string {
+	var parts []string
+	for _, s := range []string{model.Family, model.ParameterSize, model.Quantization} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// progressBar renders a bar of the given width filled to fraction
+func progressBar(fraction float64, width int) string {
+	filled := int(max(0, min(fraction, 1)) * float64(width))
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
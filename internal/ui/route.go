@@ -0,0 +1,93 @@
+// Package ui provides the terminal user interface components for the AskAI application.
+// This file routes questions to the model or persona the routing table picks for them.
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// routeMsg carries the route picked for a question about to be sent
+type routeMsg struct {
+	id       int
+	turn     *pendingTurn
+	decision llm.RouteDecision
+	question string // The question without an @override prefix
+	err      error
+}
+
+// routeCmd picks the route for the turn's question
+func (m *Model) routeCmd(ctx context.Context, id int, turn *pendingTurn) tea.Cmd {
+	router, provider := m.Router, m.Provider
+	req := llm.ChatRequest{Model: turn.model, Options: turn.options}
+	return func() tea.Msg {
+		decision, question, err := router.Route(ctx, provider, req, turn.question)
+		return routeMsg{id: id, turn: turn, decision: decision, question: question, err: err}
+	}
+}
+
+// handleRoute sends the question along the route picked for it. If the
+// classifier failed, the question goes to the default route or current model.
+func (m *Model) handleRoute(msg routeMsg) (tea.Model, tea.Cmd) {
+	if msg.id != m.StreamID || !m.Streaming {
+		return m, nil
+	}
+	if msg.err != nil {
+		log.Printf("Failed to route question: %v", msg.err)
+		m.setStatus("Failed to classify the question; asking without a route", 3*time.Second)
+	}
+	turn := *msg.turn
+	m.applyRoute(&turn, msg.decision, msg.question)
+	return m.startTurn(&turn, m.prefix)
+}
+
+// applyRoute points the turn at the route's model and persona. A routed
+// persona's system prompt only opens a new conversation; later in a
+// conversation only its model and options apply.
+func (m *Model) applyRoute(turn *pendingTurn, d llm.RouteDecision, question string) {
+	turn.routed = true
+	turn.route = d.Label()
+
+	if question != turn.question {
+		turn.question = question
+		turn.messages[len(turn.messages)-1].Content = question
+	}
+
+	if d.Persona != "" {
+		p, ok := config.FindPersona(m.Personas, d.Persona)
+		if !ok {
+			m.setStatus(fmt.Sprintf("Route %s names unknown persona %q", d.Route, d.Persona), 3*time.Second)
+		} else {
+			turn.persona = p.Name
+			if p.Model != "" {
+				turn.model = p.Model
+			}
+			if p.Temperature != nil {
+				turn.options.Temperature = *p.Temperature
+			}
+			if p.Options != nil {
+				turn.options.GenerationOptions = turn.options.GenerationOptions.Merge(*p.Options)
+			}
+			if len(m.Conversation.Messages) == 0 && p.SystemPrompt != "" {
+				// Replace the current persona's system prompt with the route's
+				messages := []fs.Message{{Role: fs.RoleSystem, Content: p.SystemPrompt, Time: time.Now()}}
+				for _, msg := range turn.messages {
+					if msg.Role != fs.RoleSystem {
+						messages = append(messages, msg)
+					}
+				}
+				turn.messages = messages
+			}
+		}
+	}
+	if d.Model != "" {
+		turn.model = d.Model
+	}
+}
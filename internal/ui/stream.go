@@ -0,0 +1,432 @@
+// Package ui provides the terminal user interface components for the AskAI application.
+// This file handles streaming answers: starting, stopping and continuing them.
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// continuePrompt asks the model to pick up a stopped answer
+const continuePrompt = "Continue your previous answer exactly where it stopped. Do not repeat anything you already wrote."
+
+// pendingTurn is a question being answered, with everything needed to store
+// its answer or continue it after it was stopped
+type pendingTurn struct {
+	question string
+	// messages are what the turn adds to the conversation: the persona's
+	// system prompt if the conversation is new, and the question
+	messages []fs.Message
+	persona  string
+	model    string
+	options  llm.Options
+	template string   // The prompt template the question was written with, if any
+	images   []string // Images attached to the question, as paths inside the vault
+	// route describes the route the question was sent along, or is ""
+	route  string
+	routed bool // The routing table has been consulted
+	// memories are the long-term memories recalled for the question
+	memories []string
+	recalled bool // Memories have been looked up, whether or not any were found
+	// skipSummary sends the turn without summarizing, after summarizing failed
+	skipSummary bool
+}
+
+// streamMsg carries the next event of a stream with the ID of the stream it
+// belongs to, so events from a stream that was stopped can be ignored
+type streamMsg struct {
+	id     int
+	events <-chan llm.Event
+	event  llm.Event
+	closed bool // The stream ended without a done or error event
+}
+
+// nextStreamMsg waits for the next event of the stream with the given ID.
+// Only one is outstanding at a time, so events are handled in order.
+func nextStreamMsg(id int, events <-chan llm.Event) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := <-events
+		return streamMsg{id: id, events: events, event: ev, closed: !ok}
+	}
+}
+
+// metricsTickMsg re-renders the live metrics while an answer is streaming
+type metricsTickMsg struct{}
+
+// metricsTick schedules the next metrics refresh
+func metricsTick() tea.Cmd {
+	return tea.Tick(250*time.Millisecond, func(time.Time) tea.Msg { return metricsTickMsg{} })
+}
+
+// turnCompleteMsg is sent once an answer has been stored
+type turnCompleteMsg struct {
+	metrics  fs.Metrics
+	partial  bool   // The answer was stopped before it was complete
+	endpoint string // The profile that answered
+	saveErr  error  // Saving the conversation failed
+	storeErr error  // Storing the Q&A failed
+}
+
+// handleChatInput handles input when in chat mode.
+func (m *Model) handleChatInput() (tea.Model, tea.Cmd) {
+	question := m.TextInput.Value()
+	if question == "" {
+		return m, nil
+	}
+	if name, ok := templateCommand(question); ok {
+		return m.openTemplate(name)
+	}
+	if q, ok := compareCommand(question); ok {
+		return m.startCompare(q)
+	}
+	if file, ok := imageCommand(question); ok {
+		m.attachImage(file)
+		return m, nil
+	}
+	return m.ask(question, "")
+}
+
+// ask sends a question, continuing the conversation. template names the
+// prompt template the question was written with, or is "".
+func (m *Model) ask(question, template string) (tea.Model, tea.Cmd) {
+	m.LastQuestion = question
+
+	// The question is sent along with the conversation so far, opening a
+	// new conversation with the persona's system prompt.
+	var messages []fs.Message
+	if len(m.Conversation.Messages) == 0 && m.Persona != nil && m.Persona.SystemPrompt != "" {
+		messages = append(messages, fs.Message{Role: fs.RoleSystem, Content: m.Persona.SystemPrompt, Time: time.Now()})
+	}
+	images := m.takeAttachments()
+	messages = append(messages, fs.Message{Role: fs.RoleUser, Content: question, Images: images, Time: time.Now()})
+
+	return m.startTurn(&pendingTurn{
+		question: question,
+		messages: messages,
+		persona:  m.personaName(),
+		model:    m.ModelName,
+		options:  llm.Options{Temperature: m.Temperature, GenerationOptions: m.GenOptions},
+		template: template,
+		images:   images,
+	}, "")
+}
+
+// startTurn streams an answer to turn. A non-empty prefix is the part of the
+// answer received before it was stopped; the model is asked to continue it
+// and the stored answer is the prefix followed by the continuation.
+//
+// The conversation is fitted into the model's context window first. If its
+// earlier turns don't fit, they are summarized before the question is sent.
+func (m *Model) startTurn(turn *pendingTurn, prefix string) (tea.Model, tea.Cmd) {
+	m.releaseStream()
+
+	m.turn = turn
+	m.Stopped = false
+	m.Msg = prefix
+	m.Thinking = ""
+	m.ToolLog = nil
+	m.StreamStarted = time.Now()
+	m.FirstTokenAt = time.Time{}
+	m.StreamChunks = 0
+
+	m.StreamID++
+	m.Streaming = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelStream = cancel
+	m.prefix = prefix
+
+	if m.Router != nil && !turn.routed {
+		return m, tea.Batch(m.routeCmd(ctx, m.StreamID, turn), metricsTick())
+	}
+	if m.MemoryOn && !turn.recalled {
+		return m, tea.Batch(m.recallCmd(m.StreamID, turn), metricsTick())
+	}
+
+	messages := llm.WithMemories(append(m.Conversation.Prompt(), turn.messages...), turn.memories)
+	if prefix != "" {
+		messages = append(messages,
+			fs.Message{Role: fs.RoleAssistant, Content: prefix, Time: time.Now()},
+			fs.Message{Role: fs.RoleUser, Content: continuePrompt, Time: time.Now()},
+		)
+	}
+
+	plan := llm.PlanContext(m.Estimator, turn.model, llm.BudgetFor(turn.options), messages)
+	m.ContextPlan = &plan
+
+	// Only turns already in the conversation can be summarized
+	n := plan.Summarize
+	if unsummarized := m.Conversation.Unsummarized(); n > unsummarized {
+		n = unsummarized
+	}
+	if n > 0 && !turn.skipSummary {
+		m.setStatus("Summarizing earlier turns to fit the context window…", 0)
+		return m, tea.Batch(m.summarizeCmd(ctx, m.StreamID, turn, n, plan.SummaryTokens), metricsTick())
+	}
+	if plan.Summarize > 0 {
+		plan.Decisions = append(plan.Decisions, fmt.Sprintf("dropped %d earlier messages without a summary", plan.Summarize))
+	}
+
+	m.Events = llm.StreamToolEvents(ctx, m.Provider, llm.ChatRequest{
+		Model:     turn.model,
+		Messages:  plan.Messages,
+		Options:   turn.options,
+		VaultPath: m.VaultPath,
+	}, m.Tools)
+
+	return m, tea.Batch(nextStreamMsg(m.StreamID, m.Events), metricsTick())
+}
+
+// summaryMsg carries the summary of earlier turns, made before a question
+// whose conversation didn't fit the context window
+type summaryMsg struct {
+	id      int
+	turn    *pendingTurn
+	summary string
+	count   int // The number of messages summarized
+	err     error
+}
+
+// summarizeCmd summarizes the next count unsummarized messages of the
+// conversation, together with its earlier summary
+func (m *Model) summarizeCmd(ctx context.Context, id int, turn *pendingTurn, count, tokens int) tea.Cmd {
+	conv := m.Conversation
+	prompt := conv.Prompt()
+	start := len(prompt) - conv.Unsummarized()
+	messages := append([]fs.Message(nil), prompt[start:start+count]...)
+	previous, provider := conv.Summary, m.Provider
+	req := llm.ChatRequest{Model: turn.model, Options: turn.options}
+
+	return func() tea.Msg {
+		summary, err := llm.Summarize(ctx, provider, req, previous, messages, tokens)
+		return summaryMsg{id: id, turn: turn, summary: summary, count: count, err: err}
+	}
+}
+
+// handleSummary records the summary in the conversation and sends the
+// question. If summarizing failed, the question is sent without a summary.
+func (m *Model) handleSummary(msg summaryMsg) (tea.Model, tea.Cmd) {
+	if msg.id != m.StreamID || !m.Streaming {
+		return m, nil
+	}
+	if msg.err != nil || msg.summary == "" {
+		log.Printf("Failed to summarize conversation: %v", msg.err)
+		m.setStatus("Failed to summarize earlier turns; dropping them instead", 5*time.Second)
+		turn := *msg.turn
+		turn.skipSummary = true
+		return m.startTurn(&turn, m.prefix)
+	}
+
+	m.Conversation.Summarize(msg.summary, msg.count)
+	if err := fs.SaveConversation(m.VaultPath, m.Conversation); err != nil {
+		log.Printf("Failed to save conversation: %v", err)
+	}
+	m.setStatus("Summarized earlier turns", 2*time.Second)
+	return m.startTurn(msg.turn, m.prefix)
+}
+
+// recordTurn appends a question and its answer to the conversation and saves
+// it, so the next question sees this turn whether or not storing the Q&A has
+// finished. toolMessages are the tool calls and results exchanged before the answer.
+func (m *Model) recordTurn(turn *pendingTurn, answer string, toolMessages []fs.Message) error {
+	// Record the system prompt and question that were sent
+	if len(m.Conversation.Messages) == 0 {
+		m.Conversation.Persona = turn.persona
+	}
+	for _, sent := range turn.messages {
+		m.Conversation.AppendMessage(sent)
+	}
+	for _, tool := range toolMessages {
+		m.Conversation.AppendMessage(tool)
+	}
+	m.Conversation.Append(fs.RoleAssistant, answer)
+
+	if err := fs.SaveConversation(m.VaultPath, m.Conversation); err != nil {
+		log.Printf("Failed to save conversation: %v", err)
+		return err
+	}
+	return nil
+}
+
+// storeTurn records a question and its answer in the conversation and returns
+// the command that saves them to the vault and the vector store.
+// resp is the completed response, or nil for an answer that was stopped.
+func (m *Model) storeTurn(turn *pendingTurn, answer string, resp *llm.ChatResponse) tea.Cmd {
+	entry := fs.QA{Question: turn.question, Answer: answer, Persona: turn.persona, Model: turn.model, Template: turn.template, Images: turn.images}
+	msg := turnCompleteMsg{partial: resp == nil}
+	var toolMessages []fs.Message
+	if resp != nil {
+		metrics := resp.Metrics
+		entry.Metrics = &metrics
+		entry.Endpoint = resp.Endpoint
+		if resp.Model != "" {
+			entry.Model = resp.Model
+		}
+		if m.KeepThinking {
+			entry.Thinking = resp.Thinking
+		}
+		msg.metrics = resp.Metrics
+		msg.endpoint = resp.Endpoint
+		toolMessages = resp.ToolMessages
+	}
+	if data, err := json.Marshal(turn.options); err == nil {
+		entry.Options = data
+	}
+	msg.saveErr = m.recordTurn(turn, answer, toolMessages)
+
+	a := m.App
+	return func() tea.Msg {
+		if _, err := a.StoreQA(entry); err != nil {
+			log.Printf("Failed to save Q&A: %v", err)
+			msg.storeErr = err
+		}
+		return msg
+	}
+}
+
+// handleStreamMsg handles the next event of a stream
+func (m *Model) handleStreamMsg(msg streamMsg) (tea.Model, tea.Cmd) {
+	// Ignore whatever is still in flight from a stream that was stopped
+	if msg.id != m.StreamID || !m.Streaming {
+		return m, nil
+	}
+	if msg.closed {
+		m.Streaming = false
+		m.releaseStream()
+		return m, nil
+	}
+
+	switch ev := msg.event; ev.Kind {
+	case llm.EventToken:
+		m.Msg += ev.Text
+		if m.FirstTokenAt.IsZero() {
+			m.FirstTokenAt = time.Now()
+		}
+		m.StreamChunks++
+
+	case llm.EventThinking:
+		m.Thinking += ev.Text
+		if m.FirstTokenAt.IsZero() {
+			m.FirstTokenAt = time.Now()
+		}
+		m.StreamChunks++
+
+	case llm.EventToolCall:
+		m.askTool(ev)
+
+	case llm.EventToolResult:
+		m.showToolResult(ev.Text)
+
+	case llm.EventDone:
+		return m, m.handleStreamDone(ev.Response)
+
+	case llm.EventError:
+		m.handleStreamError(ev.Err)
+		return m, nil
+	}
+	return m, nextStreamMsg(msg.id, msg.events)
+}
+
+// handleStreamDone ends a stream that completed and stores its answer.
+func (m *Model) handleStreamDone(resp *llm.ChatResponse) tea.Cmd {
+	m.Streaming = false
+	m.releaseStream()
+
+	// A continuation may add nothing, leaving the stopped answer as it was
+	turn, answer := m.turn, m.prefix+resp.Content
+	if turn == nil || answer == "" {
+		return nil
+	}
+	// Learn how the model tokenizes from a prompt that was sent exactly once
+	if m.ContextPlan != nil && len(resp.ToolMessages) == 0 {
+		m.Estimator.Observe(turn.model, m.ContextPlan.Messages, resp.Metrics.PromptTokens)
+	}
+	return m.storeTurn(turn, answer, resp)
+}
+
+// handleStreamError shows the error a stream failed with. An answer that
+// failed partway stays on screen as if it had been stopped, so it can be
+// kept with Ctrl+K or continued with Ctrl+R.
+func (m *Model) handleStreamError(err error) {
+	m.Streaming = false
+	m.releaseStream()
+	if strings.TrimSpace(m.Msg) == "" {
+		m.Msg = "Error: " + err.Error()
+		return
+	}
+	m.Stopped = true
+	m.setStatus(fmt.Sprintf("Error: %v · Ctrl+K to keep the partial answer, Ctrl+R to continue", err), 10*time.Second)
+}
+
+// stopStreaming cancels the answer being streamed. The HTTP request is
+// aborted and whatever arrived so far stays on screen, where it can be
+// kept with Ctrl+K or continued with Ctrl+R.
+func (m *Model) stopStreaming() {
+	m.Streaming = false
+	m.releaseStream()
+	if strings.TrimSpace(m.Msg) == "" {
+		return
+	}
+	m.Stopped = true
+	m.setStatus("Stopped · Ctrl+K to keep the partial answer, Ctrl+R to continue", 10*time.Second)
+}
+
+// releaseStream cancels the current stream, if any, which aborts its HTTP
+// request and closes its event channel.
+func (m *Model) releaseStream() {
+	m.PendingTool = nil
+	if m.cancelStream != nil {
+		m.cancelStream()
+	}
+	m.cancelStream = nil
+	m.Events = nil
+}
+
+// keepPartialAnswer stores a stopped answer as it is
+func (m *Model) keepPartialAnswer() tea.Cmd {
+	turn, answer := m.turn, m.Msg
+	m.Stopped = false
+	m.turn = nil
+	if turn == nil {
+		return nil
+	}
+	return m.storeTurn(turn, answer, nil)
+}
+
+// continueAnswer asks the model to carry on with a stopped answer
+func (m *Model) continueAnswer() (tea.Model, tea.Cmd) {
+	if m.turn == nil {
+		m.Stopped = false
+		return m, nil
+	}
+	return m.startTurn(m.turn, m.Msg)
+}
+
+// handleTurnComplete reports whether a finished question and answer were saved.
+func (m *Model) handleTurnComplete(msg turnCompleteMsg) {
+	if msg.saveErr != nil {
+		m.setStatus("Failed to save conversation", 3*time.Second)
+		return
+	}
+	if msg.storeErr != nil {
+		m.setStatus("Failed to save Q&A", 3*time.Second)
+		return
+	}
+	if msg.partial {
+		m.setStatus("✅ Partial answer saved", 5*time.Second)
+		return
+	}
+	status := "✅ " + llm.FormatMetrics(msg.metrics)
+	if msg.endpoint != "" && msg.endpoint != m.Profile {
+		status += " · answered by " + msg.endpoint
+	}
+	m.setStatus(status, 10*time.Second)
+}
@@ -32,6 +32,13 @@ var (
 			Foreground(lipgloss.Color("63")).
 			MarginBottom(1)
 
+	// thinkingStyle defines the styling for a model's reasoning, dimmed so it
+	// stands apart from the answer
+	thinkingStyle = lipgloss.NewStyle().
+			Faint(true).
+			Foreground(lipgloss.Color(config.MainColorBackgroundMute)).
+			Width(76)
+
 	// statusStyle defines the styling for status messages
 	statusStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("241")).
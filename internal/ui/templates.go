@@ -0,0 +1,142 @@
+// Package ui provides the terminal user interface components for the AskAI application.
+// This file handles prompt templates: picking one from the chat input and filling in its placeholders.
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/types"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// templateCommand reports whether the chat input asks for a template, as in
+// "/t explain-error" or "/template explain-error", and returns its name
+func templateCommand(input string) (string, bool) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 || (fields[0] != "/t" && fields[0] != "/template") {
+		return "", false
+	}
+	return strings.Join(fields[1:], " "), true
+}
+
+// openTemplate opens the form for a template's placeholders. A template
+// without placeholders is sent right away; without a name the available
+// templates are listed.
+func (m *Model) openTemplate(name string) (tea.Model, tea.Cmd) {
+	templates, err := config.LoadTemplates()
+	if err != nil {
+		m.setStatus(fmt.Sprintf("Failed to load templates: %v", err), 5*time.Second)
+		return m, nil
+	}
+	names := make([]string, len(templates))
+	for i, t := range templates {
+		names[i] = t.Name
+	}
+	if name == "" {
+		m.setStatus("Templates: "+strings.Join(names, ", "), 10*time.Second)
+		return m, nil
+	}
+	t, ok := config.FindTemplate(templates, name)
+	if !ok {
+		m.setStatus(fmt.Sprintf("Unknown template %q; available: %s", name, strings.Join(names, ", ")), 5*time.Second)
+		return m, nil
+	}
+
+	m.Template = t
+	m.TemplateFields = make([]textarea.Model, len(t.Variables()))
+	for i := range m.TemplateFields {
+		field := textarea.New()
+		field.CharLimit = 0
+		field.SetWidth(80)
+		field.SetHeight(4)
+		field.ShowLineNumbers = false
+		m.TemplateFields[i] = field
+	}
+	m.TemplateField = 0
+	if len(m.TemplateFields) == 0 {
+		return m.sendTemplate()
+	}
+
+	m.ScreenMode = types.ModeTemplate
+	return m, m.TemplateFields[0].Focus()
+}
+
+// handleTemplateKeyPress handles input in the template form.
+// Tab and Shift+Tab switch fields, Ctrl+S sends, Esc cancels.
+func (m *Model) handleTemplateKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.closeTemplate()
+		m.setStatus("Template cancelled", 2*time.Second)
+		return m, nil
+
+	case tea.KeyTab, tea.KeyShiftTab:
+		step := 1
+		if msg.Type == tea.KeyShiftTab {
+			step = len(m.TemplateFields) - 1
+		}
+		m.TemplateFields[m.TemplateField].Blur()
+		m.TemplateField = (m.TemplateField + step) % len(m.TemplateFields)
+		return m, m.TemplateFields[m.TemplateField].Focus()
+
+	case tea.KeyCtrlS:
+		return m.sendTemplate()
+	}
+
+	var cmd tea.Cmd
+	m.TemplateFields[m.TemplateField], cmd = m.TemplateFields[m.TemplateField].Update(msg)
+	return m, cmd
+}
+
+// sendTemplate renders the template with the form's values and asks it
+func (m *Model) sendTemplate() (tea.Model, tea.Cmd) {
+	t := m.Template
+	values := make(map[string]string, len(m.TemplateFields))
+	for i, name := range t.Variables() {
+		values[name] = strings.TrimSpace(m.TemplateFields[i].Value())
+	}
+	question, err := t.Render(values)
+	if err != nil {
+		m.setStatus(err.Error(), 5*time.Second)
+		return m, nil
+	}
+
+	m.closeTemplate()
+	return m.ask(question, t.Name)
+}
+
+// closeTemplate leaves the template form
+func (m *Model) closeTemplate() {
+	m.Template = nil
+	m.TemplateFields = nil
+	m.ScreenMode = types.ModeChat
+}
+
+// renderTemplateForm renders an input for each of the template's placeholders
+func (m Model) renderTemplateForm() string {
+	var sb strings.Builder
+	sb.WriteString(titleStyle.Render("Template: " + m.Template.Name))
+	sb.WriteString("\n")
+	if m.Template.Description != "" {
+		sb.WriteString(helpStyle.Render(m.Template.Description))
+		sb.WriteString("\n")
+	}
+	for i, name := range m.Template.Variables() {
+		sb.WriteString("\n")
+		label := name + ":"
+		if i == m.TemplateField {
+			label = optionStyle.Render("➜ " + label)
+		} else {
+			label = "  " + label
+		}
+		sb.WriteString(label)
+		sb.WriteString("\n")
+		sb.WriteString(m.TemplateFields[i].View())
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
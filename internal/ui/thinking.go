@@ -0,0 +1,41 @@
+// Package ui provides the terminal user interface components for the AskAI application.
+// This file handles the reasoning that thinking models write before their answer.
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+)
+
+// toggleKeepThinking switches whether reasoning is stored with each Q&A
+func (m *Model) toggleKeepThinking() {
+	keep := !m.KeepThinking
+	if err := config.SaveKeepThinking(keep); err != nil {
+		m.setStatus(fmt.Sprintf("Failed to save setting: %v", err), 3*time.Second)
+		return
+	}
+	m.KeepThinking = keep
+	m.Options[thinkingOption] = keepThinkingLabel(keep)
+}
+
+// keepThinkingLabel returns the options menu entry for storing reasoning
+func keepThinkingLabel(keep bool) string {
+	if keep {
+		return "Keep thinking: on (stored with each Q&A, not embedded)"
+	}
+	return "Keep thinking: off"
+}
+
+// renderThinking renders the reasoning of the current answer, dimmed, or a
+// one-line summary of it while collapsed
+func (m Model) renderThinking() string {
+	words := len(strings.Fields(m.Thinking))
+	if !m.ShowThinking {
+		return helpStyle.Render(fmt.Sprintf("💭 Thinking · %d words · Ctrl+T to expand", words))
+	}
+	return helpStyle.Render(fmt.Sprintf("💭 Thinking · %d words · Ctrl+T to collapse", words)) +
+		"\n" + thinkingStyle.Render(strings.TrimSpace(m.Thinking))
+}
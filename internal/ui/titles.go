@@ -0,0 +1,54 @@
+// Package ui provides the terminal user interface components for the AskAI application.
+// This file titles and summarizes conversations in the background once they have ended.
+package ui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/app"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// titledMsg is sent once a background titling run has finished
+type titledMsg struct {
+	titled []*fs.Conversation
+	err    error
+}
+
+// titleCmd titles the recent conversations that need it, leaving out the one
+// in progress. Only one run goes at a time; conversations a skipped run would
+// have titled are picked up by the next.
+func (m *Model) titleCmd() tea.Cmd {
+	if m.titling {
+		return nil
+	}
+	m.titling = true
+	a, provider, exclude := m.App, m.Provider, m.Conversation.ID
+	req := llm.ChatRequest{Model: m.ModelName, Options: llm.Options{Temperature: m.Temperature, GenerationOptions: m.GenOptions}}
+	if m.TitleModel != "" {
+		req.Model = m.TitleModel
+	}
+	return func() tea.Msg {
+		titled, err := a.TitleConversations(context.Background(), provider, req, time.Now().Add(-app.TitleWindow), exclude)
+		return titledMsg{titled: titled, err: err}
+	}
+}
+
+// handleTitled reports the conversations a background run titled
+func (m *Model) handleTitled(msg titledMsg) {
+	m.titling = false
+	if msg.err != nil {
+		log.Printf("Failed to title conversations: %v", msg.err)
+	}
+	switch n := len(msg.titled); {
+	case n == 1:
+		m.setStatus(fmt.Sprintf("📝 Saved conversation as %q", msg.titled[0].Title), 3*time.Second)
+	case n > 1:
+		m.setStatus(fmt.Sprintf("📝 Titled %d conversations", n), 3*time.Second)
+	}
+}
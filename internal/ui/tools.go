@@ -0,0 +1,74 @@
+// Package ui provides the terminal user interface components for the AskAI application.
+// This file handles tool use: turning it on, confirming calls and showing results.
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/VarunSharma3520/AskAI/internal/config"
+	"github.com/VarunSharma3520/AskAI/internal/fs"
+	"github.com/VarunSharma3520/AskAI/internal/llm"
+	"github.com/VarunSharma3520/AskAI/internal/tools"
+)
+
+// toolResultLines is how much of a tool result the chat view shows
+const toolResultLines = 6
+
+// toggleTools turns tool use on or off. The file tools are confined to the
+// directory AskAI was started in. Only the Ollama backend supports tool
+// calls, so they can't be turned on under other profiles.
+func (m *Model) toggleTools() {
+	if m.Tools != nil {
+		m.Tools = nil
+		m.Options[toolsOption] = "Tools: off"
+		m.setStatus("Tool use turned off", 2*time.Second)
+		return
+	}
+	if kind := m.Provider.Name(); kind != config.ProviderOllama {
+		m.setStatus(fmt.Sprintf("Tools need the ollama backend; profile %s uses %s", m.Profile, kind), 3*time.Second)
+		return
+	}
+
+	root, err := os.Getwd()
+	if err != nil {
+		m.setStatus(fmt.Sprintf("Failed to turn on tools: %v", err), 3*time.Second)
+		return
+	}
+	m.Tools = tools.Builtin(root, m.App, m.KnowledgeBase)
+	m.Options[toolsOption] = "Tools: on (read_file, list_directory, grep, search_vault)"
+	m.setStatus("Tool use turned on; every call asks for confirmation", 3*time.Second)
+}
+
+// askTool shows a tool call and waits for the user to confirm it
+func (m *Model) askTool(ev llm.Event) {
+	m.PendingTool = &ev
+	m.ToolLog = append(m.ToolLog, "🔧 "+formatToolCall(*ev.ToolCall))
+}
+
+// answerTool lets the pending tool call run, or declines it
+func (m *Model) answerTool(ok bool) {
+	if m.PendingTool == nil {
+		return
+	}
+	m.PendingTool.Approve(ok)
+	m.PendingTool = nil
+}
+
+// showToolResult adds the start of a tool result to the tool log
+func (m *Model) showToolResult(result string) {
+	lines := strings.Split(strings.TrimRight(result, "\n"), "\n")
+	if len(lines) > toolResultLines {
+		lines = append(lines[:toolResultLines], fmt.Sprintf("… %d more lines", len(lines)-toolResultLines))
+	}
+	for _, line := range lines {
+		m.ToolLog = append(m.ToolLog, "   "+truncateText(line, 100))
+	}
+}
+
+// formatToolCall renders a tool call as name(arguments)
+func formatToolCall(call fs.ToolCall) string {
+	return fmt.Sprintf("%s(%s)", call.Name, truncateText(string(call.Arguments), 200))
+}
@@ -4,13 +4,11 @@ package ui
 
 import (
 	"fmt"
-	"log"
 	"math"
 	"strings"
 	"time"
 
 	"github.com/VarunSharma3520/AskAI/internal/config"
-	"github.com/VarunSharma3520/AskAI/internal/llm"
 	"github.com/VarunSharma3520/AskAI/internal/types"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -30,14 +28,21 @@ func max(x, y float64) float64 {
 // It's part of the Bubble Tea framework's model interface.
 //
 // Returns:
-//   - tea.Cmd: A command that makes the text input cursor blink.
+//   - tea.Cmd: A command that makes the text input cursor blink and starts
+//     titling ended conversations through titleCmd, like every later run.
 //
 // Example:
 //
 //	program := tea.NewProgram(model)
 //	// The Init method will be called automatically by Bubble Tea
-func (m Model) Init() tea.Cmd {
-	return textinput.Blink // Reuse Bubbles' blink command for the text input
+func (m *Model) Init() tea.Cmd {
+	// Reuse Bubbles' blink command for the text input, and title the
+	// conversations that ended when AskAI was last closed
+	cmds := []tea.Cmd{textinput.Blink, m.titleCmd()}
+	if m.MemoryOn {
+		cmds = append(cmds, memoryTick())
+	}
+	return tea.Batch(cmds...)
 }
 
 // Update is the main update function that handles all messages and updates the model state.
@@ -61,22 +66,62 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		return m.handleKeyMsg(msg)
 
-	case types.TokenMsg:
-		m.Msg += string(msg)
-		// Request an immediate re-render by returning a command that does nothing
-		if m.StreamCh != nil && m.ErrCh != nil {
-			return m, tea.Batch(
-				llm.NextTokenCmd(m.StreamCh, m.ErrCh),
-				// Force a re-render
-				func() tea.Msg { return nil },
-			)
+	case streamMsg:
+		return m.handleStreamMsg(msg)
+
+	case types.StatusMsg:
+		m.setStatus(msg.Message, msg.Duration)
+
+	case compareMsg:
+		return m.handleCompareMsg(msg)
+
+	case compareStoredMsg:
+		m.handleCompareStored(msg)
+
+	case summaryMsg:
+		return m.handleSummary(msg)
+
+	case recallMsg:
+		return m.handleRecall(msg)
+
+	case memoryTickMsg:
+		return m, m.handleMemoryTick()
+
+	case memoryDistilledMsg:
+		m.handleMemoryDistilled(msg)
+
+	case titledMsg:
+		m.handleTitled(msg)
+
+	case routeMsg:
+		return m.handleRoute(msg)
+
+	case turnCompleteMsg:
+		m.handleTurnComplete(msg)
+
+	case metricsTickMsg:
+		// Keep the live metrics ticking while waiting for tokens
+		if m.Streaming || (m.Compare != nil && m.Compare.running()) {
+			return m, metricsTick()
 		}
 
-	case types.StreamEndMsg:
-		m.handleStreamEnd()
+	case duplicatesMsg:
+		m.handleDuplicates(msg)
 
-	case types.StreamErrMsg:
-		m.handleStreamError()
+	case dedupeActionMsg:
+		m.handleDedupeAction(msg)
+
+	case historyMsg:
+		m.handleHistory(msg)
+
+	case modelsMsg:
+		m.handleModels(msg)
+
+	case indexMsg:
+		return m, m.handleIndex(msg)
+
+	case pullMsg:
+		return m.handlePull(msg)
 	}
 
 	return m, nil
@@ -84,6 +129,19 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // handleKeyMsg processes keyboard input messages.
 func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.ScreenMode {
+	case types.ModeDedupe:
+		return m.handleDedupeKeyPress(msg)
+	case types.ModeHistory:
+		return m.handleHistoryKeyPress(msg)
+	case types.ModeTemplate:
+		return m.handleTemplateKeyPress(msg)
+	case types.ModeCompare:
+		return m.handleCompareKeyPress(msg)
+	case types.ModeMemories:
+		return m.handleMemoriesKeyPress(msg)
+	}
+
 	// If we're in options mode, handle all keys through handleOptionsKeyPress.
 	if m.ScreenMode == types.ModeOptions {
 		// If we're editing a field, handle that first.
@@ -92,6 +150,10 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m.handleModelInput(msg)
 		case m.EditingAPIURL:
 			return m.handleAPIURLInput(msg)
+		case m.EditingGenOption:
+			return m.handleGenOptionInput(msg)
+		case m.EditingCompare:
+			return m.handleCompareInput(msg)
 		default:
 			return m.handleOptionsKeyPress(msg)
 		}
@@ -110,22 +172,46 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case tea.KeyEsc:
 		if m.Streaming {
 			m.stopStreaming()
-			m.Msg = ""
 			return m, nil
 		}
 		return m, tea.Quit
 
 	case tea.KeyCtrlW:
-		m.safeCloseChannels()
+		m.releaseStream()
 		return m, tea.Quit
 
+	case tea.KeyRunes:
+		// Answer a tool confirmation instead of typing
+		if m.PendingTool != nil && len(msg.Runes) == 1 {
+			switch msg.Runes[0] {
+			case 'y', 'Y':
+				m.answerTool(true)
+			case 'n', 'N':
+				m.answerTool(false)
+			}
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.TextInput, cmd = m.TextInput.Update(msg)
+		return m, cmd
+
+	case tea.KeyCtrlK: // Keep a stopped, partial answer
+		if m.Stopped {
+			return m, m.keepPartialAnswer()
+		}
+
+	case tea.KeyCtrlR: // Continue a stopped answer
+		if m.Stopped {
+			return m.continueAnswer()
+		}
+
 	case tea.KeyEnter:
 		if !m.Streaming {
 			return m.handleChatInput()
 		}
 
-	case tea.KeyRunes, tea.KeyBackspace:
-		// Handle regular typing and backspace for the main chat input.
+	case tea.KeyBackspace:
+		// Handle backspace for the main chat input.
 		var cmd tea.Cmd
 		m.TextInput, cmd = m.TextInput.Update(msg)
 		return m, cmd
@@ -139,8 +225,19 @@ func (m *Model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Move cursor to start to select all text
 		m.TextInput.CursorStart()
 
-	case tea.KeyCtrlS: // Use Ctrl+S for storing current question
-		go m.StoreCurrentQuestion()
+	case tea.KeyCtrlS: // Use Ctrl+S for indexing the vault into Qdrant
+		return m, m.indexCmd()
+
+	case tea.KeyCtrlT: // Expand or collapse the model's reasoning
+		m.ShowThinking = !m.ShowThinking
+
+	case tea.KeyCtrlD: // Show how the prompt was fitted into the context window
+		m.ShowContext = !m.ShowContext
+
+	case tea.KeyCtrlN: // Start a new conversation
+		if !m.Streaming {
+			return m, m.NewConversation()
+		}
 	}
 
 	return m, nil
@@ -167,13 +264,12 @@ func (m *Model) handleOptionsKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyUp, tea.KeyDown: // Handle up/down arrow keys for temperature
-		if m.SelectedOpt == 1 && !m.EditingModel && !m.EditingAPIURL {
+		if m.SelectedOpt == temperatureOption && !m.EditingModel && !m.EditingAPIURL {
 			if msg.Type == tea.KeyUp {
-				m.Temperature = math.Min(m.Temperature+0.1, 2.0)
+				m.setTemperature(math.Min(m.Temperature+0.1, 2.0))
 			} else {
-				m.Temperature = math.Max(m.Temperature-0.1, 0.1)
+				m.setTemperature(math.Max(m.Temperature-0.1, 0.1))
 			}
-			m.Options[1] = fmt.Sprintf("Temperature: %.1f (use ↑/↓)", m.Temperature)
 			return m, nil
 		}
 
@@ -206,43 +302,6 @@ func (m *Model) handleOptionsKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// handleModelInput handles input when editing the model name.
-func (m *Model) handleModelInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// First, update the model input with the current message.
-	var cmd tea.Cmd
-	m.ModelInput, cmd = m.ModelInput.Update(msg)
-
-	switch msg.Type {
-	case tea.KeyEsc, tea.KeyCtrlC: // Handle both Esc and Ctrl+C to cancel
-		m.EditingModel = false
-		m.ModelInput.Blur()
-		m.ModelInput.Reset()
-		m.setStatus("Model change cancelled", 2*time.Second)
-		return m, cmd
-
-	case tea.KeyEnter:
-		// Save the new model name.
-		newModel := strings.TrimSpace(m.ModelInput.Value())
-		if newModel != "" {
-			// Save to config.
-			if err := config.SaveConfig(newModel, m.Temperature, config.APIURL()); err != nil {
-				m.setStatus(fmt.Sprintf("Failed to save model: %v", err), 3*time.Second)
-			} else {
-				m.ModelName = newModel
-				m.Options[0] = "Change Model: " + newModel
-				m.setStatus(fmt.Sprintf("Model set to %s", newModel), 2*time.Second)
-			}
-		}
-		m.EditingModel = false
-		m.ModelInput.Blur()
-		m.ModelInput.Reset()
-		return m, nil
-
-	default:
-		return m, cmd
-	}
-}
-
 // handleAPIURLInput handles input when editing the API URL.
 func (m *Model) handleAPIURLInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.Type {
@@ -254,11 +313,11 @@ func (m *Model) handleAPIURLInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		newURL := strings.TrimSpace(m.APIURLInput.Value())
 		if newURL != "" {
 			// Save the configuration with the new API URL.
-			if err := config.SaveConfig(m.ModelName, m.Temperature, newURL); err != nil {
+			own := m.ownSettings()
+			if err := config.SaveConfig(own.model, own.temperature, newURL); err != nil {
 				m.setStatus(fmt.Sprintf("Failed to save API URL: %v", err), 3*time.Second)
 			} else {
-				// Keep "Set API URL" consistently at Options[2].
-				m.Options[2] = "Set API URL: " + newURL
+				m.Options[apiURLOption] = "Set API URL: " + newURL
 				m.setStatus("API URL updated", 2*time.Second)
 			}
 		}
@@ -272,77 +331,17 @@ func (m *Model) handleAPIURLInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
-// handleChatInput handles input when in chat mode.
-func (m *Model) handleChatInput() (tea.Model, tea.Cmd) {
-	question := m.TextInput.Value()
-	if question == "" {
-		// log.Println("Empty question, ignoring input")
-		return m, nil
-	}
-
-	// log.Printf("Processing question: %s", question)
-	m.LastQuestion = question
-	m.Msg = ""
-
-	// Initialize channels.
-	m.ensureChannels()
-	m.Streaming = true
-	// log.Println("Streaming started")
-
-	// Create a channel to collect the full response.
-	responseCh := make(chan string, 1)
-
-	// Start the streaming with the response collector.
-	// log.Printf("Starting stream with model: %s, temperature: %.2f", m.ModelName, m.Temperature)
-	start := llm.StartStreamCmdWithCallback(
-		config.APIURL(), m.ModelName, question, m.Temperature,
-		m.StreamCh, m.ErrCh, m.StopCh, responseCh,
-	)
-	// log.Println("Stream command started")
-
-	// Command to save the full response when it's ready.
-	saveCmd := func() tea.Msg {
-		// log.Println("Waiting for full response from channel...")
-		fullResponse := <-responseCh
-		// log.Printf("Received full response (length: %d)", len(fullResponse))
-
-		if fullResponse != "" {
-			// Save the conversation to the vault.
-			// log.Println("Saving conversation to vault...")
-			if err := m.StoreQA(question, fullResponse); err != nil {
-				errMsg := fmt.Sprintf("Failed to save conversation: %v", err)
-				log.Println(errMsg)
-				return types.StatusMsg{
-					Message:  "Failed to save conversation",
-					Duration: 3 * time.Second,
-				}
-			}
-			// Optional: success status.
-			return types.StatusMsg{
-				Message:  "Conversation saved to vault",
-				Duration: 3 * time.Second,
-			}
-		}
-		return nil
-	}
-
-	return m, tea.Batch(start, llm.NextTokenCmd(m.StreamCh, m.ErrCh), saveCmd)
-}
-
 // handleOptionsSelection handles option selection in the options menu.
 func (m *Model) handleOptionsSelection() (tea.Model, tea.Cmd) {
 	switch m.SelectedOpt {
-	case 0: // Change Model
-		m.EditingModel = true
-		m.ModelInput.SetValue(m.ModelName)
-		m.ModelInput.Focus()
-		return m, textinput.Blink
+	case modelOption:
+		return m.openModelPicker()
 
-	case 1: // Toggle Temperature
+	case temperatureOption:
 		// Actual changes handled with Up/Down arrows.
 		return m, nil
 
-	case 2: // Set API URL
+	case apiURLOption:
 		m.EditingAPIURL = true
 		m.APIURLInput.SetValue(config.APIURL())
 		m.APIURLInput.Focus()
@@ -355,13 +354,14 @@ func (m *Model) handleOptionsSelection() (tea.Model, tea.Cmd) {
 			apiURL = config.APIURL()
 		}
 
-		// Save the current settings to the config file.
-		if err := config.SaveConfig(m.ModelName, m.Temperature, apiURL); err != nil {
+		// Save the user's own settings; the persona's are applied again on load.
+		own := m.ownSettings()
+		if err := config.SaveSettings(own.model, own.temperature, apiURL, m.personaName(), own.genOptions); err != nil {
 			m.setStatus(fmt.Sprintf("Failed to save settings: %v", err), 3*time.Second)
 		} else {
 			// Update the displayed options with the new values.
-			m.Options[0] = "Change Model: " + m.ModelName
-			m.Options[2] = "Set API URL: " + apiURL
+			m.Options[modelOption] = modelLabel(m.ModelName)
+			m.Options[apiURLOption] = "Set API URL: " + apiURL
 			m.setStatus("Settings saved successfully!", 2*time.Second)
 		}
 		return m, nil
@@ -372,92 +372,77 @@ func (m *Model) handleOptionsSelection() (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case 5: // Update Qdrant index
-		go m.StoreCurrentQuestion()
 		m.ScreenMode = types.ModeChat
 		m.SelectedOpt = 0
+		return m, m.indexCmd()
+
+	case 6: // Review near-duplicates
+		m.ScreenMode = types.ModeDedupe
+		m.LoadingDupes = true
+		m.Clusters = nil
+		return m, m.findDuplicatesCmd()
+
+	case 7: // Browse history
+		m.openHistory()
 		return m, nil
-	}
 
-	return m, nil
-}
+	case 8: // New conversation
+		cmd := m.NewConversation()
+		m.ScreenMode = types.ModeChat
+		m.SelectedOpt = 0
+		return m, cmd
 
-// handleStreamEnd handles the end of a stream.
-func (m *Model) handleStreamEnd() {
-	m.Streaming = false
-	m.safeCloseChannels()
-	// Force a re-render to update the UI
-	m.Update(nil)
-}
+	case personaOption: // Cycle persona
+		m.nextPersona()
+		return m, nil
 
-// handleStreamError handles errors from the stream.
-func (m *Model) handleStreamError() {
-	m.Streaming = false
-
-	// Get error from error channel if available.
-	if m.ErrCh != nil {
-		select {
-		case err := <-m.ErrCh:
-			if err != nil {
-				m.Msg = "Error: " + err.Error()
-				m.safeCloseChannels()
-				// Force a re-render to show the error
-				m.Update(nil)
-				return
-			}
-		default:
-		}
-	}
+	case toolsOption: // Toggle tool use
+		m.toggleTools()
+		return m, nil
 
-	m.Msg = "Error: An unknown error occurred during streaming"
-	m.safeCloseChannels()
-	// Force a re-render to show the error
-	m.Update(nil)
-}
+	case compareOption: // Edit the compare models
+		return m.startCompareEdit()
 
-// ensureChannels initializes the necessary channels if they don't exist.
-func (m *Model) ensureChannels() {
-	if m.StreamCh == nil {
-		m.StreamCh = make(chan string, 64)
-	}
-	if m.ErrCh == nil {
-		m.ErrCh = make(chan error, 1)
+	case thinkingOption: // Toggle storing reasoning
+		m.toggleKeepThinking()
+		return m, nil
+
+	case memoryOption: // Toggle long-term memory
+		return m, m.toggleMemory()
+
+	case memoriesOption: // Review memories
+		m.openMemories()
+		return m, nil
 	}
-	if m.StopCh == nil {
-		m.StopCh = make(chan struct{})
+
+	if isGenOption(m.SelectedOpt) {
+		return m.startGenOptionEdit(m.SelectedOpt)
 	}
-}
 
-// stopStreaming safely stops any ongoing streaming.
-func (m *Model) stopStreaming() {
-	m.Streaming = false
-	m.safeCloseChannels()
+	return m, nil
 }
 
-// safeCloseChannels safely closes all channels.
-func (m *Model) safeCloseChannels() {
-	safeClose := func(ch *chan struct{}) {
-		if ch == nil || *ch == nil {
-			return
-		}
-		// Close and nil the channel. We assume this is the only closer.
-		close(*ch)
-		*ch = nil
-	}
+// Indices of the model, temperature and API URL entries in the options menu
+const (
+	modelOption = iota
+	temperatureOption
+	apiURLOption
+)
 
-	safeCloseErrCh := func(ch *chan error) {
-		if ch == nil || *ch == nil {
-			return
-		}
-		// Close and nil the error channel.
-		close(*ch)
-		*ch = nil
-	}
+// personaOption is the index of the persona entry in the options menu
+const personaOption = 9
 
-	safeClose(&m.StopCh)
-	safeCloseErrCh(&m.ErrCh)
+// toolsOption is the index of the tool use entry, after the generation options
+const toolsOption = stopOption + 1
 
-	if m.StreamCh != nil {
-		close(m.StreamCh)
-		m.StreamCh = nil
-	}
-}
+// compareOption is the index of the compare models entry
+const compareOption = toolsOption + 1
+
+// thinkingOption is the index of the entry for storing reasoning
+const thinkingOption = compareOption + 1
+
+// memoryOption is the index of the long-term memory entry
+const memoryOption = thinkingOption + 1
+
+// memoriesOption is the index of the entry for reviewing memories
+const memoriesOption = memoryOption + 1
@@ -29,14 +29,28 @@ func (m Model) renderOptions() string {
 	// Show input field if editing
 	switch {
 	case m.EditingModel:
-		sb.WriteString("Enter model name (press Enter to save, Esc to cancel):\n")
-		sb.WriteString(m.ModelInput.View())
+		sb.WriteString(m.renderModelPicker())
 		return sb.String()
 
 	case m.EditingAPIURL:
 		sb.WriteString("Enter API URL (press Enter to save, Esc to cancel):\n")
 		sb.WriteString(m.APIURLInput.View())
 		return sb.String()
+
+	case m.EditingCompare:
+		sb.WriteString("Enter the models to compare (press Enter to save, Esc to cancel):\n")
+		sb.WriteString(m.CompareInput.View())
+		return sb.String()
+
+	case m.EditingGenOption:
+		label, _, _ := strings.Cut(m.Options[m.GenOptionIdx], ":")
+		sb.WriteString(fmt.Sprintf("Enter %s (press Enter to apply, Esc to cancel):\n", strings.ToLower(label)))
+		if m.GenOptionIdx == stopOption {
+			sb.WriteString(helpStyle.Render("Comma-separated; use \\n for a newline"))
+			sb.WriteString("\n")
+		}
+		sb.WriteString(m.GenOptionInput.View())
+		return sb.String()
 	}
 
 	// Show options list with current values
@@ -74,39 +88,102 @@ func (m Model) View() string {
 	switch m.ScreenMode {
 	case types.ModeChat:
 		// Show the message content if it exists
-		if m.Msg != "" {
+		if m.Msg != "" || len(m.ToolLog) > 0 || m.Thinking != "" {
+			// Show the reasoning, tool calls and results above the answer
+			text := m.Msg
+			if len(m.ToolLog) > 0 {
+				text = strings.TrimSpace(helpStyle.Render(strings.Join(m.ToolLog, "\n")) + "\n\n" + text)
+			}
+			if m.Thinking != "" {
+				text = strings.TrimSpace(m.renderThinking() + "\n\n" + text)
+			}
 			// Format the message with a nice border and padding
-			msgContent := messageStyle.Render(m.Msg)
+			msgContent := messageStyle.Render(text)
+			if m.Stopped {
+				msgContent = fmt.Sprintf("%s\n%s", msgContent, helpStyle.Render("⏹ stopped"))
+			}
 			// Add some vertical space before the input
 			content = fmt.Sprintf("\n%s\n\n\n%s", msgContent, m.TextInput.View())
 		} else {
 			content = m.TextInput.View()
 		}
+		if len(m.Attachments) > 0 {
+			content = fmt.Sprintf("%s\n%s", m.renderAttachments(), content)
+		}
+		if m.turn != nil && m.turn.route != "" {
+			content = fmt.Sprintf("%s\n%s", helpStyle.Render("🧭 "+m.turn.route), content)
+		}
+		if turns := m.Conversation.Turns(); turns > 0 {
+			content = fmt.Sprintf("%s\n%s", helpStyle.Render(fmt.Sprintf("Conversation · %d turns", turns)), content)
+		}
+		if m.ShowContext {
+			content = fmt.Sprintf("%s\n\n%s", content, m.renderContextPlan())
+		}
 
 		// Set instructions based on streaming state
-		if m.Streaming {
-			instructions = helpStyle.Render("Streaming… Press Esc to cancel, Ctrl+W to quit. Ctrl+O=Options.")
+		if m.PendingTool != nil {
+			instructions = helpStyle.Render(fmt.Sprintf("Run %s? y: Run it, n: Decline, Esc: Stop the answer", m.PendingTool.ToolCall.Name))
+		} else if m.Streaming {
+			instructions = helpStyle.Render("Streaming… Press Esc to stop, Ctrl+W to quit. Ctrl+O=Options.")
+		} else if m.Stopped {
+			instructions = helpStyle.Render("Ctrl+K: Keep partial answer, Ctrl+R: Continue, Enter: Ask something else, Ctrl+W: Quit")
 		} else {
-			instructions = helpStyle.Render("Press Enter to send. Esc: Cancel, Ctrl+O: Options, Ctrl+W: Quit")
+			instructions = helpStyle.Render("Press Enter to send. Esc: Cancel, Ctrl+N: New conversation, Ctrl+D: Context, Ctrl+T: Thinking, Ctrl+O: Options, Ctrl+W: Quit")
 		}
 
 	case types.ModeOptions:
 		content = m.renderOptions()
 		instructions = helpStyle.Render("Tab: Navigate • Enter: Select • ↑/↓: Adjust Temp • Ctrl+C: Back to Chat • Ctrl+W: Quit")
 
+	case types.ModeDedupe:
+		content = m.renderDedupe()
+		instructions = helpStyle.Render("←/→: Select • k: Keep selected • m: Merge into selected • d: Delete selected • n: Next cluster • Ctrl+C: Back")
+
+	case types.ModeHistory:
+		content = m.renderHistory()
+		if m.EditingEntry {
+			instructions = helpStyle.Render("Tab: Switch field • Ctrl+S: Save • Esc: Cancel")
+		} else {
+			instructions = helpStyle.Render("↑/↓: Select • e: Edit • d: Delete • Esc: Back")
+		}
+
+	case types.ModeCompare:
+		content = m.renderCompare()
+		if m.Compare.running() {
+			instructions = helpStyle.Render("←/→: Select • Enter: Store selected • a: Store all • Esc: Stop")
+		} else {
+			instructions = helpStyle.Render("←/→: Select • Enter: Store selected • a: Store all • Esc: Back to chat")
+		}
+
+	case types.ModeMemories:
+		content = m.renderMemories()
+		instructions = helpStyle.Render("↑/↓: Select • d: Delete • Esc: Back")
+
+	case types.ModeTemplate:
+		content = m.renderTemplateForm()
+		instructions = helpStyle.Render("Tab/Shift+Tab: Switch field • Ctrl+S: Send • Esc: Cancel")
+
 	default:
 		content = "[Unknown Screen]"
 	}
 
 	// Show status message if available
 	statusBar := ""
-	if m.StatusMsg != "" {
+	switch {
+	case m.StatusMsg != "":
 		statusBar = fmt.Sprintf("\n\n%s", statusStyle.Render(m.StatusMsg))
+	case m.Streaming && m.ScreenMode == types.ModeChat:
+		statusBar = fmt.Sprintf("\n\n%s", statusStyle.Render(m.liveMetrics()))
+	}
+
+	title := "AskAI"
+	if m.KnowledgeBase != "" && m.KnowledgeBase != config.DefaultKnowledgeBase {
+		title = fmt.Sprintf("AskAI · %s", m.KnowledgeBase)
 	}
 
 	// Combine all components with proper spacing
 	return fmt.Sprintf("%s\n\n%s\n\n%s%s\n",
-		titleStyle.Render("AskAI"),
+		titleStyle.Render(title),
 		content,
 		instructions,
 		statusBar,
@@ -5,25 +5,33 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+
+	"github.com/VarunSharma3520/AskAI/internal/cassette"
 )
 
 // OllamaEmbedder implements the Embedder interface using Ollama's API
 type OllamaEmbedder struct {
 	baseURL string
 	model  string
+	client *http.Client
 }
 
-// NewOllamaEmbedder creates a new Ollama embedder
-func NewOllamaEmbedder(baseURL, model string) *OllamaEmbedder {
+// NewOllamaEmbedder creates a new Ollama embedder.
+// A nil client uses cassette.Client, like the chat providers.
+func NewOllamaEmbedder(baseURL, model string, client *http.Client) *OllamaEmbedder {
 	if baseURL == "" {
 		baseURL = "http://localhost:11434"
 	}
 	if model == "" {
 		model = "mxbai-embed-large"
 	}
+	if client == nil {
+		client = cassette.Client()
+	}
 	return &OllamaEmbedder{
 		baseURL: baseURL,
 		model:  model,
+		client: client,
 	}
 }
 
@@ -63,8 +71,7 @@ func (e *OllamaEmbedder) Embed(text string) ([]float32, error) {
 	req.Header.Set("Content-Type", "application/json")
 
 	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := e.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request to Ollama: %w", err)
 	}
@@ -0,0 +1,294 @@
+package vector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/qdrant/go-client/qdrant"
+)
+
+// scrollPageSize is the number of points requested per scroll page
+const scrollPageSize = 256
+
+// ErrPointNotFound is returned by GetQA when no point has the given ID
+var ErrPointNotFound = errors.New("point not found")
+
+// QAPoint is a stored Q&A pair as read back from Qdrant
+type QAPoint struct {
+	ID       string
+	Question string
+	Answer   string
+	StoredAt string
+	Payload  map[string]string
+	Vector   []float32
+}
+
+// ScrollQAs returns every Q&A point in the collection, optionally with its vector
+func (vs *VectorStore) ScrollQAs(withVectors bool) ([]QAPoint, error) {
+	return vs.scroll(qaPairFilter(), withVectors)
+}
+
+// ScrollAll returns every point in the collection regardless of its type
+func (vs *VectorStore) ScrollAll(withVectors bool) ([]QAPoint, error) {
+	return vs.scroll(nil, withVectors)
+}
+
+// scroll pages through all points matching filter
+func (vs *VectorStore) scroll(filter *pb.Filter, withVectors bool) ([]QAPoint, error) {
+	var points []QAPoint
+	var offset *pb.PointId
+	limit := uint32(scrollPageSize)
+
+	for {
+		resp, err := vs.pointsClient.Scroll(context.Background(), &pb.ScrollPoints{
+			CollectionName: vs.collection,
+			Filter:         filter,
+			Offset:         offset,
+			Limit:          &limit,
+			WithPayload: &pb.WithPayloadSelector{
+				SelectorOptions: &pb.WithPayloadSelector_Enable{
+					Enable: true,
+				},
+			},
+			WithVectors: &pb.WithVectorsSelector{
+				SelectorOptions: &pb.WithVectorsSelector_Enable{
+					Enable: withVectors,
+				},
+			},
+		})
+		if err != nil {
+			vs.logger.Error("failed to scroll Qdrant points", err,
+				map[string]interface{}{"collection": vs.collection})
+			return nil, fmt.Errorf("scroll failed: %w", err)
+		}
+
+		for _, p := range resp.GetResult() {
+			points = append(points, toQAPoint(p))
+		}
+
+		offset = resp.GetNextPageOffset()
+		if offset == nil {
+			break
+		}
+	}
+
+	return points, nil
+}
+
+// DeletePoints removes the points with the given IDs from the collection
+func (vs *VectorStore) DeletePoints(ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	pointIDs := make([]*pb.PointId, 0, len(ids))
+	for _, id := range ids {
+		pointIDs = append(pointIDs, &pb.PointId{
+			PointIdOptions: &pb.PointId_Uuid{Uuid: id},
+		})
+	}
+
+	wait := true
+	_, err := vs.pointsClient.Delete(context.Background(), &pb.DeletePoints{
+		CollectionName: vs.collection,
+		Wait:           &wait,
+		Points: &pb.PointsSelector{
+			PointsSelectorOneOf: &pb.PointsSelector_Points{
+				Points: &pb.PointsIdsList{Ids: pointIDs},
+			},
+		},
+	})
+	if err != nil {
+		vs.logger.Error("failed to delete points from Qdrant", err,
+			map[string]interface{}{"collection": vs.collection, "point_ids": ids})
+		return fmt.Errorf("failed to delete points: %w", err)
+	}
+
+	vs.logger.Info("deleted points from Qdrant",
+		map[string]interface{}{"collection": vs.collection, "point_ids": ids})
+	return nil
+}
+
+// UpsertPoints writes a batch of points with their vectors and payloads
+func (vs *VectorStore) UpsertPoints(points []QAPoint) error {
+	if len(points) == 0 {
+		return nil
+	}
+
+	structs := make([]*pb.PointStruct, 0, len(points))
+	for _, p := range points {
+		payload := make(map[string]*pb.Value, len(p.Payload))
+		for k, v := range p.Payload {
+			payload[k] = &pb.Value{Kind: &pb.Value_StringValue{StringValue: v}}
+		}
+		structs = append(structs, &pb.PointStruct{
+			Id: &pb.PointId{PointIdOptions: &pb.PointId_Uuid{Uuid: p.ID}},
+			Vectors: &pb.Vectors{
+				VectorsOptions: &pb.Vectors_Vector{Vector: &pb.Vector{Data: p.Vector}},
+			},
+			Payload: payload,
+		})
+	}
+
+	wait := true
+	_, err := vs.pointsClient.Upsert(context.Background(), &pb.UpsertPoints{
+		CollectionName: vs.collection,
+		Wait:           &wait,
+		Points:         structs,
+	})
+	if err != nil {
+		vs.logger.Error("failed to upsert points into Qdrant", err,
+			map[string]interface{}{"collection": vs.collection, "count": len(points)})
+		return fmt.Errorf("failed to upsert points: %w", err)
+	}
+	return nil
+}
+
+// Count returns the exact number of points in the collection
+func (vs *VectorStore) Count() (uint64, error) {
+	exact := true
+	resp, err := vs.pointsClient.Count(context.Background(), &pb.CountPoints{
+		CollectionName: vs.collection,
+		Exact:          &exact,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count points: %w", err)
+	}
+	return resp.GetResult().GetCount(), nil
+}
+
+// qaPairFilter matches points whose payload type is "qa_pair"
+func qaPairFilter() *pb.Filter {
+	return typeFilter("qa_pair")
+}
+
+// typeFilter matches points whose payload type is kind
+func typeFilter(kind string) *pb.Filter {
+	return &pb.Filter{
+		Must: []*pb.Condition{
+			{
+				ConditionOneOf: &pb.Condition_Field{
+					Field: &pb.FieldCondition{
+						Key: "type",
+						Match: &pb.Match{
+							MatchValue: &pb.Match_Keyword{
+								Keyword: kind,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// toQAPoint converts a retrieved Qdrant point into a QAPoint
+func toQAPoint(p *pb.RetrievedPoint) QAPoint {
+	payload := make(map[string]string, len(p.GetPayload()))
+	for k, v := range p.GetPayload() {
+		payload[k] = v.GetStringValue()
+	}
+
+	point := QAPoint{
+		ID:       pointIDString(p.GetId()),
+		Question: payload["question"],
+		Answer:   payload["answer"],
+		StoredAt: payload["stored_at"],
+		Payload:  payload,
+	}
+
+	if vec := p.GetVectors().GetVector(); vec != nil {
+		if dense := vec.GetDense(); dense != nil {
+			point.Vector = dense.GetData()
+		} else {
+			point.Vector = vec.GetData()
+		}
+	}
+
+	return point
+}
+
+// pointIDString renders a point ID as a string regardless of its kind
+func pointIDString(id *pb.PointId) string {
+	if id == nil {
+		return ""
+	}
+	if u := id.GetUuid(); u != "" {
+		return u
+	}
+	return fmt.Sprintf("%d", id.GetNum())
+}
+
+// GetQA fetches a single Q&A point, including its vector, by ID
+func (vs *VectorStore) GetQA(id string) (*QAPoint, error) {
+	resp, err := vs.pointsClient.Get(context.Background(), &pb.GetPoints{
+		CollectionName: vs.collection,
+		Ids: []*pb.PointId{
+			{PointIdOptions: &pb.PointId_Uuid{Uuid: id}},
+		},
+		WithPayload: &pb.WithPayloadSelector{
+			SelectorOptions: &pb.WithPayloadSelector_Enable{
+				Enable: true,
+			},
+		},
+		WithVectors: &pb.WithVectorsSelector{
+			SelectorOptions: &pb.WithVectorsSelector_Enable{
+				Enable: true,
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get point %s: %w", id, err)
+	}
+
+	if len(resp.GetResult()) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrPointNotFound, id)
+	}
+
+	point := toQAPoint(resp.GetResult()[0])
+	return &point, nil
+}
+
+// ScoredQA is a Q&A point returned by a similarity search
+type ScoredQA struct {
+	QAPoint
+	Score      float32
+	Collection string
+}
+
+// SearchQAs returns the Q&A points most similar to an already computed embedding
+func (vs *VectorStore) SearchQAs(embedding []float32, limit uint64) ([]ScoredQA, error) {
+	return vs.search(embedding, limit, qaPairFilter())
+}
+
+// SearchMemories returns the memory points most similar to an already
+// computed embedding. Their text is in the Answer field.
+func (vs *VectorStore) SearchMemories(embedding []float32, limit uint64) ([]ScoredQA, error) {
+	return vs.search(embedding, limit, typeFilter(MemoryType))
+}
+
+// search returns the points matching filter that are most similar to embedding
+func (vs *VectorStore) search(embedding []float32, limit uint64, filter *pb.Filter) ([]ScoredQA, error) {
+	result, err := vs.pointsClient.Search(context.Background(), &pb.SearchPoints{
+		CollectionName: vs.collection,
+		Vector:         embedding,
+		Limit:          limit,
+		Filter:         filter,
+		WithPayload: &pb.WithPayloadSelector{
+			SelectorOptions: &pb.WithPayloadSelector_Enable{
+				Enable: true,
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search in %s failed: %w", vs.collection, err)
+	}
+
+	hits := make([]ScoredQA, 0, len(result.GetResult()))
+	for _, sp := range result.GetResult() {
+		point := toQAPoint(&pb.RetrievedPoint{Id: sp.GetId(), Payload: sp.GetPayload()})
+		hits = append(hits, ScoredQA{QAPoint: point, Score: sp.GetScore(), Collection: vs.collection})
+	}
+	return hits, nil
+}
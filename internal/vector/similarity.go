@@ -0,0 +1,74 @@
+package vector
+
+import (
+	"math"
+	"sort"
+)
+
+// CosineSimilarity returns the cosine similarity of two vectors.
+// Vectors of different length or zero magnitude have a similarity of 0.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// ClusterBySimilarity groups points whose vectors are at least threshold similar.
+// Similarity is transitive within a cluster: if A~B and B~C, all three end up together.
+// Only clusters with two or more points are returned, largest first.
+func ClusterBySimilarity(points []QAPoint, threshold float64) [][]QAPoint {
+	parent := make([]int, len(points))
+	for i := range parent {
+		parent[i] = i
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := range points {
+		for j := i + 1; j < len(points); j++ {
+			if CosineSimilarity(points[i].Vector, points[j].Vector) >= threshold {
+				parent[find(i)] = find(j)
+			}
+		}
+	}
+
+	groups := make(map[int][]QAPoint)
+	var roots []int
+	for i, p := range points {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], p)
+	}
+
+	var clusters [][]QAPoint
+	for _, root := range roots {
+		if len(groups[root]) > 1 {
+			clusters = append(clusters, groups[root])
+		}
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i]) > len(clusters[j])
+	})
+	return clusters
+}
@@ -4,10 +4,11 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/VarunSharma3520/AskAI/internal/logger"
-	"github.com/google/uuid"
 	pb "github.com/qdrant/go-client/qdrant"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -44,6 +45,33 @@ func NewVectorStore(conn *grpc.ClientConn, collection string, embedder Embedder,
 	}
 }
 
+// WithCollection returns a VectorStore for another collection that shares this
+// store's connection, embedder and logger
+func (vs *VectorStore) WithCollection(collection string) *VectorStore {
+	clone := *vs
+	clone.collection = collection
+	return &clone
+}
+
+// Collection returns the name of the collection this store reads and writes
+func (vs *VectorStore) Collection() string {
+	return vs.collection
+}
+
+// ListCollections returns the names of all collections on the Qdrant server
+func (vs *VectorStore) ListCollections() ([]string, error) {
+	resp, err := vs.collectionsClient.List(context.Background(), &pb.ListCollectionsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	names := make([]string, 0, len(resp.GetCollections()))
+	for _, c := range resp.GetCollections() {
+		names = append(names, c.GetName())
+	}
+	return names, nil
+}
+
 // Embed creates a vector embedding for the given text
 func (vs *VectorStore) Embed(text string) ([]float32, error) {
 	if vs.embedder == nil {
@@ -169,8 +197,9 @@ func (vs *VectorStore) StoreVector(id string, vector []float32, metadata map[str
 	return nil
 }
 
-// StoreQA stores a question and its answer in Qdrant with proper metadata
-func (vs *VectorStore) StoreQA(question, answer string, questionEmbedding, answerEmbedding []float32) error {
+// StoreQA stores a question and its answer in Qdrant under the given point ID with proper metadata.
+// Fields in extra are added to the payload.
+func (vs *VectorStore) StoreQA(qaID, question, answer string, questionEmbedding, answerEmbedding []float32, extra map[string]string) error {
 	// First check if this Q&A pair already exists
 	exists, err := vs.QAExists(question, answer)
 	if err != nil {
@@ -190,25 +219,18 @@ func (vs *VectorStore) StoreQA(question, answer string, questionEmbedding, answe
 		"answer_vector_size":   len(answerEmbedding),
 	})
 
-	vs.logger.Info(fmt.Sprintf("Storing vector with ID: %s, vector size: %d", uuid.New().String(), len(questionEmbedding)), nil)
-
-	// Create a single ID for the Q&A pair
-	qaID := uuid.New().String()
+	vs.logger.Info(fmt.Sprintf("Storing vector with ID: %s, vector size: %d", qaID, len(questionEmbedding)), nil)
 
-	// Get current timestamp
-	timestamp := time.Now().Format(time.RFC3339)
+	payload := QAPayload(question, answer, time.Now())
+	for k, v := range extra {
+		payload[k] = v
+	}
 
 	// Store a single vector with combined Q&A information
 	err = vs.StoreVector(
 		qaID,
 		questionEmbedding, // Using question embedding for search
-		map[string]string{
-			"type":        "qa_pair",
-			"question":    question,
-			"answer":      answer,
-			"stored_at":   timestamp,
-			"vector_type": "question",
-		},
+		payload,
 	)
 
 	if err != nil {
@@ -220,6 +242,48 @@ func (vs *VectorStore) StoreQA(question, answer string, questionEmbedding, answe
 	return nil
 }
 
+// QAPayload builds the payload stored with every Q&A point
+func QAPayload(question, answer string, storedAt time.Time) map[string]string {
+	return map[string]string{
+		"type":        "qa_pair",
+		"question":    question,
+		"answer":      answer,
+		"stored_at":   storedAt.Format(time.RFC3339),
+		"vector_type": "question",
+	}
+}
+
+// MemoryType is the payload type of long-term memory points
+const MemoryType = "memory"
+
+// MemoryPayload builds the payload stored with a memory point. The text is
+// stored as the answer, so memories read back like Q&A points.
+func MemoryPayload(text, kind string, sources []string, created time.Time) map[string]string {
+	return map[string]string{
+		"type":      MemoryType,
+		"answer":    text,
+		"kind":      kind,
+		"sources":   strings.Join(sources, ","),
+		"stored_at": created.Format(time.RFC3339),
+	}
+}
+
+// ConversationType is the payload type of conversation points
+const ConversationType = "conversation"
+
+// ConversationPayload builds the payload stored with a conversation point.
+// The title is stored as the question and the synopsis as the answer, so
+// conversations read back like Q&A points.
+func ConversationPayload(title, synopsis string, turns int, updated time.Time) map[string]string {
+	return map[string]string{
+		"type":      ConversationType,
+		"question":  title,
+		"answer":    synopsis,
+		"turns":     strconv.Itoa(turns),
+		"stored_at": updated.Format(time.RFC3339),
+	}
+}
+
 // SearchSimilarQuestions finds similar questions in Qdrant
 func (vs *VectorStore) SearchSimilarQuestions(question string, limit int32) ([]*pb.ScoredPoint, error) {
 	// Generate embedding for the question